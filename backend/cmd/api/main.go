@@ -8,18 +8,41 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Embed zone data so user time zones resolve on minimal images
 
 	"github.com/gin-gonic/gin"
 
 	"task-management/configs"
+	"task-management/internal/accounts"
+	"task-management/internal/audit"
 	"task-management/internal/db"
 	"task-management/internal/handlers"
+	"task-management/internal/history"
+	"task-management/internal/jobs"
+	"task-management/internal/mail"
 	"task-management/internal/middleware"
+	"task-management/internal/revisions"
 )
 
 func main() {
 	config := configs.LoadConfig()
 
+	// Without a mailer email changes can't be verified, so the server
+	// refuses to start without one in release mode
+	mailer, err := mail.New(mail.Config{
+		Host:     config.SMTPHost,
+		Port:     config.SMTPPort,
+		Username: config.SMTPUsername,
+		Password: config.SMTPPassword,
+		From:     config.MailFrom,
+	}, config.Production)
+	if err != nil {
+		log.Fatalf("Failed to set up mail delivery: %v", err)
+	}
+	if _, ok := mailer.(mail.Nop); ok {
+		log.Println("Warning: SMTP_HOST is not set, outgoing mail is dropped")
+	}
+
 	mongodb, err := db.NewMongoDB(config.MongoURI, config.DBName)
 	if err != nil {
 		log.Fatalf("Failed to connect to MongoDB: %v", err)
@@ -34,7 +57,30 @@ func main() {
 		}
 	}()
 
-	jwtMiddleware := middleware.NewJwtMiddleware(config.JWTSecret)
+	jwtMiddleware := middleware.NewJwtMiddleware(config.JWTSecret, mongodb.DB.Collection("users"), mongodb.DB.Collection("workspaces"))
+
+	// Background jobs stop when the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	recorder := history.NewRecorder(mongodb.DB.Collection(history.CollectionName))
+	go jobs.RunDaily(jobsCtx, "progress snapshots", func(ctx context.Context, now time.Time) error {
+		return recorder.SnapshotAll(ctx, mongodb.DB.Collection("goals"), now)
+	})
+
+	revisionStore := revisions.NewStore(mongodb.DB.Collection(revisions.CollectionName), revisions.NewPolicy(config.RevisionKeepLast, config.RevisionKeepDays))
+	if err := revisionStore.EnsureIndexes(ctx); err != nil {
+		log.Printf("Failed to create revision indexes: %v", err)
+	}
+	go jobs.RunDaily(jobsCtx, "revision pruning", revisionStore.PruneExpired)
+
+	// Deleted goals can be restored as long as their revisions are kept
+	go jobs.RunDaily(jobsCtx, "history pruning", func(ctx context.Context, now time.Time) error {
+		return recorder.PruneDeleted(ctx, now.Add(-revisions.DeletedGoalRetention))
+	})
+
+	purger := accounts.NewPurger(mongodb.DB, audit.NewLog(mongodb.DB.Collection(audit.CollectionName)))
+	go jobs.RunDaily(jobsCtx, "account deletion", purger.PurgeDue)
 
 	router := gin.Default()
 
@@ -43,7 +89,7 @@ func main() {
 		log.Printf("Handling request: %s %s", c.Request.Method, c.Request.URL.Path)
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Workspace-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -56,7 +102,7 @@ func main() {
 	})
 
 	// Setup routes
-	handlers.SetupRoutes(router, mongodb.DB, jwtMiddleware)
+	handlers.SetupRoutes(router, mongodb.DB, jwtMiddleware, mailer, config)
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -77,6 +123,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	stopJobs()
 
 	// Shutdown server with timeout
 	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
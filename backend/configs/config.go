@@ -3,6 +3,7 @@ package configs
 import (
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -14,6 +15,15 @@ type Config struct {
 	Port              string
 	TokenExpiryHours  int
 	PasswordSaltRound int
+	RevisionKeepLast  int
+	RevisionKeepDays  int
+	DeletionGraceDays int
+	Production        bool
+	SMTPHost          string
+	SMTPPort          int
+	SMTPUsername      string
+	SMTPPassword      string
+	MailFrom          string
 }
 
 func LoadConfig() *Config {
@@ -43,6 +53,18 @@ func LoadConfig() *Config {
 		log.Println("Warning: Using default JWT secret. This should be changed in production.")
 	}
 
+	// Goal revisions beyond the last N, or older than N days, are pruned;
+	// zero disables the limit
+	revisionKeepLast := intEnv("REVISION_KEEP_LAST", 50)
+	revisionKeepDays := intEnv("REVISION_KEEP_DAYS", 0)
+
+	// Deleted accounts are purged this many days after deletion is requested
+	deletionGraceDays := intEnv("ACCOUNT_DELETION_GRACE_DAYS", 14)
+
+	// Outgoing mail such as email verification goes through this SMTP
+	// server. It is required in release mode.
+	smtpPort := intEnv("SMTP_PORT", 587)
+
 	return &Config{
 		MongoURI:          mongoURI,
 		DBName:            dbName,
@@ -50,5 +72,29 @@ func LoadConfig() *Config {
 		Port:              port,
 		TokenExpiryHours:  24,
 		PasswordSaltRound: 10,
+		RevisionKeepLast:  revisionKeepLast,
+		RevisionKeepDays:  revisionKeepDays,
+		DeletionGraceDays: deletionGraceDays,
+		Production:        os.Getenv("GIN_MODE") == "release",
+		SMTPHost:          os.Getenv("SMTP_HOST"),
+		SMTPPort:          smtpPort,
+		SMTPUsername:      os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:      os.Getenv("SMTP_PASSWORD"),
+		MailFrom:          os.Getenv("MAIL_FROM"),
+	}
+}
+
+// intEnv reads a non-negative integer environment variable, falling back to
+// def when it is unset or invalid
+func intEnv(name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Printf("Warning: invalid %s %q, using %d", name, value, def)
+		return def
 	}
+	return n
 }
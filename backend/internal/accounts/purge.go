@@ -0,0 +1,178 @@
+package accounts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/audit"
+	"task-management/internal/history"
+	"task-management/internal/models"
+	"task-management/internal/revisions"
+)
+
+// Purger deletes accounts whose deletion grace period is over, together
+// with their personal goals and everything else that belongs to them. Goals
+// in workspaces stay with the workspace; the activity log is kept.
+type Purger struct {
+	db       *mongo.Database
+	activity *audit.Log
+}
+
+// NewPurger creates a purger for the collections in db
+func NewPurger(db *mongo.Database, activityLog *audit.Log) *Purger {
+	return &Purger{db: db, activity: activityLog}
+}
+
+// PurgeDue purges every account due for deletion at now. Accounts that
+// still own a workspace are skipped until its ownership is transferred.
+func (p *Purger) PurgeDue(ctx context.Context, now time.Time) error {
+	cursor, err := p.db.Collection("users").Find(ctx,
+		bson.M{"deleteAfter": bson.M{"$lte": now}},
+		options.Find().SetProjection(bson.M{"_id": 1, "email": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		owned, err := p.db.Collection("workspaces").CountDocuments(ctx, bson.M{"ownerId": user.ID})
+		if err != nil {
+			return err
+		}
+		if owned > 0 {
+			log.Printf("Skipping deletion of user %s: they still own %d workspaces", user.ID.Hex(), owned)
+			continue
+		}
+
+		if err := p.Purge(ctx, user.ID, user.Email); err != nil {
+			return fmt.Errorf("purging user %s: %w", user.ID.Hex(), err)
+		}
+	}
+	return nil
+}
+
+// Purge deletes a user's account and data. The user document goes last so
+// a purge that fails part way is picked up again on the next run.
+func (p *Purger) Purge(ctx context.Context, userID primitive.ObjectID, email string) error {
+	goalIDs, err := p.personalGoalIDs(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if len(goalIDs) > 0 {
+		byGoal := bson.M{"goalId": bson.M{"$in": goalIDs}}
+		for _, name := range []string{"comments", "notifications", "invitations", "time_entries", "focus_sessions", history.CollectionName, revisions.CollectionName} {
+			if _, err := p.db.Collection(name).DeleteMany(ctx, byGoal); err != nil {
+				return err
+			}
+		}
+
+		// Goals other users placed below the deleted ones move to the top level
+		if _, err := p.db.Collection("goals").UpdateMany(ctx,
+			bson.M{"parentId": bson.M{"$in": goalIDs}},
+			bson.M{"$unset": bson.M{"parentId": ""}},
+		); err != nil {
+			return err
+		}
+		if _, err := p.db.Collection("goals").DeleteMany(ctx, bson.M{"_id": bson.M{"$in": goalIDs}}); err != nil {
+			return err
+		}
+	}
+
+	// Leave shared goals, unassigning the user's subtasks
+	now := time.Now()
+	if _, err := p.db.Collection("goals").UpdateMany(ctx,
+		bson.M{"members.userId": userID},
+		bson.M{"$pull": bson.M{"members": bson.M{"userId": userID}}},
+	); err != nil {
+		return err
+	}
+	if _, err := p.db.Collection("goals").UpdateMany(ctx,
+		bson.M{"subTasks.assigneeId": userID},
+		bson.M{
+			"$unset": bson.M{"subTasks.$[task].assigneeId": ""},
+			"$push": bson.M{"subTasks.$[task].assignments": models.Assignment{
+				AssignedBy: userID,
+				AssignedAt: now,
+			}},
+		},
+		options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{bson.M{"task.assigneeId": userID}},
+		}),
+	); err != nil {
+		return err
+	}
+	if _, err := p.db.Collection("workspaces").UpdateMany(ctx,
+		bson.M{"members.userId": userID},
+		bson.M{"$pull": bson.M{"members": bson.M{"userId": userID}}},
+	); err != nil {
+		return err
+	}
+
+	// The user's own records
+	byUser := bson.M{"userId": userID}
+	for _, name := range []string{"notifications", "time_entries", "timers", "focus_sessions", "templates", "saved_filters"} {
+		if _, err := p.db.Collection(name).DeleteMany(ctx, byUser); err != nil {
+			return err
+		}
+	}
+	if _, err := p.db.Collection("comments").DeleteMany(ctx, bson.M{"authorId": userID}); err != nil {
+		return err
+	}
+	if _, err := p.db.Collection("invitations").DeleteMany(ctx, bson.M{"$or": bson.A{
+		bson.M{"inviterId": userID},
+		bson.M{"inviteeId": userID},
+		bson.M{"email": strings.ToLower(email)},
+	}}); err != nil {
+		return err
+	}
+
+	if _, err := p.db.Collection("users").DeleteOne(ctx, bson.M{"_id": userID}); err != nil {
+		return err
+	}
+
+	entry := models.Activity{
+		Action:    models.ActivityAccountDeleted,
+		ActorID:   &userID,
+		Metadata:  map[string]string{"goals": fmt.Sprint(len(goalIDs))},
+		CreatedAt: now,
+	}
+	if err := p.activity.Record(ctx, entry); err != nil {
+		log.Printf("Failed to record %s activity: %v", entry.Action, err)
+	}
+	return nil
+}
+
+// personalGoalIDs returns the IDs of the goals the user owns outside any
+// workspace
+func (p *Purger) personalGoalIDs(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	cursor, err := p.db.Collection("goals").Find(ctx,
+		bson.M{"userId": userID, "workspaceId": nil},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var goals []models.Goal
+	if err := cursor.All(ctx, &goals); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, len(goals))
+	for i, goal := range goals {
+		ids[i] = goal.ID
+	}
+	return ids, nil
+}
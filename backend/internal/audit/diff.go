@@ -0,0 +1,120 @@
+// Package audit records the append-only activity log and computes the
+// field-level changes stored in it.
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"task-management/internal/models"
+)
+
+// ignoredFields are bookkeeping fields left out of diffs
+var ignoredFields = map[string]bool{
+	"updatedAt": true,
+}
+
+// Diff compares two values by their JSON form and returns the fields that
+// differ, sorted by path. Either side may be nil, which makes every field of
+// the other side a change. Arrays of objects with an "id" are compared
+// element by element; other arrays are compared as a whole.
+func Diff(before, after interface{}) ([]models.FieldChange, error) {
+	b, err := flatten(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := flatten(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []models.FieldChange
+	for field, value := range b {
+		if other, ok := a[field]; !ok || !reflect.DeepEqual(value, other) {
+			changes = append(changes, models.FieldChange{Field: field, Before: value, After: a[field]})
+		}
+	}
+	for field, value := range a {
+		if _, ok := b[field]; !ok {
+			changes = append(changes, models.FieldChange{Field: field, After: value})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+// flatten converts a value into a map from dotted field path to leaf value
+func flatten(v interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	walk("", doc, fields)
+	return fields, nil
+}
+
+func walk(prefix string, value interface{}, fields map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if prefix == "" && ignoredFields[key] {
+				continue
+			}
+			walk(join(prefix, key), child, fields)
+		}
+	case []interface{}:
+		// Empty arrays are treated like missing ones
+		if len(v) == 0 {
+			return
+		}
+		if !keyedByID(v) {
+			fields[prefix] = v
+			return
+		}
+		for _, item := range v {
+			obj := item.(map[string]interface{})
+			id := obj["id"].(string)
+			for key, child := range obj {
+				if key == "id" || ignoredFields[key] {
+					continue
+				}
+				walk(join(join(prefix, id), key), child, fields)
+			}
+		}
+	default:
+		fields[prefix] = v
+	}
+}
+
+// keyedByID reports whether every element is an object with a string id
+func keyedByID(items []interface{}) bool {
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, ok := obj["id"].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func join(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
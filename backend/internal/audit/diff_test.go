@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"task-management/internal/models"
+)
+
+func TestDiff(t *testing.T) {
+	taskID, _ := primitive.ObjectIDFromHex("65f000000000000000000001")
+	otherID, _ := primitive.ObjectIDFromHex("65f000000000000000000002")
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	task := func(title string, completed bool) models.SubTask {
+		return models.SubTask{ID: taskID, Title: title, Completed: completed, CreatedAt: created}
+	}
+	id := taskID.Hex()
+
+	tests := []struct {
+		name   string
+		before interface{}
+		after  interface{}
+		want   []models.FieldChange
+	}{
+		{
+			name:   "no changes",
+			before: map[string]interface{}{"title": "a", "tags": []string{"x"}},
+			after:  map[string]interface{}{"title": "a", "tags": []string{"x"}},
+		},
+		{
+			name:   "changed field",
+			before: map[string]interface{}{"title": "a", "priority": "low"},
+			after:  map[string]interface{}{"title": "b", "priority": "low"},
+			want:   []models.FieldChange{{Field: "title", Before: "a", After: "b"}},
+		},
+		{
+			name:   "added and removed fields",
+			before: map[string]interface{}{"description": "old"},
+			after:  map[string]interface{}{"priority": "high"},
+			want: []models.FieldChange{
+				{Field: "description", Before: "old"},
+				{Field: "priority", After: "high"},
+			},
+		},
+		{
+			name:   "nested objects use dotted paths",
+			before: map[string]interface{}{"habit": map[string]interface{}{"target": 1, "frequency": "daily"}},
+			after:  map[string]interface{}{"habit": map[string]interface{}{"target": 3, "frequency": "daily"}},
+			want:   []models.FieldChange{{Field: "habit.target", Before: float64(1), After: float64(3)}},
+		},
+		{
+			name:   "plain arrays compare as a whole",
+			before: map[string]interface{}{"tags": []string{"a", "b"}},
+			after:  map[string]interface{}{"tags": []string{"b", "a"}},
+			want: []models.FieldChange{
+				{Field: "tags", Before: []interface{}{"a", "b"}, After: []interface{}{"b", "a"}},
+			},
+		},
+		{
+			name:   "empty array matches a missing one",
+			before: map[string]interface{}{"tags": []string{}},
+			after:  map[string]interface{}{},
+		},
+		{
+			name:   "updatedAt is ignored",
+			before: map[string]interface{}{"updatedAt": "2026-03-01"},
+			after:  map[string]interface{}{"updatedAt": "2026-03-02"},
+		},
+		{
+			name:   "subtasks are keyed by id",
+			before: map[string]interface{}{"subTasks": []models.SubTask{task("draft", false)}},
+			after:  map[string]interface{}{"subTasks": []models.SubTask{task("final", true)}},
+			want: []models.FieldChange{
+				{Field: "subTasks." + id + ".completed", Before: false, After: true},
+				{Field: "subTasks." + id + ".title", Before: "draft", After: "final"},
+			},
+		},
+		{
+			name:   "reordered subtasks are unchanged",
+			before: map[string]interface{}{"subTasks": []map[string]interface{}{{"id": "a", "title": "1"}, {"id": "b", "title": "2"}}},
+			after:  map[string]interface{}{"subTasks": []map[string]interface{}{{"id": "b", "title": "2"}, {"id": "a", "title": "1"}}},
+		},
+		{
+			name:   "added subtask",
+			before: &models.Goal{Title: "g", SubTasks: []models.SubTask{}},
+			after:  &models.Goal{Title: "g", SubTasks: []models.SubTask{{ID: otherID, Title: "new"}}},
+			want: []models.FieldChange{
+				{Field: "subTasks." + otherID.Hex() + ".blocked", After: false},
+				{Field: "subTasks." + otherID.Hex() + ".completed", After: false},
+				{Field: "subTasks." + otherID.Hex() + ".createdAt", After: "0001-01-01T00:00:00Z"},
+				{Field: "subTasks." + otherID.Hex() + ".title", After: "new"},
+			},
+		},
+		{
+			name:   "nil before",
+			before: nil,
+			after:  map[string]interface{}{"title": "a"},
+			want:   []models.FieldChange{{Field: "title", After: "a"}},
+		},
+		{
+			name:   "nil pointer after",
+			before: map[string]interface{}{"title": "a"},
+			after:  (*models.Goal)(nil),
+			want:   []models.FieldChange{{Field: "title", Before: "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Diff(tt.before, tt.after)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffUnmarshalable(t *testing.T) {
+	if _, err := Diff(map[string]interface{}{"f": func() {}}, nil); err == nil {
+		t.Error("expected an error for a value that can't be encoded")
+	}
+}
@@ -0,0 +1,127 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"task-management/internal/models"
+	"task-management/internal/transfer"
+)
+
+// csvHeader lists the columns of a CSV audit export. Changes and metadata
+// are written as JSON.
+var csvHeader = []string{
+	"id", "created_at", "action", "actor_id", "goal_id", "subtask_id",
+	"workspace_id", "ip", "user_agent", "changes", "metadata",
+}
+
+// Writer streams activity entries in an export format
+type Writer struct {
+	format transfer.Format
+	buf    *bufio.Writer
+	csv    *csv.Writer
+	count  int
+}
+
+// NewWriter creates an audit export writer for the given format
+func NewWriter(format transfer.Format, w io.Writer) *Writer {
+	buf := bufio.NewWriter(w)
+	return &Writer{format: format, buf: buf, csv: csv.NewWriter(buf)}
+}
+
+// Write appends one entry to the export
+func (w *Writer) Write(entry *models.Activity) error {
+	defer func() { w.count++ }()
+
+	switch w.format {
+	case transfer.FormatCSV:
+		if w.count == 0 {
+			if err := w.csv.Write(csvHeader); err != nil {
+				return err
+			}
+		}
+		changes, err := json.Marshal(entry.Changes)
+		if err != nil {
+			return err
+		}
+		metadata, err := json.Marshal(entry.Metadata)
+		if err != nil {
+			return err
+		}
+		if err := w.csv.Write([]string{
+			entry.ID.Hex(),
+			entry.CreatedAt.UTC().Format(time.RFC3339),
+			entry.Action,
+			hexOrEmpty(entry.ActorID),
+			hexOrEmpty(entry.GoalID),
+			hexOrEmpty(entry.SubTaskID),
+			hexOrEmpty(entry.WorkspaceID),
+			entry.IP,
+			entry.UserAgent,
+			string(changes),
+			string(metadata),
+		}); err != nil {
+			return err
+		}
+		w.csv.Flush()
+		return w.csv.Error()
+
+	default:
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if w.format == transfer.FormatJSON {
+			sep := ",\n"
+			if w.count == 0 {
+				sep = "[\n"
+			}
+			if _, err := w.buf.WriteString(sep); err != nil {
+				return err
+			}
+		} else {
+			data = append(data, '\n')
+		}
+		if _, err := w.buf.Write(data); err != nil {
+			return err
+		}
+		return w.buf.Flush()
+	}
+}
+
+// Close finishes the export
+func (w *Writer) Close() error {
+	switch w.format {
+	case transfer.FormatCSV:
+		if w.count == 0 {
+			if err := w.csv.Write(csvHeader); err != nil {
+				return err
+			}
+		}
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	case transfer.FormatJSON:
+		end := "\n]\n"
+		if w.count == 0 {
+			end = "[]\n"
+		}
+		if _, err := w.buf.WriteString(end); err != nil {
+			return err
+		}
+	}
+	return w.buf.Flush()
+}
+
+func hexOrEmpty(id *primitive.ObjectID) string {
+	if id == nil {
+		return ""
+	}
+	return id.Hex()
+}
@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"task-management/internal/models"
+)
+
+// CollectionName is the collection activity entries are stored in
+const CollectionName = "activity"
+
+// Log appends activity entries. Entries are only ever inserted.
+type Log struct {
+	collection *mongo.Collection
+}
+
+// NewLog creates a new activity log
+func NewLog(collection *mongo.Collection) *Log {
+	return &Log{
+		collection: collection,
+	}
+}
+
+// Record appends an entry, filling in its ID and timestamp when unset
+func (l *Log) Record(ctx context.Context, entry models.Activity) error {
+	if l == nil {
+		return nil
+	}
+
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	_, err := l.collection.InsertOne(ctx, entry)
+	return err
+}
@@ -0,0 +1,68 @@
+package events
+
+import (
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// subscriberBuffer is how many events a slow subscriber can fall behind
+// before further events to it are dropped
+const subscriberBuffer = 16
+
+// Event is a message pushed to a user's connected clients
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Broker fans events out to the clients each user has connected. It is in
+// memory, so only clients connected to the same server instance are
+// reached.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[primitive.ObjectID]map[chan Event]struct{}
+}
+
+// NewBroker creates a new event broker
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[primitive.ObjectID]map[chan Event]struct{}),
+	}
+}
+
+// Subscribe registers a client of the user. The returned function
+// unsubscribes it and must be called when the client disconnects.
+func (b *Broker) Subscribe(userID primitive.ObjectID) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan Event]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers[userID], ch)
+		if len(b.subscribers[userID]) == 0 {
+			delete(b.subscribers, userID)
+		}
+	}
+}
+
+// Publish sends an event to all of the user's clients without blocking.
+// Clients that aren't keeping up miss the event.
+func (b *Broker) Publish(userID primitive.ObjectID, event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[userID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"task-management/internal/models"
+)
+
+// Goal access errors
+var (
+	errGoalNotFound = errors.New("goal not found")
+	errForbidden    = errors.New("insufficient permissions")
+)
+
+// goalScope describes which goals a request can reach: the caller's own
+// and shared goals within the active workspace, or within their personal
+// space when no workspace is active
+type goalScope struct {
+	UserID        primitive.ObjectID
+	WorkspaceID   *primitive.ObjectID
+	WorkspaceRole string
+}
+
+// requestScope reads the scope that AuthRequired stored in the context,
+// writing an error response and returning false when it is missing
+func requestScope(c *gin.Context) (goalScope, bool) {
+	// Get user ID from context
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return goalScope{}, false
+	}
+
+	scope := goalScope{UserID: userID.(primitive.ObjectID)}
+	if workspaceID, ok := c.Get("workspaceId"); ok {
+		id := workspaceID.(primitive.ObjectID)
+		scope.WorkspaceID = &id
+		scope.WorkspaceRole = c.GetString("workspaceRole")
+	}
+
+	return scope, true
+}
+
+// key identifies the scope in caches
+func (s goalScope) key() string {
+	if s.WorkspaceID == nil {
+		return "personal"
+	}
+	return s.WorkspaceID.Hex()
+}
+
+// tenantValue is the workspaceId to match; nil matches personal goals,
+// which have no workspace
+func (s goalScope) tenantValue() interface{} {
+	if s.WorkspaceID == nil {
+		return nil
+	}
+	return *s.WorkspaceID
+}
+
+// ownedFilter matches the goals the user owns in the active workspace
+func (s goalScope) ownedFilter() bson.M {
+	return bson.M{
+		"userId":      s.UserID,
+		"workspaceId": s.tenantValue(),
+	}
+}
+
+// accessibleFilter matches the goals the user can see in the active
+// workspace: all of them for workspace members, otherwise those they own
+// or are a member of
+func (s goalScope) accessibleFilter() bson.M {
+	filter := bson.M{"workspaceId": s.tenantValue()}
+	if models.GoalRole(s.WorkspaceRole) == "" {
+		filter["$or"] = bson.A{
+			bson.M{"userId": s.UserID},
+			bson.M{"members.userId": s.UserID},
+		}
+	}
+	return filter
+}
+
+// roleOn returns the user's effective role on a goal: the higher of their
+// own role on it and the role implied by their workspace role
+func (s goalScope) roleOn(goal *models.Goal) string {
+	role := goal.RoleOf(s.UserID)
+	if implied := models.GoalRole(s.WorkspaceRole); implied != "" && !models.RoleAtLeast(role, implied) {
+		role = implied
+	}
+	return role
+}
+
+// findGoalWithRole loads a goal in the request scope and checks that the
+// user's role grants at least minRole. Goals the user can't see at all are
+// reported as not found rather than forbidden.
+func findGoalWithRole(ctx context.Context, goalCollection *mongo.Collection, goalID primitive.ObjectID, scope goalScope, minRole string) (*models.Goal, string, error) {
+	filter := scope.accessibleFilter()
+	filter["_id"] = goalID
+
+	var goal models.Goal
+	if err := goalCollection.FindOne(ctx, filter).Decode(&goal); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, "", errGoalNotFound
+		}
+		return nil, "", err
+	}
+
+	role := scope.roleOn(&goal)
+	if !models.RoleAtLeast(role, minRole) {
+		return nil, role, errForbidden
+	}
+
+	return &goal, role, nil
+}
+
+// respondGoalAccessError writes the response for a findGoalWithRole error
+func respondGoalAccessError(c *gin.Context, err error) {
+	switch err {
+	case errGoalNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+	case errForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to do this"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get goal"})
+	}
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/audit"
+	"task-management/internal/models"
+	"task-management/internal/transfer"
+)
+
+// ActivityHandler handles activity feed and audit export routes
+type ActivityHandler struct {
+	activityCollection *mongo.Collection
+	goalCollection     *mongo.Collection
+	userCollection     *mongo.Collection
+}
+
+// NewActivityHandler creates a new activity handler
+func NewActivityHandler(activityCollection, goalCollection, userCollection *mongo.Collection) *ActivityHandler {
+	return &ActivityHandler{
+		activityCollection: activityCollection,
+		goalCollection:     goalCollection,
+		userCollection:     userCollection,
+	}
+}
+
+// newActivity starts an activity entry for the request, recording the
+// authenticated user, client IP and user agent
+func newActivity(c *gin.Context, action string) models.Activity {
+	entry := models.Activity{
+		Action:    action,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		CreatedAt: time.Now(),
+	}
+	if userID, exists := c.Get("userId"); exists {
+		actorID := userID.(primitive.ObjectID)
+		entry.ActorID = &actorID
+	}
+	return entry
+}
+
+// GetGoalActivity handles listing a goal's activity, newest first
+func (h *ActivityHandler) GetGoalActivity(c *gin.Context) {
+	goalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal ID"})
+		return
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	if _, _, err := findGoalWithRole(context.Background(), h.goalCollection, goalID, scope, models.RoleViewer); err != nil {
+		respondGoalAccessError(c, err)
+		return
+	}
+
+	h.respondFeed(c, bson.M{"goalId": goalID})
+}
+
+// GetMyActivity handles listing the caller's own activity, newest first
+func (h *ActivityHandler) GetMyActivity(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	h.respondFeed(c, bson.M{"actorId": userID.(primitive.ObjectID)})
+}
+
+// ExportAudit streams the whole activity log to admins. from, to, action
+// and actorId narrow the export.
+func (h *ActivityHandler) ExportAudit(c *gin.Context) {
+	if !requireAdmin(c, h.userCollection) {
+		return
+	}
+
+	format, err := transfer.ParseFormat(c.DefaultQuery("format", string(transfer.FormatNDJSON)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := bson.M{}
+	createdAt := bson.M{}
+	from, err := parseQueryDate(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date: " + err.Error()})
+		return
+	}
+	if from != nil {
+		createdAt["$gte"] = *from
+	}
+	to, err := parseQueryDate(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date: " + err.Error()})
+		return
+	}
+	if to != nil {
+		createdAt["$lt"] = *to
+	}
+	if len(createdAt) > 0 {
+		filter["createdAt"] = createdAt
+	}
+	if action := c.Query("action"); action != "" {
+		filter["action"] = action
+	}
+	if value := c.Query("actorId"); value != "" {
+		actorID, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid actor ID"})
+			return
+		}
+		filter["actorId"] = actorID
+	}
+
+	cursor, err := h.activityCollection.Find(context.Background(), filter,
+		options.Find().SetSort(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export audit log"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	filename := fmt.Sprintf("audit-%s.%s", time.Now().Format("20060102"), format.Extension())
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	// Headers are already sent, so failures past this point can only be logged
+	writer := audit.NewWriter(format, c.Writer)
+	for cursor.Next(context.Background()) {
+		var entry models.Activity
+		if err := cursor.Decode(&entry); err != nil {
+			c.Error(err)
+			return
+		}
+		if err := writer.Write(&entry); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		c.Error(err)
+		return
+	}
+	if err := writer.Close(); err != nil {
+		c.Error(err)
+	}
+}
+
+// respondFeed writes one page of activity matching filter
+func (h *ActivityHandler) respondFeed(c *gin.Context, filter bson.M) {
+	limit, err := pageQuery(c, filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cursor, err := h.activityCollection.Find(context.Background(), filter,
+		options.Find().SetSort(bson.M{"_id": -1}).SetLimit(limit),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch activity"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	entries := []models.Activity{}
+	if err := cursor.All(context.Background(), &entries); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode activity"})
+		return
+	}
+
+	// The next page starts before the last entry
+	var next string
+	if int64(len(entries)) == limit {
+		next = entries[len(entries)-1].ID.Hex()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"activity": entries, "next": next})
+}
+
+// requireAdmin checks that the caller is an admin, writing the error
+// response and returning false when they aren't
+func requireAdmin(c *gin.Context, userCollection *mongo.Collection) bool {
+	// Get user ID from context
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return false
+	}
+
+	var user models.User
+	err := userCollection.FindOne(context.Background(),
+		bson.M{"_id": userID.(primitive.ObjectID)},
+		options.FindOne().SetProjection(bson.M{"admin": 1}),
+	).Decode(&user)
+	if err != nil && err != mongo.ErrNoDocuments {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+		return false
+	}
+	if !user.Admin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return false
+	}
+
+	return true
+}
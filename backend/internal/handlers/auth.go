@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"context"
+	"log"
 	"net/http"
+	"regexp"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,6 +13,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 
+	"task-management/internal/audit"
 	"task-management/internal/middleware"
 	"task-management/internal/models"
 	"task-management/internal/utils"
@@ -21,14 +24,16 @@ type AuthHandler struct {
 	userCollection *mongo.Collection
 	validator      *validator.Validate
 	jwtMiddleware  *middleware.JwtMiddleware
+	activity       *audit.Log
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(userCollection *mongo.Collection, jwtMiddleware *middleware.JwtMiddleware) *AuthHandler {
+func NewAuthHandler(userCollection *mongo.Collection, jwtMiddleware *middleware.JwtMiddleware, activityLog *audit.Log) *AuthHandler {
 	return &AuthHandler{
 		userCollection: userCollection,
 		validator:      validator.New(),
 		jwtMiddleware:  jwtMiddleware,
+		activity:       activityLog,
 	}
 }
 
@@ -39,6 +44,7 @@ type RegisterRequest struct {
 	Password  string `json:"password" validate:"required,min=6"`
 	FirstName string `json:"firstName,omitempty"`
 	LastName  string `json:"lastName,omitempty"`
+	Timezone  string `json:"timezone,omitempty" validate:"omitempty,timezone"`
 }
 
 // LoginRequest represents the user login request
@@ -69,7 +75,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	// Check if user with email already exists
 	var existingUser models.User
-	err := h.userCollection.FindOne(context.Background(), bson.M{"email": req.Email}).Decode(&existingUser)
+	err := h.userCollection.FindOne(context.Background(), bson.M{"email": emailFilter(req.Email)}).Decode(&existingUser)
 	if err == nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "User with this email already exists"})
 		return
@@ -91,6 +97,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		Password:  req.Password,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
+		Timezone:  req.Timezone,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -109,12 +116,14 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	// Generate JWT token
-	token, err := h.jwtMiddleware.GenerateToken(user.ID, 24)
+	token, err := h.jwtMiddleware.GenerateToken(user.ID, user.TokenVersion, 24)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
+	h.recordAuthEvent(c, models.ActivityRegistered, &user.ID, user.Email, "")
+
 	// Return token and user
 	c.JSON(http.StatusCreated, AuthResponse{
 		Token: token,
@@ -145,26 +154,51 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	var user models.User
 	err := h.userCollection.FindOne(context.Background(), bson.M{"email": req.Email}).Decode(&user)
 	if err != nil {
+		h.recordAuthEvent(c, models.ActivityLoginFailed, nil, req.Email, "unknown email")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
 	}
 
 	// Compare passwords
 	if err := user.ComparePassword(req.Password); err != nil {
+		h.recordAuthEvent(c, models.ActivityLoginFailed, &user.ID, req.Email, "wrong password")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
 	}
 
 	// Generate JWT token
-	token, err := h.jwtMiddleware.GenerateToken(user.ID, 24)
+	token, err := h.jwtMiddleware.GenerateToken(user.ID, user.TokenVersion, 24)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
+	h.recordAuthEvent(c, models.ActivityLogin, &user.ID, user.Email, "")
+
 	// Return token and user
 	c.JSON(http.StatusOK, AuthResponse{
 		Token: token,
 		User:  user.ToResponse(),
 	})
 }
+
+// recordAuthEvent appends an authentication event to the activity log.
+// Auth routes are public, so the actor is the user the event is about.
+func (h *AuthHandler) recordAuthEvent(c *gin.Context, action string, userID *primitive.ObjectID, email, reason string) {
+	entry := newActivity(c, action)
+	entry.ActorID = userID
+	entry.Metadata = map[string]string{"email": email}
+	if reason != "" {
+		entry.Metadata["reason"] = reason
+	}
+
+	if err := h.activity.Record(context.Background(), entry); err != nil {
+		log.Printf("Failed to record %s activity: %v", action, err)
+	}
+}
+
+// emailFilter matches an email regardless of case. Emails are stored as
+// entered, so lookups that decide who owns an address ignore case.
+func emailFilter(email string) primitive.Regex {
+	return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(email) + "$", Options: "i"}
+}
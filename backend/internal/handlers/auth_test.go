@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestEmailFilter(t *testing.T) {
+	filter := emailFilter("Bob.Smith+tasks@x.com")
+	if filter.Options != "i" {
+		t.Fatalf("options = %q, want i", filter.Options)
+	}
+	re := regexp.MustCompile("(?" + filter.Options + ")" + filter.Pattern)
+
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"Bob.Smith+tasks@x.com", true},
+		{"bob.smith+tasks@X.COM", true},
+		{"bobxsmith+tasks@x.com", false},
+		{"bob.smithtasks@x.com", false},
+		{"alice.bob.smith+tasks@x.com", false},
+		{"bob.smith+tasks@x.com.evil", false},
+	}
+	for _, tt := range tests {
+		if got := re.MatchString(tt.email); got != tt.want {
+			t.Errorf("match %q = %v, want %v", tt.email, got, tt.want)
+		}
+	}
+}
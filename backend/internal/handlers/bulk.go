@@ -0,0 +1,469 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"task-management/internal/models"
+)
+
+// Bulk actions
+const (
+	bulkComplete = "complete"
+	bulkArchive  = "archive"
+	bulkDelete   = "delete"
+	bulkTag      = "tag"
+	bulkMove     = "move"
+)
+
+// Bulk item outcomes. Rolled back items succeeded but were undone because
+// another item of an all-or-nothing request failed.
+const (
+	bulkOK         = "ok"
+	bulkFailed     = "failed"
+	bulkSkipped    = "skipped"
+	bulkRolledBack = "rolled_back"
+)
+
+// errBulkAborted ends an all-or-nothing transaction after an item failed
+var errBulkAborted = errors.New("bulk operation aborted")
+
+// BulkItem names a goal, or one of its subtasks, to apply a bulk action to
+type BulkItem struct {
+	GoalID    primitive.ObjectID  `json:"goalId"`
+	SubTaskID *primitive.ObjectID `json:"subTaskId,omitempty"`
+}
+
+// BulkRequest represents the bulk goal request. tags are added by the tag
+// action. The move action places goals below parentId, or at the top level
+// when it's empty, and moves subtasks to targetGoalId. With atomic set,
+// either every item succeeds or none is applied.
+type BulkRequest struct {
+	Action       string     `json:"action" validate:"required,oneof=complete archive delete tag move"`
+	Items        []BulkItem `json:"items" validate:"required,min=1,max=100"`
+	Tags         []string   `json:"tags,omitempty"`
+	ParentID     string     `json:"parentId,omitempty"`
+	TargetGoalID string     `json:"targetGoalId,omitempty"`
+	Atomic       bool       `json:"atomic"`
+}
+
+// BulkResult is the outcome of one bulk item
+type BulkResult struct {
+	GoalID    primitive.ObjectID  `json:"goalId"`
+	SubTaskID *primitive.ObjectID `json:"subTaskId,omitempty"`
+	Status    string              `json:"status"`
+	Code      int                 `json:"code"`
+	Error     string              `json:"error,omitempty"`
+}
+
+// DuplicateGoalRequest represents the duplicate goal request. The title
+// defaults to the original's with " (copy)" appended.
+type DuplicateGoalRequest struct {
+	Title string `json:"title,omitempty" validate:"max=200"`
+}
+
+// bulkError is an item failure with the status it's reported with
+type bulkError struct {
+	code    int
+	message string
+}
+
+func (e *bulkError) Error() string {
+	return e.message
+}
+
+// bulkChange is a goal write made by a bulk item, recorded once the writes
+// are final
+type bulkChange struct {
+	action    string
+	subTaskID *primitive.ObjectID
+	before    *models.Goal
+	after     *models.Goal
+}
+
+// bulkTarget holds the parsed destinations of a move
+type bulkTarget struct {
+	parentID     *primitive.ObjectID
+	targetGoalID *primitive.ObjectID
+}
+
+// BulkGoals handles applying one action to many goals and subtasks. Each
+// item gets its own result; in atomic mode the items run in a transaction
+// and the first failure rolls all of them back.
+func (h *GoalHandler) BulkGoals(c *gin.Context) {
+	var req BulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for _, item := range req.Items {
+		if item.GoalID.IsZero() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Every item needs a goalId"})
+			return
+		}
+	}
+	if req.Action == bulkTag && len(req.Tags) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tags are required to tag items"})
+		return
+	}
+
+	var target bulkTarget
+	if req.ParentID != "" {
+		id, err := primitive.ObjectIDFromHex(req.ParentID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parent goal ID"})
+			return
+		}
+		target.parentID = &id
+	}
+	if req.TargetGoalID != "" {
+		id, err := primitive.ObjectIDFromHex(req.TargetGoalID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target goal ID"})
+			return
+		}
+		target.targetGoalID = &id
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	var (
+		results []BulkResult
+		changes []bulkChange
+	)
+	run := func(ctx context.Context) bool {
+		results = make([]BulkResult, len(req.Items))
+		changes = nil
+		failed := false
+		now := time.Now()
+		for i, item := range req.Items {
+			results[i] = BulkResult{GoalID: item.GoalID, SubTaskID: item.SubTaskID}
+			if failed && req.Atomic {
+				results[i].Status = bulkSkipped
+				continue
+			}
+
+			itemChanges, err := h.applyBulkItem(ctx, scope, &req, target, item, now)
+			if err != nil {
+				failed = true
+				results[i].Status = bulkFailed
+				results[i].Code, results[i].Error = bulkFailure(err)
+				continue
+			}
+			results[i].Status = bulkOK
+			results[i].Code = http.StatusOK
+			changes = append(changes, itemChanges...)
+		}
+		return !failed
+	}
+
+	if !req.Atomic {
+		run(context.Background())
+		h.recordBulkChanges(c, changes)
+		c.JSON(http.StatusOK, gin.H{"results": results, "committed": true})
+		return
+	}
+
+	session, err := h.goalCollection.Database().Client().StartSession()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start a transaction"})
+		return
+	}
+	defer session.EndSession(context.Background())
+
+	_, err = session.WithTransaction(context.Background(), func(sc mongo.SessionContext) (interface{}, error) {
+		if !run(sc) {
+			return nil, errBulkAborted
+		}
+		return nil, nil
+	})
+	if err == errBulkAborted {
+		for i := range results {
+			if results[i].Status == bulkOK {
+				results[i].Status = bulkRolledBack
+			}
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"results": results, "committed": false})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run the bulk operation in a transaction"})
+		return
+	}
+
+	h.recordBulkChanges(c, changes)
+	c.JSON(http.StatusOK, gin.H{"results": results, "committed": true})
+}
+
+// DuplicateGoal handles creating a deep copy of a goal owned by the caller,
+// with its completion reset. The copy stays below the original's parent
+// when the caller can edit it.
+func (h *GoalHandler) DuplicateGoal(c *gin.Context) {
+	var req DuplicateGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, scope, ok := h.loadGoal(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	// Guests can only work on goals shared with them
+	if scope.WorkspaceID != nil && !models.WorkspaceRoleAtLeast(scope.WorkspaceRole, models.WorkspaceRoleMember) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Guests can't create goals in this workspace"})
+		return
+	}
+
+	duplicate := goal.Duplicate(time.Now())
+	duplicate.UserID = scope.UserID
+	duplicate.WorkspaceID = scope.WorkspaceID
+	duplicate.Title = goal.Title + " (copy)"
+	if req.Title != "" {
+		duplicate.Title = req.Title
+	}
+	if duplicate.ParentID != nil {
+		if err := h.checkParent(context.Background(), scope, primitive.NilObjectID, *duplicate.ParentID); err != nil {
+			duplicate.ParentID = nil
+		}
+	}
+
+	if _, err := h.goalCollection.InsertOne(context.Background(), duplicate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to duplicate goal"})
+		return
+	}
+	h.goalChanged(c, models.ActivityGoalDuplicated, nil, nil, &duplicate)
+
+	c.JSON(http.StatusCreated, duplicate)
+}
+
+// applyBulkItem applies the request's action to one item, writing with ctx,
+// and returns the changes it made
+func (h *GoalHandler) applyBulkItem(ctx context.Context, scope goalScope, req *BulkRequest, target bulkTarget, item BulkItem, now time.Time) ([]bulkChange, error) {
+	minRole := models.RoleEditor
+	if req.Action == bulkDelete && item.SubTaskID == nil {
+		minRole = models.RoleOwner
+	}
+	goal, _, err := findGoalWithRole(ctx, h.goalCollection, item.GoalID, scope, minRole)
+	if err != nil {
+		return nil, err
+	}
+	before := goal.Clone()
+
+	if item.SubTaskID != nil {
+		return h.applyBulkSubTask(ctx, scope, req.Action, req.Tags, target, goal, *item.SubTaskID, now)
+	}
+
+	action := models.ActivityGoalUpdated
+	switch req.Action {
+	case bulkComplete:
+		if !goal.Completed {
+			goal.Completed = true
+			goal.CompletedAt = &now
+		}
+	case bulkArchive:
+		action = models.ActivityGoalArchived
+		if !goal.Archived {
+			goal.Archived = true
+			goal.ArchivedAt = &now
+		}
+	case bulkTag:
+		goal.Tags = mergeTags(goal.Tags, req.Tags)
+	case bulkMove:
+		action = models.ActivityGoalMoved
+		if target.parentID != nil {
+			if err := h.checkParent(ctx, scope, goal.ID, *target.parentID); err != nil {
+				return nil, parentFailure(err)
+			}
+		}
+		goal.ParentID = target.parentID
+	case bulkDelete:
+		// Sub-goals have to be moved or deleted first so none are orphaned
+		children, err := h.goalCollection.CountDocuments(ctx, bson.M{"parentId": goal.ID})
+		if err != nil {
+			return nil, err
+		}
+		if children > 0 {
+			return nil, &bulkError{http.StatusConflict, "Goal has sub-goals; move or delete them first"}
+		}
+		result, err := h.goalCollection.DeleteOne(ctx, bson.M{"_id": goal.ID, "updatedAt": before.UpdatedAt})
+		if err != nil {
+			return nil, err
+		}
+		if result.DeletedCount == 0 {
+			return nil, errGoalConflict
+		}
+		return []bulkChange{{action: models.ActivityGoalDeleted, before: before}}, nil
+	}
+
+	goal.UpdatedAt = now
+	if err := h.replaceGoal(ctx, before, goal); err != nil {
+		return nil, err
+	}
+	return []bulkChange{{action: action, before: before, after: goal}}, nil
+}
+
+// applyBulkSubTask applies a bulk action to one subtask of a loaded goal
+func (h *GoalHandler) applyBulkSubTask(ctx context.Context, scope goalScope, action string, tags []string, target bulkTarget, goal *models.Goal, subTaskID primitive.ObjectID, now time.Time) ([]bulkChange, error) {
+	before := goal.Clone()
+	task := goal.FindSubTask(subTaskID)
+	if task == nil {
+		return nil, &bulkError{http.StatusNotFound, "Subtask not found"}
+	}
+
+	activity := models.ActivitySubTaskUpdated
+	switch action {
+	case bulkArchive:
+		return nil, &bulkError{http.StatusBadRequest, "Subtasks can't be archived"}
+	case bulkComplete:
+		if !task.Completed {
+			task.Completed = true
+			task.CompletedAt = &now
+			task.UpdatedAt = now
+		}
+	case bulkTag:
+		task.Tags = mergeTags(task.Tags, tags)
+		task.UpdatedAt = now
+	case bulkDelete:
+		activity = models.ActivitySubTaskDeleted
+		removeSubTask(goal, subTaskID)
+	case bulkMove:
+		return h.moveSubTask(ctx, scope, target, goal, *task, now)
+	}
+
+	goal.UpdateCompletion(now)
+	goal.UpdatedAt = now
+	if err := h.replaceGoal(ctx, before, goal); err != nil {
+		return nil, err
+	}
+	return []bulkChange{{action: activity, subTaskID: &subTaskID, before: before, after: goal}}, nil
+}
+
+// moveSubTask moves a subtask to the target goal, keeping its ID and its
+// own dependencies
+func (h *GoalHandler) moveSubTask(ctx context.Context, scope goalScope, target bulkTarget, source *models.Goal, task models.SubTask, now time.Time) ([]bulkChange, error) {
+	if target.targetGoalID == nil {
+		return nil, &bulkError{http.StatusBadRequest, "targetGoalId is required to move subtasks"}
+	}
+	if *target.targetGoalID == source.ID {
+		return nil, &bulkError{http.StatusBadRequest, "Subtask is already in the target goal"}
+	}
+
+	destination, _, err := findGoalWithRole(ctx, h.goalCollection, *target.targetGoalID, scope, models.RoleEditor)
+	if err != nil {
+		if err == errGoalNotFound {
+			return nil, &bulkError{http.StatusBadRequest, "Target goal not found"}
+		}
+		return nil, err
+	}
+	if destination.Type == models.GoalTypeHabit {
+		return nil, &bulkError{http.StatusBadRequest, "Habit goals are tracked by check-ins"}
+	}
+
+	sourceBefore := source.Clone()
+	removeSubTask(source, task.ID)
+	source.UpdateCompletion(now)
+	source.UpdatedAt = now
+
+	destinationBefore := destination.Clone()
+	task.UpdatedAt = now
+	// Assignees have to be members of the goal the subtask is in
+	if task.AssigneeID != nil && destination.RoleOf(*task.AssigneeID) == "" {
+		task.Assign(nil, scope.UserID, now)
+	}
+	destination.SubTasks = append(destination.SubTasks, task)
+	destination.UpdateCompletion(now)
+	destination.UpdatedAt = now
+
+	if err := h.replaceGoal(ctx, sourceBefore, source); err != nil {
+		return nil, err
+	}
+	if err := h.replaceGoal(ctx, destinationBefore, destination); err != nil {
+		return nil, err
+	}
+	return []bulkChange{
+		{action: models.ActivitySubTaskDeleted, subTaskID: &task.ID, before: sourceBefore, after: source},
+		{action: models.ActivitySubTaskCreated, subTaskID: &task.ID, before: destinationBefore, after: destination},
+	}, nil
+}
+
+// recordBulkChanges records the history, activity, revisions and undo
+// entries of applied bulk changes
+func (h *GoalHandler) recordBulkChanges(c *gin.Context, changes []bulkChange) {
+	for _, change := range changes {
+		h.goalChanged(c, change.action, change.subTaskID, change.before, change.after)
+	}
+}
+
+// bulkFailure returns the status and message an item error is reported
+// with
+func bulkFailure(err error) (int, string) {
+	var itemErr *bulkError
+	switch {
+	case errors.As(err, &itemErr):
+		return itemErr.code, itemErr.message
+	case err == errGoalNotFound:
+		return http.StatusNotFound, "Goal not found"
+	case err == errForbidden:
+		return http.StatusForbidden, "You don't have permission to change this goal"
+	case err == errGoalConflict:
+		return http.StatusConflict, "Goal was modified by another request, please retry"
+	default:
+		return http.StatusInternalServerError, "Failed to update goal"
+	}
+}
+
+// parentFailure turns a checkParent error into an item error
+func parentFailure(err error) error {
+	switch err {
+	case errGoalNotFound:
+		return &bulkError{http.StatusBadRequest, "Parent goal not found"}
+	case errForbidden:
+		return &bulkError{http.StatusForbidden, "You don't have permission to add sub-goals to the parent goal"}
+	case errGoalCycle:
+		return &bulkError{http.StatusBadRequest, "A goal can't be moved below itself"}
+	default:
+		return err
+	}
+}
+
+// mergeTags adds tags that aren't there yet, keeping the existing order
+func mergeTags(existing, tags []string) []string {
+	merged := append([]string{}, existing...)
+	seen := make(map[string]bool, len(existing)+len(tags))
+	for _, tag := range existing {
+		seen[tag] = true
+	}
+	for _, tag := range tags {
+		if !seen[tag] {
+			seen[tag] = true
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
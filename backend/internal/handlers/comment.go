@@ -0,0 +1,506 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/markdown"
+	"task-management/internal/models"
+)
+
+// CommentHandler handles comment routes on goals and subtasks
+type CommentHandler struct {
+	commentCollection      *mongo.Collection
+	goalCollection         *mongo.Collection
+	userCollection         *mongo.Collection
+	workspaceCollection    *mongo.Collection
+	notificationCollection *mongo.Collection
+	validator              *validator.Validate
+}
+
+// NewCommentHandler creates a new comment handler
+func NewCommentHandler(commentCollection, goalCollection, userCollection, workspaceCollection, notificationCollection *mongo.Collection) *CommentHandler {
+	return &CommentHandler{
+		commentCollection:      commentCollection,
+		goalCollection:         goalCollection,
+		userCollection:         userCollection,
+		workspaceCollection:    workspaceCollection,
+		notificationCollection: notificationCollection,
+		validator:              validator.New(),
+	}
+}
+
+// CreateCommentRequest represents the create comment request
+type CreateCommentRequest struct {
+	Body     string              `json:"body" validate:"required,max=10000"`
+	ParentID *primitive.ObjectID `json:"parentId,omitempty"`
+}
+
+// UpdateCommentRequest represents the edit comment request
+type UpdateCommentRequest struct {
+	Body string `json:"body" validate:"required,max=10000"`
+}
+
+// errEmptyComment is returned for bodies with nothing left after sanitizing
+var errEmptyComment = errors.New("Comment body is empty")
+
+// sanitizeCommentBody sanitizes a comment body and checks that what is
+// stored fits within markdown.MaxLength bytes
+func sanitizeCommentBody(raw string) (string, error) {
+	body := markdown.Sanitize(raw)
+	if body == "" {
+		return "", errEmptyComment
+	}
+	if len(body) > markdown.MaxLength {
+		return "", fmt.Errorf("Comment body is longer than %d bytes", markdown.MaxLength)
+	}
+	return body, nil
+}
+
+// CommentThread is a comment with its replies
+type CommentThread struct {
+	models.Comment
+	Replies []*CommentThread `json:"replies"`
+}
+
+// ListComments handles listing the comments on a goal, or on one of its
+// subtasks when the route names one, as threads
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	goal, _, ok := h.loadGoal(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	subTaskID, ok := h.subTaskParam(c, goal)
+	if !ok {
+		return
+	}
+
+	filter := bson.M{"goalId": goal.ID}
+	if subTaskID != nil {
+		filter["subTaskId"] = *subTaskID
+	}
+
+	cursor, err := h.commentCollection.Find(context.Background(), filter,
+		options.Find().SetSort(bson.M{"createdAt": 1}),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	var comments []models.Comment
+	if err := cursor.All(context.Background(), &comments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode comments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, buildThreads(comments))
+}
+
+// CreateComment handles commenting on a goal or subtask, or replying to a comment
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	var req CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, scope, ok := h.loadGoal(c, models.RoleCommenter)
+	if !ok {
+		return
+	}
+
+	subTaskID, ok := h.subTaskParam(c, goal)
+	if !ok {
+		return
+	}
+
+	body, err := sanitizeCommentBody(req.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Replies stay on the goal and subtask of the comment they answer
+	if req.ParentID != nil {
+		var parent models.Comment
+		err := h.commentCollection.FindOne(context.Background(), bson.M{
+			"_id":    *req.ParentID,
+			"goalId": goal.ID,
+		}).Decode(&parent)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Parent comment not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get parent comment"})
+			return
+		}
+		if subTaskID != nil && (parent.SubTaskID == nil || *parent.SubTaskID != *subTaskID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parent comment belongs to another subtask"})
+			return
+		}
+		subTaskID = parent.SubTaskID
+	}
+
+	now := time.Now()
+	comment := models.Comment{
+		ID:        primitive.NewObjectID(),
+		GoalID:    goal.ID,
+		SubTaskID: subTaskID,
+		ParentID:  req.ParentID,
+		AuthorID:  scope.UserID,
+		Body:      body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	mentioned, err := h.resolveMentions(context.Background(), goal, body, scope.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve mentions"})
+		return
+	}
+	comment.Mentions = mentioned
+
+	if _, err := h.commentCollection.InsertOne(context.Background(), comment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comment"})
+		return
+	}
+
+	h.notifyMentions(goal, &comment, mentioned)
+
+	comment.Prepare()
+	c.JSON(http.StatusCreated, comment)
+}
+
+// UpdateComment handles editing a comment. Only the author can edit, and
+// the previous body is kept in the comment's edit history.
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	var req UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, scope, ok := h.loadGoal(c, models.RoleCommenter)
+	if !ok {
+		return
+	}
+
+	comment, ok := h.loadComment(c, goal)
+	if !ok {
+		return
+	}
+	if comment.AuthorID != scope.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can edit a comment"})
+		return
+	}
+
+	body, err := sanitizeCommentBody(req.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if body == comment.Body {
+		comment.Prepare()
+		c.JSON(http.StatusOK, comment)
+		return
+	}
+
+	mentioned, err := h.resolveMentions(context.Background(), goal, body, scope.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve mentions"})
+		return
+	}
+
+	// Only users who weren't already mentioned are notified again
+	previous := make(map[primitive.ObjectID]bool, len(comment.Mentions))
+	for _, id := range comment.Mentions {
+		previous[id] = true
+	}
+	var added []primitive.ObjectID
+	for _, id := range mentioned {
+		if !previous[id] {
+			added = append(added, id)
+		}
+	}
+
+	now := time.Now()
+	edit := models.CommentEdit{Body: comment.Body, EditedAt: now}
+	result, err := h.commentCollection.UpdateOne(context.Background(),
+		bson.M{"_id": comment.ID, "updatedAt": comment.UpdatedAt, "deletedAt": nil},
+		bson.M{
+			"$set":  bson.M{"body": body, "mentions": mentioned, "updatedAt": now},
+			"$push": bson.M{"edits": edit},
+		},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update comment"})
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Comment was modified by another request, please retry"})
+		return
+	}
+
+	comment.Body = body
+	comment.Mentions = mentioned
+	comment.Edits = append(comment.Edits, edit)
+	comment.UpdatedAt = now
+	h.notifyMentions(goal, comment, added)
+
+	comment.Prepare()
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment handles soft deleting a comment. The author and the goal's
+// owner can delete; replies stay visible under the deleted comment.
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	goal, scope, ok := h.loadGoal(c, models.RoleCommenter)
+	if !ok {
+		return
+	}
+
+	comment, ok := h.loadComment(c, goal)
+	if !ok {
+		return
+	}
+	if comment.AuthorID != scope.UserID && scope.roleOn(goal) != models.RoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to do this"})
+		return
+	}
+
+	now := time.Now()
+	_, err := h.commentCollection.UpdateOne(context.Background(),
+		bson.M{"_id": comment.ID, "deletedAt": nil},
+		bson.M{"$set": bson.M{"deletedAt": now, "deletedBy": scope.UserID, "updatedAt": now}},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+// GetCommentHistory handles listing the previous bodies of an edited comment
+func (h *CommentHandler) GetCommentHistory(c *gin.Context) {
+	goal, _, ok := h.loadGoal(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	comment, ok := h.loadComment(c, goal)
+	if !ok {
+		return
+	}
+
+	edits := comment.Edits
+	if edits == nil {
+		edits = []models.CommentEdit{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"current": comment.Body, "edits": edits})
+}
+
+// loadGoal fetches the goal named by the :id route parameter for the caller
+func (h *CommentHandler) loadGoal(c *gin.Context, minRole string) (*models.Goal, goalScope, bool) {
+	goalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal ID"})
+		return nil, goalScope{}, false
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return nil, goalScope{}, false
+	}
+
+	goal, _, err := findGoalWithRole(context.Background(), h.goalCollection, goalID, scope, minRole)
+	if err != nil {
+		respondGoalAccessError(c, err)
+		return nil, goalScope{}, false
+	}
+
+	return goal, scope, true
+}
+
+// subTaskParam reads the optional :subtaskId route parameter and checks
+// that the subtask belongs to the goal
+func (h *CommentHandler) subTaskParam(c *gin.Context, goal *models.Goal) (*primitive.ObjectID, bool) {
+	value := c.Param("subtaskId")
+	if value == "" {
+		return nil, true
+	}
+
+	subTaskID, err := primitive.ObjectIDFromHex(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subtask ID"})
+		return nil, false
+	}
+	if goal.FindSubTask(subTaskID) == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subtask not found"})
+		return nil, false
+	}
+
+	return &subTaskID, true
+}
+
+// loadComment fetches the live comment named by the :commentId route parameter
+func (h *CommentHandler) loadComment(c *gin.Context, goal *models.Goal) (*models.Comment, bool) {
+	commentID, err := primitive.ObjectIDFromHex(c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return nil, false
+	}
+
+	var comment models.Comment
+	err = h.commentCollection.FindOne(context.Background(), bson.M{
+		"_id":       commentID,
+		"goalId":    goal.ID,
+		"deletedAt": nil,
+	}).Decode(&comment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get comment"})
+		return nil, false
+	}
+
+	return &comment, true
+}
+
+// resolveMentions looks up the users mentioned in a comment body and keeps
+// those who can see the goal, leaving out the author
+func (h *CommentHandler) resolveMentions(ctx context.Context, goal *models.Goal, body string, authorID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	usernames := markdown.Mentions(body)
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := h.userCollection.Find(ctx,
+		bson.M{"username": bson.M{"$in": usernames}},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	var workspace *models.Workspace
+	if goal.WorkspaceID != nil {
+		workspace = &models.Workspace{}
+		if err := h.workspaceCollection.FindOne(ctx, bson.M{"_id": *goal.WorkspaceID}).Decode(workspace); err != nil && err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+	}
+
+	var mentioned []primitive.ObjectID
+	for _, user := range users {
+		if user.ID == authorID {
+			continue
+		}
+		canSee := goal.RoleOf(user.ID) != ""
+		if workspace != nil && models.GoalRole(workspace.RoleOf(user.ID)) != "" {
+			canSee = true
+		}
+		if canSee {
+			mentioned = append(mentioned, user.ID)
+		}
+	}
+
+	return mentioned, nil
+}
+
+// notifyMentions creates a mention notification for each user. Failures are
+// logged because the comment itself has already been saved.
+func (h *CommentHandler) notifyMentions(goal *models.Goal, comment *models.Comment, userIDs []primitive.ObjectID) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	name := "Someone"
+	var author models.User
+	if err := h.userCollection.FindOne(context.Background(), bson.M{"_id": comment.AuthorID}).Decode(&author); err != nil {
+		log.Printf("Failed to load comment author %s: %v", comment.AuthorID.Hex(), err)
+	} else {
+		name = "@" + author.Username
+	}
+
+	message := fmt.Sprintf("%s mentioned you on %q", name, goal.Title)
+	notifications := make([]interface{}, 0, len(userIDs))
+	for _, userID := range userIDs {
+		notifications = append(notifications, models.Notification{
+			ID:        primitive.NewObjectID(),
+			UserID:    userID,
+			Type:      models.NotificationMention,
+			ActorID:   comment.AuthorID,
+			GoalID:    goal.ID,
+			SubTaskID: comment.SubTaskID,
+			CommentID: &comment.ID,
+			Message:   message,
+			CreatedAt: comment.UpdatedAt,
+		})
+	}
+
+	if _, err := h.notificationCollection.InsertMany(context.Background(), notifications); err != nil {
+		log.Printf("Failed to create mention notifications for comment %s: %v", comment.ID.Hex(), err)
+	}
+}
+
+// buildThreads nests comments under their parents. Comments whose parent is
+// missing are shown at the top level.
+func buildThreads(comments []models.Comment) []*CommentThread {
+	threads := make(map[primitive.ObjectID]*CommentThread, len(comments))
+	for i := range comments {
+		comments[i].Prepare()
+		threads[comments[i].ID] = &CommentThread{Comment: comments[i], Replies: []*CommentThread{}}
+	}
+
+	roots := []*CommentThread{}
+	for i := range comments {
+		thread := threads[comments[i].ID]
+		if comments[i].ParentID != nil {
+			if parent, ok := threads[*comments[i].ParentID]; ok {
+				parent.Replies = append(parent.Replies, thread)
+				continue
+			}
+		}
+		roots = append(roots, thread)
+	}
+
+	return roots
+}
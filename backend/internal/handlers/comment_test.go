@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"task-management/internal/markdown"
+)
+
+func TestSanitizeCommentBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain", body: "  Looks good  ", want: "Looks good"},
+		{name: "html only", body: "<script></script>", wantErr: true},
+		{name: "at the limit", body: strings.Repeat("a", markdown.MaxLength), want: strings.Repeat("a", markdown.MaxLength)},
+		{name: "over the limit", body: strings.Repeat("a", markdown.MaxLength+1), wantErr: true},
+		// Validation counts characters, the limit is in bytes
+		{name: "multibyte over the limit", body: strings.Repeat("é", markdown.MaxLength/2+1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sanitizeCommentBody(tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sanitizeCommentBody() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("sanitizeCommentBody() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,486 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/models"
+	"task-management/internal/schedule"
+)
+
+// errDependencyCycle is returned when a dependency would make a subtask
+// wait on itself
+var errDependencyCycle = errors.New("dependency cycle")
+
+// maxDependencyWalk bounds how many subtasks a cycle check visits
+const maxDependencyWalk = 10000
+
+// AddDependencyRequest represents the add dependency request. goalId
+// defaults to the dependent subtask's goal.
+type AddDependencyRequest struct {
+	GoalID    *primitive.ObjectID `json:"goalId,omitempty"`
+	SubTaskID primitive.ObjectID  `json:"subTaskId"`
+}
+
+// ScheduledTask is a subtask with its computed start and end. External
+// tasks belong to other goals and block tasks of this one. Hidden tasks
+// belong to goals the caller can't see and only carry their ID, schedule
+// and completion.
+type ScheduledTask struct {
+	ID        primitive.ObjectID   `json:"id"`
+	GoalID    primitive.ObjectID   `json:"goalId"`
+	Title     string               `json:"title"`
+	Start     time.Time            `json:"start"`
+	End       time.Time            `json:"end"`
+	DueDate   *time.Time           `json:"dueDate,omitempty"`
+	Completed bool                 `json:"completed"`
+	Blocked   bool                 `json:"blocked"`
+	Late      bool                 `json:"late"`
+	Critical  bool                 `json:"critical"`
+	External  bool                 `json:"external"`
+	Hidden    bool                 `json:"hidden,omitempty"`
+	DependsOn []primitive.ObjectID `json:"dependsOn"`
+}
+
+// AddDependency handles declaring that a subtask can't start until another
+// subtask, possibly in another goal, is completed
+func (h *GoalHandler) AddDependency(c *gin.Context) {
+	subTaskID, err := primitive.ObjectIDFromHex(c.Param("subtaskId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subtask ID"})
+		return
+	}
+
+	var req AddDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.SubTaskID.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "subTaskId is required"})
+		return
+	}
+
+	goal, scope, ok := h.loadGoal(c, models.RoleEditor)
+	if !ok {
+		return
+	}
+	before := goal.Clone()
+
+	task := goal.FindSubTask(subTaskID)
+	if task == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subtask not found"})
+		return
+	}
+
+	dependency := models.Dependency{GoalID: goal.ID, SubTaskID: req.SubTaskID}
+	if req.GoalID != nil {
+		dependency.GoalID = *req.GoalID
+	}
+	if dependency.SubTaskID == subTaskID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A subtask can't depend on itself"})
+		return
+	}
+	for _, existing := range task.DependsOn {
+		if existing.SubTaskID == dependency.SubTaskID {
+			c.JSON(http.StatusConflict, gin.H{"error": "Subtask already depends on this subtask"})
+			return
+		}
+	}
+
+	// The blocking subtask has to be visible to the caller
+	blockerGoal := goal
+	if dependency.GoalID != goal.ID {
+		blockerGoal, _, err = findGoalWithRole(context.Background(), h.goalCollection, dependency.GoalID, scope, models.RoleViewer)
+		if err != nil {
+			if err == errGoalNotFound {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Blocking goal not found"})
+			} else {
+				respondGoalAccessError(c, err)
+			}
+			return
+		}
+	}
+	if blockerGoal.FindSubTask(dependency.SubTaskID) == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Blocking subtask not found"})
+		return
+	}
+
+	if err := h.checkNoDependencyCycle(context.Background(), goal, subTaskID, dependency); err != nil {
+		if err == errDependencyCycle {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "The blocking subtask already waits on this subtask"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check dependencies"})
+		}
+		return
+	}
+
+	task.DependsOn = append(append([]models.Dependency{}, task.DependsOn...), dependency)
+	task.UpdatedAt = time.Now()
+
+	h.saveGoal(c, models.ActivitySubTaskUpdated, &subTaskID, before, goal, http.StatusCreated)
+}
+
+// RemoveDependency handles removing one of a subtask's dependencies
+func (h *GoalHandler) RemoveDependency(c *gin.Context) {
+	subTaskID, err := primitive.ObjectIDFromHex(c.Param("subtaskId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subtask ID"})
+		return
+	}
+	dependencyID, err := primitive.ObjectIDFromHex(c.Param("dependencyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dependency ID"})
+		return
+	}
+
+	goal, _, ok := h.loadGoal(c, models.RoleEditor)
+	if !ok {
+		return
+	}
+	before := goal.Clone()
+
+	task := goal.FindSubTask(subTaskID)
+	if task == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subtask not found"})
+		return
+	}
+
+	remaining := make([]models.Dependency, 0, len(task.DependsOn))
+	for _, dependency := range task.DependsOn {
+		if dependency.SubTaskID != dependencyID {
+			remaining = append(remaining, dependency)
+		}
+	}
+	if len(remaining) == len(task.DependsOn) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dependency not found"})
+		return
+	}
+
+	task.DependsOn = remaining
+	task.UpdatedAt = time.Now()
+
+	h.saveGoal(c, models.ActivitySubTaskUpdated, &subTaskID, before, goal, http.StatusOK)
+}
+
+// GetSchedule handles computing a goal's earliest finish and critical path
+// from its subtasks' estimates, due dates and dependencies
+func (h *GoalHandler) GetSchedule(c *gin.Context) {
+	goal, _, ok := h.loadGoal(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	tasks, plan, ok := h.buildSchedule(c, goal)
+	if !ok {
+		return
+	}
+
+	criticalPath := []ScheduledTask{}
+	for _, task := range tasks {
+		if task.Critical {
+			criticalPath = append(criticalPath, task)
+		}
+	}
+	late := 0
+	for _, task := range tasks {
+		if task.Late && !task.External {
+			late++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"earliestFinish": plan.Finish,
+		"criticalPath":   criticalPath,
+		"late":           late,
+	})
+}
+
+// GetGantt handles listing a goal's subtasks, and the subtasks of other
+// goals blocking them, with computed start and end dates
+func (h *GoalHandler) GetGantt(c *gin.Context) {
+	goal, _, ok := h.loadGoal(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	tasks, plan, ok := h.buildSchedule(c, goal)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"earliestFinish": plan.Finish, "tasks": tasks})
+}
+
+// buildSchedule schedules a goal's subtasks together with the subtasks of
+// other goals they depend on, writing the error response and returning
+// false when it can't. Critical path tasks are returned in path order
+// before the rest.
+func (h *GoalHandler) buildSchedule(c *gin.Context, goal *models.Goal) ([]ScheduledTask, *schedule.Plan, bool) {
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	external, err := h.dependencyGoals(context.Background(), []*models.Goal{goal})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load dependencies"})
+		return nil, nil, false
+	}
+	visible, err := h.visibleGoals(context.Background(), scope, external)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load dependencies"})
+		return nil, nil, false
+	}
+
+	response := goal.Clone()
+	setBlocked([]*models.Goal{response}, external)
+
+	var inputs []schedule.Task
+	var tasks []ScheduledTask
+	add := func(owner *models.Goal, task *models.SubTask, external bool) {
+		input := schedule.Task{
+			ID:          task.ID,
+			Duration:    owner.TaskDuration(task),
+			Completed:   task.Completed,
+			CompletedAt: task.CompletedAt,
+			DueDate:     task.DueDate,
+		}
+		item := ScheduledTask{
+			ID:        task.ID,
+			GoalID:    owner.ID,
+			Title:     task.Title,
+			DueDate:   task.DueDate,
+			Completed: task.Completed,
+			Blocked:   task.Blocked,
+			External:  external,
+			DependsOn: []primitive.ObjectID{},
+		}
+		// Goals the caller can't see only show up as placeholders
+		if external && !visible[owner.ID] {
+			item.GoalID = primitive.NilObjectID
+			item.Title = ""
+			item.DueDate = nil
+			item.Hidden = true
+		}
+		// Dependencies of other goals' subtasks are outside this schedule
+		if !external {
+			for _, dependency := range task.DependsOn {
+				input.DependsOn = append(input.DependsOn, dependency.SubTaskID)
+				item.DependsOn = append(item.DependsOn, dependency.SubTaskID)
+			}
+		}
+		inputs = append(inputs, input)
+		tasks = append(tasks, item)
+	}
+
+	added := make(map[primitive.ObjectID]bool)
+	for i := range response.SubTasks {
+		add(response, &response.SubTasks[i], false)
+		added[response.SubTasks[i].ID] = true
+	}
+	for _, task := range response.SubTasks {
+		for _, dependency := range task.DependsOn {
+			owner, ok := external[dependency.GoalID]
+			if !ok || added[dependency.SubTaskID] {
+				continue
+			}
+			if blocker := owner.FindSubTask(dependency.SubTaskID); blocker != nil {
+				add(owner, blocker, true)
+				added[dependency.SubTaskID] = true
+			}
+		}
+	}
+
+	plan, err := schedule.Build(inputs, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule subtasks"})
+		return nil, nil, false
+	}
+
+	position := make(map[primitive.ObjectID]int, len(plan.CriticalPath))
+	for i, id := range plan.CriticalPath {
+		position[id] = i
+	}
+	ordered := make([]ScheduledTask, len(plan.CriticalPath), len(tasks))
+	for _, task := range tasks {
+		slot := plan.Slots[task.ID]
+		task.Start, task.End = slot.Start, slot.End
+		task.Late, task.Critical = slot.Late && !task.Hidden, slot.Critical
+		if task.Critical {
+			ordered[position[task.ID]] = task
+		} else {
+			ordered = append(ordered, task)
+		}
+	}
+
+	return ordered, plan, true
+}
+
+// checkNoDependencyCycle fails with errDependencyCycle if the subtask
+// subTaskID of goal is already among the subtasks that dependency waits on,
+// directly or through other dependencies. goal is used as modified.
+func (h *GoalHandler) checkNoDependencyCycle(ctx context.Context, goal *models.Goal, subTaskID primitive.ObjectID, dependency models.Dependency) error {
+	goals := map[primitive.ObjectID]*models.Goal{goal.ID: goal}
+	visited := make(map[primitive.ObjectID]bool)
+	queue := []models.Dependency{dependency}
+
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if next.SubTaskID == subTaskID {
+			return errDependencyCycle
+		}
+		if visited[next.SubTaskID] {
+			continue
+		}
+		visited[next.SubTaskID] = true
+		if len(visited) > maxDependencyWalk {
+			return errDependencyCycle
+		}
+
+		owner, ok := goals[next.GoalID]
+		if !ok {
+			owner = &models.Goal{}
+			err := h.goalCollection.FindOne(ctx,
+				bson.M{"_id": next.GoalID},
+				options.FindOne().SetProjection(bson.M{"subTasks._id": 1, "subTasks.dependsOn": 1}),
+			).Decode(owner)
+			if err != nil && err != mongo.ErrNoDocuments {
+				return err
+			}
+			goals[next.GoalID] = owner
+		}
+		if task := owner.FindSubTask(next.SubTaskID); task != nil {
+			queue = append(queue, task.DependsOn...)
+		}
+	}
+
+	return nil
+}
+
+// dependencyGoals loads the other goals that the subtasks of goals depend
+// on, keyed by ID
+func (h *GoalHandler) dependencyGoals(ctx context.Context, goals []*models.Goal) (map[primitive.ObjectID]*models.Goal, error) {
+	known := make(map[primitive.ObjectID]bool, len(goals))
+	for _, goal := range goals {
+		known[goal.ID] = true
+	}
+
+	var ids []primitive.ObjectID
+	for _, goal := range goals {
+		for _, task := range goal.SubTasks {
+			for _, dependency := range task.DependsOn {
+				if !known[dependency.GoalID] {
+					known[dependency.GoalID] = true
+					ids = append(ids, dependency.GoalID)
+				}
+			}
+		}
+	}
+
+	external := make(map[primitive.ObjectID]*models.Goal, len(ids))
+	if len(ids) == 0 {
+		return external, nil
+	}
+
+	cursor, err := h.goalCollection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var found []models.Goal
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	for i := range found {
+		external[found[i].ID] = &found[i]
+	}
+	return external, nil
+}
+
+// visibleGoals returns which of the goals the caller can access
+func (h *GoalHandler) visibleGoals(ctx context.Context, scope goalScope, goals map[primitive.ObjectID]*models.Goal) (map[primitive.ObjectID]bool, error) {
+	visible := make(map[primitive.ObjectID]bool, len(goals))
+	if len(goals) == 0 {
+		return visible, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(goals))
+	for id := range goals {
+		ids = append(ids, id)
+	}
+	filter := scope.accessibleFilter()
+	filter["_id"] = bson.M{"$in": ids}
+
+	cursor, err := h.goalCollection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var found []models.Goal
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	for _, goal := range found {
+		visible[goal.ID] = true
+	}
+	return visible, nil
+}
+
+// markBlocked sets the Blocked flag of the goals' subtasks. Dependencies on
+// subtasks that no longer exist don't block. Failures are logged and leave
+// the flags unset.
+func (h *GoalHandler) markBlocked(ctx context.Context, goals ...*models.Goal) {
+	external, err := h.dependencyGoals(ctx, goals)
+	if err != nil {
+		log.Printf("Failed to load subtask dependencies: %v", err)
+		return
+	}
+
+	setBlocked(goals, external)
+}
+
+// setBlocked sets the Blocked flag of the goals' subtasks, given the other
+// goals they depend on
+func setBlocked(goals []*models.Goal, external map[primitive.ObjectID]*models.Goal) {
+	open := make(map[primitive.ObjectID]bool)
+	for _, goal := range goals {
+		for _, task := range goal.SubTasks {
+			open[task.ID] = !task.Completed
+		}
+	}
+	for _, goal := range external {
+		for _, task := range goal.SubTasks {
+			open[task.ID] = !task.Completed
+		}
+	}
+
+	for _, goal := range goals {
+		for i := range goal.SubTasks {
+			task := &goal.SubTasks[i]
+			task.Blocked = false
+			if task.Completed {
+				continue
+			}
+			for _, dependency := range task.DependsOn {
+				if open[dependency.SubTaskID] {
+					task.Blocked = true
+					break
+				}
+			}
+		}
+	}
+}
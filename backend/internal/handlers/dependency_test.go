@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"task-management/internal/models"
+)
+
+func TestCheckNoDependencyCycle(t *testing.T) {
+	goalID := primitive.NewObjectID()
+	a, b, c := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	on := func(id primitive.ObjectID) models.Dependency {
+		return models.Dependency{GoalID: goalID, SubTaskID: id}
+	}
+	goal := &models.Goal{ID: goalID, SubTasks: []models.SubTask{
+		{ID: a},
+		{ID: b, DependsOn: []models.Dependency{on(a)}},
+		{ID: c, DependsOn: []models.Dependency{on(b)}},
+	}}
+
+	tests := []struct {
+		name       string
+		subTask    primitive.ObjectID
+		dependency models.Dependency
+		want       error
+	}{
+		{"on itself", a, on(a), errDependencyCycle},
+		{"direct cycle", a, on(b), errDependencyCycle},
+		{"transitive cycle", a, on(c), errDependencyCycle},
+		{"along the chain", c, on(a), nil},
+		{"reversed dependency", b, on(c), errDependencyCycle},
+	}
+
+	// Dependencies within the goal never reach the collection
+	h := &GoalHandler{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := h.checkNoDependencyCycle(context.Background(), goal, tt.subTask, tt.dependency); err != tt.want {
+				t.Errorf("checkNoDependencyCycle() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetBlocked(t *testing.T) {
+	goalID, otherID := primitive.NewObjectID(), primitive.NewObjectID()
+	open, done, external := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	deleted := primitive.NewObjectID()
+
+	goal := &models.Goal{ID: goalID, SubTasks: []models.SubTask{
+		{ID: open},
+		{ID: done, Completed: true},
+		{Title: "waits on open", DependsOn: []models.Dependency{{GoalID: goalID, SubTaskID: open}}},
+		{Title: "waits on done", DependsOn: []models.Dependency{{GoalID: goalID, SubTaskID: done}}},
+		{Title: "waits on other goal", DependsOn: []models.Dependency{{GoalID: otherID, SubTaskID: external}}},
+		{Title: "waits on deleted", DependsOn: []models.Dependency{{GoalID: goalID, SubTaskID: deleted}}},
+		{Title: "completed", Completed: true, Blocked: true, DependsOn: []models.Dependency{{GoalID: goalID, SubTaskID: open}}},
+	}}
+	others := map[primitive.ObjectID]*models.Goal{
+		otherID: {ID: otherID, SubTasks: []models.SubTask{{ID: external}}},
+	}
+
+	setBlocked([]*models.Goal{goal}, others)
+
+	want := map[string]bool{
+		"waits on open":       true,
+		"waits on done":       false,
+		"waits on other goal": true,
+		"waits on deleted":    false,
+		"completed":           false,
+	}
+	for _, task := range goal.SubTasks {
+		if blocked, ok := want[task.Title]; ok && task.Blocked != blocked {
+			t.Errorf("%s: blocked = %v, want %v", task.Title, task.Blocked, blocked)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"task-management/internal/models"
+)
+
+// Capacity window limits, in weeks
+const (
+	defaultCapacityWeeks = 4
+	maxCapacityWeeks     = 52
+)
+
+// CapacityBucket sums the remaining estimates of open subtasks by unit.
+// Tasks counts all open subtasks in the bucket, estimated or not.
+type CapacityBucket struct {
+	Effort map[string]float64 `json:"effort"`
+	Tasks  int                `json:"tasks"`
+}
+
+// CapacityWeek is the work due in the week starting on Start, a Monday
+type CapacityWeek struct {
+	Start string `json:"start"`
+	CapacityBucket
+}
+
+// CapacityResponse spreads the remaining work of open goals over the next
+// weeks by subtask due date
+type CapacityResponse struct {
+	Weeks       []CapacityWeek     `json:"weeks"`
+	Overdue     CapacityBucket     `json:"overdue"`
+	Later       CapacityBucket     `json:"later"`
+	Unscheduled CapacityBucket     `json:"unscheduled"`
+	Total       map[string]float64 `json:"total"`
+	Unestimated int                `json:"unestimated"`
+}
+
+// GetGoalEffort handles comparing a goal's estimated and remaining effort
+func (h *GoalHandler) GetGoalEffort(c *gin.Context) {
+	goal, _, ok := h.loadGoal(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, goal.Effort())
+}
+
+// GetCapacity handles summing the remaining effort of the open subtasks of
+// all open goals the user can access, week by week for the next weeks
+// weeks. assigneeId narrows it to one person's subtasks, and "me" to the
+// caller's.
+func (h *StatsHandler) GetCapacity(c *gin.Context) {
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	weeks := defaultCapacityWeeks
+	if value := c.Query("weeks"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxCapacityWeeks {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "weeks must be between 1 and 52"})
+			return
+		}
+		weeks = n
+	}
+
+	var assigneeID *primitive.ObjectID
+	switch value := c.Query("assigneeId"); value {
+	case "":
+	case "me":
+		assigneeID = &scope.UserID
+	default:
+		id, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignee ID"})
+			return
+		}
+		assigneeID = &id
+	}
+
+	loc, err := resolveLocation(c, h.userCollection, scope.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := scope.accessibleFilter()
+	filter["completed"] = false
+	openTask := bson.M{"completed": false}
+	if assigneeID != nil {
+		openTask["assigneeId"] = *assigneeID
+	}
+	filter["subTasks"] = bson.M{"$elemMatch": openTask}
+
+	cursor, err := h.goalCollection.Find(context.Background(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch goals"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	var goals []models.Goal
+	if err := cursor.All(context.Background(), &goals); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode goals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, buildCapacity(goals, assigneeID, weeks, time.Now().In(loc)))
+}
+
+// buildCapacity buckets the open subtasks of goals by the week they are due,
+// starting with the current week of now
+func buildCapacity(goals []models.Goal, assigneeID *primitive.ObjectID, weeks int, now time.Time) CapacityResponse {
+	// Weeks start on Monday, as in the statistics
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	end := start.AddDate(0, 0, 7*weeks)
+
+	response := CapacityResponse{
+		Weeks:       make([]CapacityWeek, weeks),
+		Overdue:     CapacityBucket{Effort: map[string]float64{}},
+		Later:       CapacityBucket{Effort: map[string]float64{}},
+		Unscheduled: CapacityBucket{Effort: map[string]float64{}},
+		Total:       map[string]float64{},
+	}
+	for i := range response.Weeks {
+		response.Weeks[i] = CapacityWeek{
+			Start:          start.AddDate(0, 0, 7*i).Format(dayLayout),
+			CapacityBucket: CapacityBucket{Effort: map[string]float64{}},
+		}
+	}
+
+	for _, goal := range goals {
+		unit := goal.Unit()
+		for _, task := range goal.SubTasks {
+			if task.Completed {
+				continue
+			}
+			if assigneeID != nil && (task.AssigneeID == nil || *task.AssigneeID != *assigneeID) {
+				continue
+			}
+
+			var bucket *CapacityBucket
+			switch {
+			case task.DueDate == nil:
+				bucket = &response.Unscheduled
+			case task.DueDate.Before(today):
+				bucket = &response.Overdue
+			case !task.DueDate.Before(end):
+				bucket = &response.Later
+			default:
+				// Weeks are stepped by calendar so daylight saving shifts don't
+				// move tasks across week boundaries
+				week := 0
+				for week < weeks-1 && !task.DueDate.Before(start.AddDate(0, 0, 7*(week+1))) {
+					week++
+				}
+				bucket = &response.Weeks[week].CapacityBucket
+			}
+
+			bucket.Tasks++
+			if task.Estimate == nil {
+				response.Unestimated++
+				continue
+			}
+			bucket.Effort[unit] += *task.Estimate
+			response.Total[unit] += *task.Estimate
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,514 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/events"
+	"task-management/internal/models"
+)
+
+// Default pomodoro lengths, in minutes
+const (
+	defaultWorkMinutes  = 25
+	defaultBreakMinutes = 5
+)
+
+// Focus statistics window limits
+const (
+	defaultFocusDays  = 7
+	maxFocusDays      = 90
+	defaultFocusWeeks = 8
+	maxFocusWeeks     = 52
+)
+
+// focusHeartbeat is how often the event stream sends a keep-alive comment
+const focusHeartbeat = 30 * time.Second
+
+// FocusHandler handles focus session routes
+type FocusHandler struct {
+	sessionCollection *mongo.Collection
+	goalCollection    *mongo.Collection
+	userCollection    *mongo.Collection
+	broker            *events.Broker
+	validator         *validator.Validate
+}
+
+// NewFocusHandler creates a new focus session handler
+func NewFocusHandler(sessionCollection, goalCollection, userCollection *mongo.Collection, broker *events.Broker) *FocusHandler {
+	return &FocusHandler{
+		sessionCollection: sessionCollection,
+		goalCollection:    goalCollection,
+		userCollection:    userCollection,
+		broker:            broker,
+		validator:         validator.New(),
+	}
+}
+
+// StartFocusRequest represents the start focus session request. Lengths
+// default to 25 minutes of work and a 5 minute break.
+type StartFocusRequest struct {
+	GoalID       *primitive.ObjectID `json:"goalId,omitempty"`
+	SubTaskID    *primitive.ObjectID `json:"subTaskId,omitempty"`
+	WorkMinutes  int                 `json:"workMinutes,omitempty" validate:"omitempty,min=1,max=180"`
+	BreakMinutes *int                `json:"breakMinutes,omitempty" validate:"omitempty,min=0,max=60"`
+}
+
+// FocusPeriod sums the focus sessions that started in one day or week
+type FocusPeriod struct {
+	Start        string `json:"start"`
+	FocusSeconds int64  `json:"focusSeconds"`
+	Completed    int    `json:"completed"`
+	Abandoned    int    `json:"abandoned"`
+}
+
+// StartFocusSession handles starting a focus session, optionally on a
+// subtask. Users can only have one active session.
+func (h *FocusHandler) StartFocusSession(c *gin.Context) {
+	var req StartFocusRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.SubTaskID != nil && req.GoalID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "goalId is required with subTaskId"})
+		return
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	session := models.FocusSession{
+		ID:           primitive.NewObjectID(),
+		UserID:       scope.UserID,
+		WorkspaceID:  scope.WorkspaceID,
+		WorkMinutes:  defaultWorkMinutes,
+		BreakMinutes: defaultBreakMinutes,
+		State:        models.FocusRunning,
+		StartedAt:    now,
+		Transitions:  []models.FocusTransition{{State: models.FocusRunning, At: now}},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if req.WorkMinutes > 0 {
+		session.WorkMinutes = req.WorkMinutes
+	}
+	if req.BreakMinutes != nil {
+		session.BreakMinutes = *req.BreakMinutes
+	}
+
+	if req.GoalID != nil {
+		goal, _, err := findGoalWithRole(context.Background(), h.goalCollection, *req.GoalID, scope, models.RoleEditor)
+		if err != nil {
+			respondGoalAccessError(c, err)
+			return
+		}
+		if req.SubTaskID != nil && goal.FindSubTask(*req.SubTaskID) == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Subtask not found"})
+			return
+		}
+		session.GoalID = &goal.ID
+		session.SubTaskID = req.SubTaskID
+		session.WorkspaceID = goal.WorkspaceID
+	}
+
+	active, err := h.activeSession(context.Background(), scope.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check focus sessions"})
+		return
+	}
+	if active != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "A focus session is already active; complete or abandon it first", "session": active})
+		return
+	}
+
+	if _, err := h.sessionCollection.InsertOne(context.Background(), session); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start focus session"})
+		return
+	}
+	h.publish(&session)
+
+	c.JSON(http.StatusCreated, session)
+}
+
+// GetCurrentFocusSession handles getting the caller's active focus session,
+// which is null when there is none
+func (h *FocusHandler) GetCurrentFocusSession(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	session, err := h.activeSession(context.Background(), userID.(primitive.ObjectID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get focus session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"session": session})
+}
+
+// ListFocusSessions handles listing the caller's focus sessions, newest
+// first. goalId and subTaskId narrow the list to one goal or subtask.
+func (h *FocusHandler) ListFocusSessions(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	filter := bson.M{"userId": userID.(primitive.ObjectID)}
+	if value := c.Query("goalId"); value != "" {
+		goalID, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal ID"})
+			return
+		}
+		filter["goalId"] = goalID
+	}
+	if value := c.Query("subTaskId"); value != "" {
+		subTaskID, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subtask ID"})
+			return
+		}
+		filter["subTaskId"] = subTaskID
+	}
+
+	limit, err := pageQuery(c, filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cursor, err := h.sessionCollection.Find(context.Background(), filter,
+		options.Find().SetSort(bson.M{"_id": -1}).SetLimit(limit),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch focus sessions"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	sessions := []models.FocusSession{}
+	if err := cursor.All(context.Background(), &sessions); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode focus sessions"})
+		return
+	}
+	now := time.Now()
+	for i := range sessions {
+		if sessions[i].Refresh(now) {
+			h.saveSession(context.Background(), &sessions[i])
+		}
+	}
+
+	// The next page starts before the last session
+	var next string
+	if int64(len(sessions)) == limit {
+		next = sessions[len(sessions)-1].ID.Hex()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions, "next": next})
+}
+
+// PauseFocusSession handles pausing a running focus session
+func (h *FocusHandler) PauseFocusSession(c *gin.Context) {
+	h.transition(c, models.FocusPause)
+}
+
+// ResumeFocusSession handles resuming a paused focus session
+func (h *FocusHandler) ResumeFocusSession(c *gin.Context) {
+	h.transition(c, models.FocusResume)
+}
+
+// CompleteFocusSession handles finishing a focus session's work interval,
+// which starts its break
+func (h *FocusHandler) CompleteFocusSession(c *gin.Context) {
+	h.transition(c, models.FocusComplete)
+}
+
+// AbandonFocusSession handles giving up on a focus session
+func (h *FocusHandler) AbandonFocusSession(c *gin.Context) {
+	h.transition(c, models.FocusAbandon)
+}
+
+// transition applies an action to the caller's session named by the
+// :sessionId route parameter and responds with the session
+func (h *FocusHandler) transition(c *gin.Context, action string) {
+	sessionID, err := primitive.ObjectIDFromHex(c.Param("sessionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid focus session ID"})
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	var session models.FocusSession
+	err = h.sessionCollection.FindOne(context.Background(), bson.M{
+		"_id":    sessionID,
+		"userId": userID.(primitive.ObjectID),
+	}).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Focus session not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get focus session"})
+		}
+		return
+	}
+
+	// A session whose work interval ran out has already completed
+	now := time.Now()
+	completed := session.Refresh(now)
+	if !(completed && action == models.FocusComplete) {
+		if err := session.Apply(action, now); err != nil {
+			if completed {
+				h.saveSession(context.Background(), &session)
+			}
+			c.JSON(http.StatusConflict, gin.H{"error": "Can't " + action + " a " + session.State + " focus session", "session": session})
+			return
+		}
+	}
+
+	if !h.saveSession(context.Background(), &session) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Focus session was modified by another request, please retry"})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// GetFocusStats handles summing the caller's focus time and sessions per
+// day for the last days days and per week for the last weeks weeks
+func (h *FocusHandler) GetFocusStats(c *gin.Context) {
+	// Get user ID from context
+	userIDValue, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	userID := userIDValue.(primitive.ObjectID)
+
+	days, ok := intQuery(c, "days", defaultFocusDays, maxFocusDays)
+	if !ok {
+		return
+	}
+	weeks, ok := intQuery(c, "weeks", defaultFocusWeeks, maxFocusWeeks)
+	if !ok {
+		return
+	}
+
+	loc, err := resolveLocation(c, h.userCollection, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	firstDay := today.AddDate(0, 0, -(days - 1))
+	// Weeks start on Monday, as in the statistics
+	thisWeek := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	firstWeek := thisWeek.AddDate(0, 0, -7*(weeks-1))
+	since := firstDay
+	if firstWeek.Before(since) {
+		since = firstWeek
+	}
+
+	cursor, err := h.sessionCollection.Find(context.Background(), bson.M{
+		"userId":    userID,
+		"startedAt": bson.M{"$gte": since},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch focus sessions"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	var sessions []models.FocusSession
+	if err := cursor.All(context.Background(), &sessions); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode focus sessions"})
+		return
+	}
+
+	daily := make([]FocusPeriod, days)
+	for i := range daily {
+		daily[i].Start = firstDay.AddDate(0, 0, i).Format(dayLayout)
+	}
+	weekly := make([]FocusPeriod, weeks)
+	for i := range weekly {
+		weekly[i].Start = firstWeek.AddDate(0, 0, 7*i).Format(dayLayout)
+	}
+
+	var total FocusPeriod
+	for i := range sessions {
+		session := &sessions[i]
+		session.Refresh(now)
+
+		started := session.StartedAt.In(loc)
+		day := time.Date(started.Year(), started.Month(), started.Day(), 0, 0, 0, 0, loc)
+		week := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		for _, period := range []*FocusPeriod{
+			findPeriod(daily, day.Format(dayLayout)),
+			findPeriod(weekly, week.Format(dayLayout)),
+		} {
+			if period == nil {
+				continue
+			}
+			addFocus(period, session)
+		}
+		if !day.Before(firstDay) {
+			addFocus(&total, session)
+		}
+	}
+	total.Start = firstDay.Format(dayLayout)
+
+	c.JSON(http.StatusOK, gin.H{"daily": daily, "weekly": weekly, "total": total})
+}
+
+// FocusEvents handles streaming the caller's focus session changes as
+// server-sent events
+func (h *FocusHandler) FocusEvents(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	stream, unsubscribe := h.broker.Subscribe(userID.(primitive.ObjectID))
+	defer unsubscribe()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(focusHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event := <-stream:
+			c.SSEvent(event.Type, event.Data)
+			return true
+		case <-heartbeat.C:
+			// Comments keep proxies from closing an idle connection
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		}
+	})
+}
+
+// activeSession returns the user's running or paused session, or nil.
+// Sessions whose work interval has run out are completed first.
+func (h *FocusHandler) activeSession(ctx context.Context, userID primitive.ObjectID) (*models.FocusSession, error) {
+	var session models.FocusSession
+	err := h.sessionCollection.FindOne(ctx, bson.M{
+		"userId": userID,
+		"state":  bson.M{"$in": bson.A{models.FocusRunning, models.FocusPaused}},
+	}).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Refresh(time.Now()) {
+		h.saveSession(ctx, &session)
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// saveSession writes a changed session, guarded by its previous updatedAt,
+// and pushes it to the user's clients. It reports whether the write
+// matched; failures are logged.
+func (h *FocusHandler) saveSession(ctx context.Context, session *models.FocusSession) bool {
+	previous := session.UpdatedAt
+	session.UpdatedAt = time.Now()
+
+	result, err := h.sessionCollection.ReplaceOne(ctx, bson.M{"_id": session.ID, "updatedAt": previous}, session)
+	if err != nil {
+		log.Printf("Failed to save focus session %s: %v", session.ID.Hex(), err)
+		return false
+	}
+	if result.MatchedCount == 0 {
+		return false
+	}
+
+	h.publish(session)
+	return true
+}
+
+// publish pushes a session's state to the user's connected clients
+func (h *FocusHandler) publish(session *models.FocusSession) {
+	h.broker.Publish(session.UserID, events.Event{Type: "focus." + session.State, Data: session})
+}
+
+// findPeriod returns the period starting on start, or nil
+func findPeriod(periods []FocusPeriod, start string) *FocusPeriod {
+	for i := range periods {
+		if periods[i].Start == start {
+			return &periods[i]
+		}
+	}
+	return nil
+}
+
+// addFocus counts a session towards a period
+func addFocus(period *FocusPeriod, session *models.FocusSession) {
+	period.FocusSeconds += session.FocusSeconds
+	switch session.State {
+	case models.FocusCompleted:
+		period.Completed++
+	case models.FocusAbandoned:
+		period.Abandoned++
+	}
+}
+
+// intQuery reads an optional integer query parameter between 1 and max,
+// writing the error response and returning false when it is invalid
+func intQuery(c *gin.Context, name string, fallback, max int) (int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, true
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > max {
+		c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be between 1 and " + strconv.Itoa(max)})
+		return 0, false
+	}
+	return n, true
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"log"
 	"net/http"
 	"time"
 
@@ -12,20 +13,37 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"task-management/internal/audit"
+	"task-management/internal/history"
 	"task-management/internal/models"
+	"task-management/internal/query"
+	"task-management/internal/revisions"
+	"task-management/internal/undo"
 )
 
 // GoalHandler handles goal related routes
 type GoalHandler struct {
 	goalCollection *mongo.Collection
+	userCollection *mongo.Collection
 	validator      *validator.Validate
+	statsCache     *StatsCache
+	history        *history.Recorder
+	activity       *audit.Log
+	revisions      *revisions.Store
+	undo           *undo.Manager
 }
 
 // NewGoalHandler creates a new goal handler
-func NewGoalHandler(goalCollection *mongo.Collection) *GoalHandler {
+func NewGoalHandler(goalCollection, userCollection *mongo.Collection, statsCache *StatsCache, recorder *history.Recorder, activityLog *audit.Log, revisionStore *revisions.Store, undoManager *undo.Manager) *GoalHandler {
 	return &GoalHandler{
 		goalCollection: goalCollection,
+		userCollection: userCollection,
 		validator:      validator.New(),
+		statsCache:     statsCache,
+		history:        recorder,
+		activity:       activityLog,
+		revisions:      revisionStore,
+		undo:           undoManager,
 	}
 }
 
@@ -33,24 +51,43 @@ func NewGoalHandler(goalCollection *mongo.Collection) *GoalHandler {
 type CreateGoalRequest struct {
 	Title       string     `json:"title" validate:"required"`
 	Description string     `json:"description,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	Priority    string     `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent"`
 	StartDate   time.Time  `json:"startDate"`
 	EndDate     *time.Time `json:"endDate,omitempty"`
+	// ParentID places the new goal below an existing goal
+	ParentID     *primitive.ObjectID `json:"parentId,omitempty"`
+	EstimateUnit string              `json:"estimateUnit,omitempty" validate:"omitempty,oneof=points hours"`
+	// Habit makes the goal a habit tracked by check-ins
+	Habit *HabitRequest `json:"habit,omitempty"`
 }
 
 // UpdateGoalRequest represents the update goal request
 type UpdateGoalRequest struct {
 	Title       string     `json:"title,omitempty"`
 	Description string     `json:"description,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	Priority    string     `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent"`
 	StartDate   time.Time  `json:"startDate,omitempty"`
 	EndDate     *time.Time `json:"endDate,omitempty"`
 	Completed   bool       `json:"completed,omitempty"`
+	// EstimateUnit changes the unit of the subtasks' estimates without
+	// converting them
+	EstimateUnit string `json:"estimateUnit,omitempty" validate:"omitempty,oneof=points hours"`
+	// Archived hides the goal from the goal list, or brings it back
+	Archived *bool `json:"archived,omitempty"`
 }
 
 // AddSubTaskRequest represents the add subtask request
 type AddSubTaskRequest struct {
-	Title       string     `json:"title" validate:"required"`
-	Description string     `json:"description,omitempty"`
-	DueDate     *time.Time `json:"dueDate,omitempty"`
+	Title       string              `json:"title" validate:"required"`
+	Description string              `json:"description,omitempty"`
+	DueDate     *time.Time          `json:"dueDate,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Priority    string              `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent"`
+	AssigneeID  *primitive.ObjectID `json:"assigneeId,omitempty"`
+	Weight      *float64            `json:"weight,omitempty" validate:"omitempty,gt=0"`
+	Estimate    *float64            `json:"estimate,omitempty" validate:"omitempty,gte=0"`
 }
 
 // CreateGoal handles goal creation
@@ -67,36 +104,90 @@ func (h *GoalHandler) CreateGoal(c *gin.Context) {
 		return
 	}
 
-	// Get user ID from context
-	userID, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	goal, ok := h.createGoal(c, scope, &req)
+	if !ok {
 		return
 	}
 
+	c.JSON(http.StatusCreated, goal)
+}
+
+// createGoal creates a goal from a validated request and records it. It
+// writes the error response and returns false when the goal can't be
+// created.
+func (h *GoalHandler) createGoal(c *gin.Context, scope goalScope, req *CreateGoalRequest) (*models.Goal, bool) {
+	// Guests can only work on goals shared with them
+	if scope.WorkspaceID != nil && !models.WorkspaceRoleAtLeast(scope.WorkspaceRole, models.WorkspaceRoleMember) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Guests can't create goals in this workspace"})
+		return nil, false
+	}
+
+	var habit *models.Habit
+	if req.Habit != nil {
+		if err := h.validator.Struct(req.Habit); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		var err error
+		if habit, err = newHabit(req.Habit); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, false
+		}
+	}
+
+	if req.ParentID != nil {
+		if err := h.checkParent(context.Background(), scope, primitive.NilObjectID, *req.ParentID); err != nil {
+			respondParentError(c, err)
+			return nil, false
+		}
+	}
+
 	now := time.Now()
 	goal := models.Goal{
-		ID:          primitive.NewObjectID(),
-		UserID:      userID.(primitive.ObjectID),
-		Title:       req.Title,
-		Description: req.Description,
-		SubTasks:    []models.SubTask{},
-		StartDate:   req.StartDate,
-		EndDate:     req.EndDate,
-		Completed:   false,
-		Progress:    0,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		ID:           primitive.NewObjectID(),
+		UserID:       scope.UserID,
+		WorkspaceID:  scope.WorkspaceID,
+		ParentID:     req.ParentID,
+		EstimateUnit: req.EstimateUnit,
+		Title:        req.Title,
+		Description:  req.Description,
+		Habit:        habit,
+		SubTasks:     []models.SubTask{},
+		Tags:         req.Tags,
+		Priority:     req.Priority,
+		StartDate:    req.StartDate,
+		EndDate:      req.EndDate,
+		Completed:    false,
+		Progress:     0,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if habit != nil {
+		goal.Type = models.GoalTypeHabit
 	}
 
 	// Insert goal to database
 	_, err := h.goalCollection.InsertOne(context.Background(), goal)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create goal"})
-		return
+		return nil, false
 	}
+	h.goalChanged(c, models.ActivityGoalCreated, nil, nil, &goal)
 
-	c.JSON(http.StatusCreated, goal)
+	return &goal, true
+}
+
+// GoalWithRole is a goal annotated with the caller's role on it
+type GoalWithRole struct {
+	models.Goal
+	Role string `json:"role"`
 }
 
 // GetGoal handles getting a single goal
@@ -107,44 +198,58 @@ func (h *GoalHandler) GetGoal(c *gin.Context) {
 		return
 	}
 
-	// Get user ID from context
-	userID, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
 		return
 	}
 
-	// Find goal by ID and user ID
-	var goal models.Goal
-	err = h.goalCollection.FindOne(context.Background(), bson.M{
-		"_id":    goalID,
-		"userId": userID.(primitive.ObjectID),
-	}).Decode(&goal)
-
+	// Find goal by ID among the goals the user can access
+	goal, role, err := findGoalWithRole(context.Background(), h.goalCollection, goalID, scope, models.RoleViewer)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get goal"})
-		}
+		respondGoalAccessError(c, err)
+		return
+	}
+	h.markBlocked(context.Background(), goal)
+	if !h.refreshHabitProgress(c, scope, goal) {
 		return
 	}
 
-	c.JSON(http.StatusOK, goal)
+	c.JSON(http.StatusOK, GoalWithRole{Goal: *goal, Role: role})
 }
 
-// ListGoals handles listing all goals for a user
+// ListGoals handles listing all goals owned by or shared with a user.
+// archived=true lists the archived goals instead and q narrows them down
+// with a filter query.
 func (h *GoalHandler) ListGoals(c *gin.Context) {
-	// Get user ID from context
-	userID, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
 		return
 	}
 
-	// Find all goals by user ID
+	// Find all goals the user can access in the active workspace.
+	// Archived goals are only listed on request.
+	filter := scope.accessibleFilter()
+	if c.Query("archived") == "true" {
+		filter["archived"] = true
+	} else {
+		filter["archived"] = bson.M{"$ne": true}
+	}
+	if q := c.Query("q"); q != "" {
+		expr, ok := parseGoalQuery(c, q)
+		if !ok {
+			return
+		}
+		loc, err := resolveLocation(c, h.userCollection, scope.UserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filter["$and"] = bson.A{expr.Mongo(query.Env{Now: time.Now().In(loc), UserID: scope.UserID})}
+	}
 	cursor, err := h.goalCollection.Find(context.Background(),
-		bson.M{"userId": userID.(primitive.ObjectID)},
+		filter,
 		options.Find().SetSort(bson.M{"createdAt": -1}),
 	)
 	if err != nil {
@@ -159,7 +264,21 @@ func (h *GoalHandler) ListGoals(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, goals)
+	pointers := make([]*models.Goal, len(goals))
+	for i := range goals {
+		pointers[i] = &goals[i]
+	}
+	h.markBlocked(context.Background(), pointers...)
+	if !h.refreshHabitProgress(c, scope, pointers...) {
+		return
+	}
+
+	response := make([]GoalWithRole, 0, len(goals))
+	for _, goal := range goals {
+		response = append(response, GoalWithRole{Goal: goal, Role: scope.roleOn(&goal)})
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // UpdateGoal handles updating a goal
@@ -170,10 +289,9 @@ func (h *GoalHandler) UpdateGoal(c *gin.Context) {
 		return
 	}
 
-	// Get user ID from context
-	userID, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
 		return
 	}
 
@@ -183,9 +301,24 @@ func (h *GoalHandler) UpdateGoal(c *gin.Context) {
 		return
 	}
 
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Load the current goal so completion time is only set on transition
+	existing, _, err := findGoalWithRole(context.Background(), h.goalCollection, goalID, scope, models.RoleEditor)
+	if err != nil {
+		respondGoalAccessError(c, err)
+		return
+	}
+
+	now := time.Now()
 	update := bson.M{
-		"updatedAt": time.Now(),
+		"updatedAt": now,
 	}
+	unset := bson.M{}
 
 	if req.Title != "" {
 		update["title"] = req.Title
@@ -199,15 +332,39 @@ func (h *GoalHandler) UpdateGoal(c *gin.Context) {
 	if req.EndDate != nil {
 		update["endDate"] = req.EndDate
 	}
+	if req.Tags != nil {
+		update["tags"] = req.Tags
+	}
+	if req.Priority != "" {
+		update["priority"] = req.Priority
+	}
+	if req.EstimateUnit != "" {
+		update["estimateUnit"] = req.EstimateUnit
+	}
+	if req.Archived != nil && *req.Archived != existing.Archived {
+		update["archived"] = *req.Archived
+		if *req.Archived {
+			update["archivedAt"] = now
+		} else {
+			unset["archivedAt"] = ""
+		}
+	}
 	update["completed"] = req.Completed
+	if req.Completed && !existing.Completed {
+		update["completedAt"] = now
+	} else if !req.Completed {
+		unset["completedAt"] = ""
+	}
+
+	changes := bson.M{"$set": update}
+	if len(unset) > 0 {
+		changes["$unset"] = unset
+	}
 
 	result, err := h.goalCollection.UpdateOne(
 		context.Background(),
-		bson.M{
-			"_id":    goalID,
-			"userId": userID.(primitive.ObjectID),
-		},
-		bson.M{"$set": update},
+		bson.M{"_id": goalID},
+		changes,
 	)
 
 	if err != nil {
@@ -222,15 +379,13 @@ func (h *GoalHandler) UpdateGoal(c *gin.Context) {
 
 	// Get updated goal
 	var goal models.Goal
-	err = h.goalCollection.FindOne(context.Background(), bson.M{
-		"_id":    goalID,
-		"userId": userID.(primitive.ObjectID),
-	}).Decode(&goal)
+	err = h.goalCollection.FindOne(context.Background(), bson.M{"_id": goalID}).Decode(&goal)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get updated goal"})
 		return
 	}
+	h.goalChanged(c, models.ActivityGoalUpdated, nil, existing, &goal)
 
 	c.JSON(http.StatusOK, goal)
 }
@@ -243,27 +398,100 @@ func (h *GoalHandler) DeleteGoal(c *gin.Context) {
 		return
 	}
 
-	// Get user ID from context
-	userID, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
 		return
 	}
 
-	result, err := h.goalCollection.DeleteOne(context.Background(), bson.M{
-		"_id":    goalID,
-		"userId": userID.(primitive.ObjectID),
-	})
+	// Only the owner (or a workspace admin) may delete a goal
+	if _, _, err := findGoalWithRole(context.Background(), h.goalCollection, goalID, scope, models.RoleOwner); err != nil {
+		respondGoalAccessError(c, err)
+		return
+	}
 
+	// Sub-goals have to be moved or deleted first so none are orphaned
+	children, err := h.goalCollection.CountDocuments(context.Background(), bson.M{"parentId": goalID})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete goal"})
 		return
 	}
+	if children > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Goal has sub-goals; move or delete them first"})
+		return
+	}
 
-	if result.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+	var deleted models.Goal
+	err = h.goalCollection.FindOneAndDelete(context.Background(), bson.M{"_id": goalID}).Decode(&deleted)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete goal"})
+		}
 		return
 	}
+	h.goalChanged(c, models.ActivityGoalDeleted, nil, &deleted, nil)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Goal deleted successfully"})
 }
+
+// goalChanged runs the bookkeeping that follows every goal mutation. before
+// is nil for newly created goals and after is nil for deleted ones;
+// subTaskID names the subtask for subtask actions.
+func (h *GoalHandler) goalChanged(c *gin.Context, action string, subTaskID *primitive.ObjectID, before, after *models.Goal) {
+	goal := after
+	if goal == nil {
+		goal = before
+	}
+	h.statsCache.Invalidate(goal.UserID)
+
+	if err := h.history.Record(context.Background(), before, after); err != nil {
+		log.Printf("Failed to record progress history: %v", err)
+	}
+
+	entry := newActivity(c, action)
+	entry.GoalID = &goal.ID
+	entry.SubTaskID = subTaskID
+	entry.WorkspaceID = goal.WorkspaceID
+	changes, err := audit.Diff(before, after)
+	if err != nil {
+		log.Printf("Failed to diff goal %s: %v", goal.ID.Hex(), err)
+	}
+	entry.Changes = changes
+	if err := h.activity.Record(context.Background(), entry); err != nil {
+		log.Printf("Failed to record activity: %v", err)
+	}
+
+	switch {
+	case after == nil:
+		err = h.revisions.DeleteGoal(context.Background(), goal.ID, time.Now())
+	case before == nil:
+		// A goal brought back by undo or a restore keeps its earlier history
+		if err = h.revisions.RestoreGoal(context.Background(), goal.ID); err == nil {
+			err = h.revisions.Record(context.Background(), after, action, entry.ActorID)
+		}
+	default:
+		err = h.revisions.Record(context.Background(), after, action, entry.ActorID)
+	}
+	if err != nil {
+		log.Printf("Failed to record revision of goal %s: %v", goal.ID.Hex(), err)
+	}
+
+	// Undo and redo manage their own stacks, and sharing can't be undone
+	switch action {
+	case models.ActivityGoalUndone, models.ActivityGoalRedone,
+		models.ActivityMemberAdded, models.ActivityMemberUpdated, models.ActivityMemberRemoved:
+	default:
+		if scope, ok := requestScope(c); ok {
+			h.undo.Record(undoKey(scope), undo.Change{
+				Action: action,
+				GoalID: goal.ID,
+				From:   before,
+				To:     after,
+				At:     time.Now(),
+			})
+		}
+	}
+}
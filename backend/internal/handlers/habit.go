@@ -0,0 +1,429 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"task-management/internal/models"
+)
+
+// defaultHeatmapDays is how many days the habit heatmap covers by default
+const defaultHeatmapDays = 365
+
+// HabitRequest represents a habit's frequency settings
+type HabitRequest struct {
+	Frequency       string `json:"frequency" validate:"required,oneof=daily weekly weekdays"`
+	TimesPerWeek    int    `json:"timesPerWeek,omitempty"`
+	Weekdays        []int  `json:"weekdays,omitempty"`
+	FreezesPerMonth int    `json:"freezesPerMonth,omitempty"`
+}
+
+// HabitCheckInRequest represents a habit check-in. date defaults to today
+// in the user's time zone.
+type HabitCheckInRequest struct {
+	Date string `json:"date,omitempty"`
+	Note string `json:"note,omitempty" validate:"max=2000"`
+}
+
+// HabitStats summarizes a habit's streaks and completion
+type HabitStats struct {
+	Frequency     string  `json:"frequency"`
+	Unit          string  `json:"unit"`
+	CurrentStreak int     `json:"currentStreak"`
+	LongestStreak int     `json:"longestStreak"`
+	Completion    float64 `json:"completion"`
+	Done          int     `json:"done"`
+	Missed        int     `json:"missed"`
+	Frozen        int     `json:"frozen"`
+	Today         string  `json:"today"`
+	From          string  `json:"from"`
+	To            string  `json:"to"`
+}
+
+// HeatmapDay is one calendar day of a habit's heatmap. Status is the status
+// of the period the day belongs to, or empty when the habit wasn't due.
+type HeatmapDay struct {
+	Date      string `json:"date"`
+	CheckedIn bool   `json:"checkedIn"`
+	Status    string `json:"status,omitempty"`
+}
+
+// habitClock holds the calendar days a habit is evaluated over, as
+// midnights in the user's time zone
+type habitClock struct {
+	loc   *time.Location
+	today time.Time
+	start time.Time
+	end   time.Time
+}
+
+// newHabit builds a validated habit from a request
+func newHabit(req *HabitRequest) (*models.Habit, error) {
+	habit := &models.Habit{
+		Frequency:       req.Frequency,
+		TimesPerWeek:    req.TimesPerWeek,
+		Weekdays:        req.Weekdays,
+		FreezesPerMonth: req.FreezesPerMonth,
+		CheckIns:        []models.HabitCheckIn{},
+	}
+	if err := habit.Validate(); err != nil {
+		return nil, err
+	}
+	return habit, nil
+}
+
+// UpdateHabit handles changing a habit's frequency settings. Goals without
+// subtasks or key results can be turned into habits this way; check-ins
+// are kept.
+func (h *GoalHandler) UpdateHabit(c *gin.Context) {
+	var req HabitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	habit, err := newHabit(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, scope, ok := h.loadGoal(c, models.RoleEditor)
+	if !ok {
+		return
+	}
+	before := goal.Clone()
+
+	if goal.Type != models.GoalTypeHabit && (len(goal.SubTasks) > 0 || len(goal.KeyResults) > 0) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Goals with subtasks or key results can't become habits"})
+		return
+	}
+	if goal.Habit != nil {
+		habit.CheckIns = goal.Habit.CheckIns
+	}
+	goal.Type = models.GoalTypeHabit
+	goal.Habit = habit
+
+	clock, ok := h.habitClock(c, scope, goal)
+	if !ok {
+		return
+	}
+	goal.Progress = models.HabitCompletion(goal.Habit.Periods(clock.start, clock.end, clock.today))
+
+	h.saveGoal(c, models.ActivityHabitUpdated, nil, before, goal, http.StatusOK)
+}
+
+// CheckInHabit handles recording that a habit was done on a day. Checking
+// in again on the same day replaces the note.
+func (h *GoalHandler) CheckInHabit(c *gin.Context) {
+	var req HabitCheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, scope, ok := h.loadHabit(c, models.RoleEditor)
+	if !ok {
+		return
+	}
+	before := goal.Clone()
+
+	clock, ok := h.habitClock(c, scope, goal)
+	if !ok {
+		return
+	}
+	day := clock.today
+	if req.Date != "" {
+		parsed, err := time.ParseInLocation(models.HabitDateLayout, req.Date, clock.loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date: expected YYYY-MM-DD"})
+			return
+		}
+		day = parsed
+	}
+	if day.After(clock.today) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Can't check in on a future day"})
+		return
+	}
+	if day.Before(clock.start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Can't check in before the habit starts"})
+		return
+	}
+	if day.After(clock.end) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Can't check in after the habit ends"})
+		return
+	}
+
+	date := day.Format(models.HabitDateLayout)
+	status := http.StatusOK
+	if checkIn := goal.Habit.FindCheckIn(date); checkIn != nil {
+		checkIn.Note = req.Note
+	} else {
+		goal.Habit.CheckIns = append(goal.Habit.CheckIns, models.HabitCheckIn{
+			Date:      date,
+			Note:      req.Note,
+			UserID:    scope.UserID,
+			CreatedAt: time.Now(),
+		})
+		sortHabitCheckIns(goal.Habit.CheckIns)
+		status = http.StatusCreated
+	}
+	goal.Progress = models.HabitCompletion(goal.Habit.Periods(clock.start, clock.end, clock.today))
+
+	h.saveGoal(c, models.ActivityHabitCheckedIn, nil, before, goal, status)
+}
+
+// UncheckHabit handles removing a habit's check-in on the day given by the
+// :date route parameter
+func (h *GoalHandler) UncheckHabit(c *gin.Context) {
+	date := c.Param("date")
+	if _, err := time.Parse(models.HabitDateLayout, date); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date: expected YYYY-MM-DD"})
+		return
+	}
+
+	goal, scope, ok := h.loadHabit(c, models.RoleEditor)
+	if !ok {
+		return
+	}
+	before := goal.Clone()
+
+	remaining := make([]models.HabitCheckIn, 0, len(goal.Habit.CheckIns))
+	for _, checkIn := range goal.Habit.CheckIns {
+		if checkIn.Date != date {
+			remaining = append(remaining, checkIn)
+		}
+	}
+	if len(remaining) == len(goal.Habit.CheckIns) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Check-in not found"})
+		return
+	}
+	goal.Habit.CheckIns = remaining
+
+	clock, ok := h.habitClock(c, scope, goal)
+	if !ok {
+		return
+	}
+	goal.Progress = models.HabitCompletion(goal.Habit.Periods(clock.start, clock.end, clock.today))
+
+	h.saveGoal(c, models.ActivityHabitUnchecked, nil, before, goal, http.StatusOK)
+}
+
+// GetHabitStats handles computing a habit's streaks and its completion
+// between the optional from and to days
+func (h *GoalHandler) GetHabitStats(c *gin.Context) {
+	goal, scope, ok := h.loadHabit(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	clock, ok := h.habitClock(c, scope, goal)
+	if !ok {
+		return
+	}
+	from, to, ok := habitRange(c, clock, clock.start)
+	if !ok {
+		return
+	}
+
+	// Streaks always run over the whole history so freezes are counted
+	// the same way whatever the range
+	periods := goal.Habit.Periods(clock.start, clock.end, clock.today)
+	stats := HabitStats{
+		Frequency: goal.Habit.Frequency,
+		Unit:      goal.Habit.StreakUnit(),
+		From:      from.Format(models.HabitDateLayout),
+		To:        to.Format(models.HabitDateLayout),
+	}
+	stats.CurrentStreak, stats.LongestStreak = models.HabitStreaks(periods)
+	if len(periods) > 0 && periods[len(periods)-1].End >= clock.today.Format(models.HabitDateLayout) {
+		stats.Today = periods[len(periods)-1].Status
+	}
+
+	window := periodsBetween(periods, stats.From, stats.To)
+	for _, period := range window {
+		switch period.Status {
+		case models.HabitDone:
+			stats.Done++
+		case models.HabitMissed:
+			stats.Missed++
+		case models.HabitFrozen:
+			stats.Frozen++
+		}
+	}
+	stats.Completion = models.HabitCompletion(window)
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetHabitHeatmap handles listing each day between from and to, by default
+// the last year, with whether the habit was checked in and how its period
+// went
+func (h *GoalHandler) GetHabitHeatmap(c *gin.Context) {
+	goal, scope, ok := h.loadHabit(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	clock, ok := h.habitClock(c, scope, goal)
+	if !ok {
+		return
+	}
+	from, to, ok := habitRange(c, clock, clock.today.AddDate(0, 0, -(defaultHeatmapDays-1)))
+	if !ok {
+		return
+	}
+
+	checkedIn := make(map[string]bool, len(goal.Habit.CheckIns))
+	for _, checkIn := range goal.Habit.CheckIns {
+		checkedIn[checkIn.Date] = true
+	}
+	periods := goal.Habit.Periods(clock.start, clock.end, clock.today)
+
+	days := []HeatmapDay{}
+	next := 0
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(models.HabitDateLayout)
+		entry := HeatmapDay{Date: date, CheckedIn: checkedIn[date]}
+		for next < len(periods) && periods[next].End < date {
+			next++
+		}
+		if next < len(periods) && periods[next].Start <= date {
+			entry.Status = periods[next].Status
+		}
+		days = append(days, entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"frequency": goal.Habit.Frequency, "days": days})
+}
+
+// loadHabit loads the goal named by the :id route parameter and checks
+// that it is a habit
+func (h *GoalHandler) loadHabit(c *gin.Context, minRole string) (*models.Goal, goalScope, bool) {
+	goal, scope, ok := h.loadGoal(c, minRole)
+	if !ok {
+		return nil, goalScope{}, false
+	}
+	if goal.Type != models.GoalTypeHabit || goal.Habit == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Goal is not a habit"})
+		return nil, goalScope{}, false
+	}
+	return goal, scope, true
+}
+
+// habitClock works out the days a habit is evaluated over in the user's
+// time zone: from its start date up to today, or its end date if that's
+// earlier
+func (h *GoalHandler) habitClock(c *gin.Context, scope goalScope, goal *models.Goal) (habitClock, bool) {
+	loc, err := resolveLocation(c, h.userCollection, scope.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return habitClock{}, false
+	}
+
+	return newHabitClock(goal, loc, time.Now()), true
+}
+
+// newHabitClock returns the days a habit runs over at now in loc
+func newHabitClock(goal *models.Goal, loc *time.Location, now time.Time) habitClock {
+	clock := habitClock{loc: loc, today: localDay(now, loc)}
+	started := goal.StartDate
+	if started.IsZero() {
+		started = goal.CreatedAt
+	}
+	clock.start = localDay(started, loc)
+	clock.end = clock.today
+	if goal.EndDate != nil && localDay(*goal.EndDate, loc).Before(clock.end) {
+		clock.end = localDay(*goal.EndDate, loc)
+	}
+	return clock
+}
+
+// refreshHabitProgress recomputes the progress of the habit goals among
+// goals for the current day. The stored progress only changes with
+// check-ins, so it falls behind as days are missed. It writes the error
+// response and returns false when the user's time zone can't be resolved.
+func (h *GoalHandler) refreshHabitProgress(c *gin.Context, scope goalScope, goals ...*models.Goal) bool {
+	var loc *time.Location
+	now := time.Now()
+	for _, goal := range goals {
+		if goal.Type != models.GoalTypeHabit || goal.Habit == nil {
+			continue
+		}
+		if loc == nil {
+			var err error
+			if loc, err = resolveLocation(c, h.userCollection, scope.UserID); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return false
+			}
+		}
+		clock := newHabitClock(goal, loc, now)
+		goal.Progress = models.HabitCompletion(goal.Habit.Periods(clock.start, clock.end, clock.today))
+	}
+	return true
+}
+
+// habitRange reads the optional from and to query days. from defaults to
+// fallback and to to the habit's last day.
+func habitRange(c *gin.Context, clock habitClock, fallback time.Time) (time.Time, time.Time, bool) {
+	from, to := fallback, clock.end
+	for _, param := range []struct {
+		name string
+		day  *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseInLocation(models.HabitDateLayout, value, clock.loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param.name + " date: expected YYYY-MM-DD"})
+			return time.Time{}, time.Time{}, false
+		}
+		*param.day = parsed
+	}
+	if from.After(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+// periodsBetween returns the periods that start between two days inclusive
+func periodsBetween(periods []models.HabitPeriod, from, to string) []models.HabitPeriod {
+	var window []models.HabitPeriod
+	for _, period := range periods {
+		if period.Start >= from && period.Start <= to {
+			window = append(window, period)
+		}
+	}
+	return window
+}
+
+// sortHabitCheckIns keeps check-ins in date order
+func sortHabitCheckIns(checkIns []models.HabitCheckIn) {
+	sort.Slice(checkIns, func(i, j int) bool {
+		return checkIns[i].Date < checkIns[j].Date
+	})
+}
+
+// localDay returns midnight of t's calendar day in loc
+func localDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"task-management/internal/models"
+)
+
+func TestNewHabitClock(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data unavailable")
+	}
+	utc := func(value string) time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return parsed
+	}
+	ptr := func(value time.Time) *time.Time { return &value }
+
+	tests := []struct {
+		name              string
+		goal              models.Goal
+		loc               *time.Location
+		now               string
+		start, end, today string
+	}{
+		{
+			name:  "runs up to today",
+			goal:  models.Goal{StartDate: utc("2026-03-01T08:00:00Z")},
+			loc:   time.UTC,
+			now:   "2026-03-10T15:00:00Z",
+			start: "2026-03-01", end: "2026-03-10", today: "2026-03-10",
+		},
+		{
+			name:  "stops at an earlier end date",
+			goal:  models.Goal{StartDate: utc("2026-03-01T08:00:00Z"), EndDate: ptr(utc("2026-03-05T12:00:00Z"))},
+			loc:   time.UTC,
+			now:   "2026-03-10T15:00:00Z",
+			start: "2026-03-01", end: "2026-03-05", today: "2026-03-10",
+		},
+		{
+			name:  "later end date",
+			goal:  models.Goal{StartDate: utc("2026-03-01T08:00:00Z"), EndDate: ptr(utc("2026-04-01T00:00:00Z"))},
+			loc:   time.UTC,
+			now:   "2026-03-10T15:00:00Z",
+			start: "2026-03-01", end: "2026-03-10", today: "2026-03-10",
+		},
+		{
+			name:  "falls back to the creation time",
+			goal:  models.Goal{CreatedAt: utc("2026-03-03T10:00:00Z")},
+			loc:   time.UTC,
+			now:   "2026-03-10T15:00:00Z",
+			start: "2026-03-03", end: "2026-03-10", today: "2026-03-10",
+		},
+		{
+			name:  "days follow the user's time zone",
+			goal:  models.Goal{StartDate: utc("2026-02-28T23:30:00Z"), EndDate: ptr(utc("2026-03-04T23:30:00Z"))},
+			loc:   berlin,
+			now:   "2026-03-09T23:30:00Z",
+			start: "2026-03-01", end: "2026-03-05", today: "2026-03-10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newHabitClock(&tt.goal, tt.loc, utc(tt.now))
+			for _, day := range []struct {
+				name string
+				got  time.Time
+				want string
+			}{{"start", clock.start, tt.start}, {"end", clock.end, tt.end}, {"today", clock.today, tt.today}} {
+				if got := day.got.Format(models.HabitDateLayout); got != day.want {
+					t.Errorf("%s = %s, want %s", day.name, got, day.want)
+				}
+				if day.got.Location() != tt.loc || day.got.Hour() != 0 {
+					t.Errorf("%s = %v, want a midnight in %v", day.name, day.got, tt.loc)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/models"
+)
+
+// errGoalCycle is returned when a goal would become its own ancestor
+var errGoalCycle = errors.New("goal would become its own ancestor")
+
+// maxGoalDepth bounds ancestor walks so corrupt data can't loop forever
+const maxGoalDepth = 1000
+
+// MoveGoalRequest represents the move goal request. An empty or missing
+// parentId makes the goal a top-level goal.
+type MoveGoalRequest struct {
+	ParentID string `json:"parentId"`
+}
+
+// GoalNode is a goal in a goal tree with its sub-goals and the progress
+// rolled up from them
+type GoalNode struct {
+	GoalWithRole
+	RolledUpProgress float64     `json:"rolledUpProgress"`
+	Children         []*GoalNode `json:"children"`
+}
+
+// GetGoalTree handles listing the goals the user can access as a forest of
+// top-level goals and their sub-goals
+func (h *GoalHandler) GetGoalTree(c *gin.Context) {
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	cursor, err := h.goalCollection.Find(context.Background(),
+		scope.accessibleFilter(),
+		options.Find().SetSort(bson.M{"createdAt": 1}),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list goals"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	var goals []models.Goal
+	if err := cursor.All(context.Background(), &goals); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode goals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, buildGoalTree(goals, scope))
+}
+
+// GetGoalSubtree handles getting a goal together with all of its sub-goals
+// the user can access
+func (h *GoalHandler) GetGoalSubtree(c *gin.Context) {
+	goal, scope, ok := h.loadGoal(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	descendants, err := h.findDescendants(context.Background(), goal.ID, scope)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sub-goals"})
+		return
+	}
+
+	// The goal itself is the only root, even if it has a parent
+	root := *goal
+	root.ParentID = nil
+	forest := buildGoalTree(append([]models.Goal{root}, descendants...), scope)
+	for _, node := range forest {
+		if node.ID == goal.ID {
+			node.ParentID = goal.ParentID
+			c.JSON(http.StatusOK, node)
+			return
+		}
+	}
+
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build goal tree"})
+}
+
+// MoveGoal handles moving a goal under another parent goal, or to the top
+// level. The caller needs to be able to edit both the goal and its new
+// parent, and a goal can't be moved below itself.
+func (h *GoalHandler) MoveGoal(c *gin.Context) {
+	var req MoveGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var parentID *primitive.ObjectID
+	if req.ParentID != "" {
+		id, err := primitive.ObjectIDFromHex(req.ParentID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parent goal ID"})
+			return
+		}
+		parentID = &id
+	}
+
+	goal, scope, ok := h.loadGoal(c, models.RoleEditor)
+	if !ok {
+		return
+	}
+	before := goal.Clone()
+
+	if parentID != nil {
+		if err := h.checkParent(context.Background(), scope, goal.ID, *parentID); err != nil {
+			respondParentError(c, err)
+			return
+		}
+	}
+
+	goal.ParentID = parentID
+	h.saveGoal(c, models.ActivityGoalMoved, nil, before, goal, http.StatusOK)
+}
+
+// checkParent verifies that parentID can become the parent of goalID: the
+// user must be able to edit it in the active scope, and goalID must not be
+// parentID or one of its ancestors. goalID is zero for new goals.
+func (h *GoalHandler) checkParent(ctx context.Context, scope goalScope, goalID, parentID primitive.ObjectID) error {
+	if _, _, err := findGoalWithRole(ctx, h.goalCollection, parentID, scope, models.RoleEditor); err != nil {
+		return err
+	}
+	if goalID.IsZero() {
+		return nil
+	}
+
+	return h.checkNoCycle(ctx, goalID, parentID)
+}
+
+// checkNoCycle walks up from parentID and fails with errGoalCycle if goalID
+// is among its ancestors, or is parentID itself
+func (h *GoalHandler) checkNoCycle(ctx context.Context, goalID, parentID primitive.ObjectID) error {
+	current := parentID
+	for depth := 0; depth < maxGoalDepth; depth++ {
+		if current == goalID {
+			return errGoalCycle
+		}
+
+		var ancestor models.Goal
+		err := h.goalCollection.FindOne(ctx,
+			bson.M{"_id": current},
+			options.FindOne().SetProjection(bson.M{"parentId": 1}),
+		).Decode(&ancestor)
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if ancestor.ParentID == nil {
+			return nil
+		}
+		current = *ancestor.ParentID
+	}
+
+	return errGoalCycle
+}
+
+// findDescendants returns every goal below goalID that the user can access.
+// Sub-goals the user can't see hide their own sub-goals as well.
+func (h *GoalHandler) findDescendants(ctx context.Context, goalID primitive.ObjectID, scope goalScope) ([]models.Goal, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"_id": goalID}}},
+		{{Key: "$graphLookup", Value: bson.M{
+			"from":                    h.goalCollection.Name(),
+			"startWith":               "$_id",
+			"connectFromField":        "_id",
+			"connectToField":          "parentId",
+			"as":                      "descendants",
+			"restrictSearchWithMatch": scope.accessibleFilter(),
+		}}},
+		{{Key: "$project", Value: bson.M{"descendants": 1}}},
+	}
+
+	cursor, err := h.goalCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Descendants []models.Goal `bson:"descendants"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	return results[0].Descendants, nil
+}
+
+// buildGoalTree arranges goals under their parents and rolls progress up
+// from the leaves. Goals whose parent isn't among goals become roots.
+func buildGoalTree(goals []models.Goal, scope goalScope) []*GoalNode {
+	nodes := make(map[primitive.ObjectID]*GoalNode, len(goals))
+	for i := range goals {
+		nodes[goals[i].ID] = &GoalNode{
+			GoalWithRole: GoalWithRole{Goal: goals[i], Role: scope.roleOn(&goals[i])},
+			Children:     []*GoalNode{},
+		}
+	}
+
+	roots := []*GoalNode{}
+	for i := range goals {
+		node := nodes[goals[i].ID]
+		if goals[i].ParentID != nil {
+			if parent, ok := nodes[*goals[i].ParentID]; ok && parent != node {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	for _, root := range roots {
+		rollUp(root)
+	}
+	return roots
+}
+
+// rollUp fills in the rolled-up progress of a node and its children
+func rollUp(node *GoalNode) float64 {
+	children := make([]float64, 0, len(node.Children))
+	for _, child := range node.Children {
+		children = append(children, rollUp(child))
+	}
+	node.RolledUpProgress = node.Goal.RollUpProgress(children)
+	return node.RolledUpProgress
+}
+
+// respondParentError writes the response for a checkParent error
+func respondParentError(c *gin.Context, err error) {
+	switch err {
+	case errGoalNotFound:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parent goal not found"})
+	case errForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to add sub-goals to the parent goal"})
+	case errGoalCycle:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A goal can't be moved below itself"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check parent goal"})
+	}
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"task-management/internal/history"
+	"task-management/internal/models"
+)
+
+// Burndown window limits, in days
+const (
+	defaultBurndownDays = 30
+	maxBurndownDays     = 366
+	velocityWindowDays  = 14
+)
+
+// HistoryHandler handles progress history routes
+type HistoryHandler struct {
+	goalCollection *mongo.Collection
+	userCollection *mongo.Collection
+	recorder       *history.Recorder
+}
+
+// NewHistoryHandler creates a new history handler
+func NewHistoryHandler(goalCollection, userCollection *mongo.Collection, recorder *history.Recorder) *HistoryHandler {
+	return &HistoryHandler{
+		goalCollection: goalCollection,
+		userCollection: userCollection,
+		recorder:       recorder,
+	}
+}
+
+// BurndownResponse represents burndown and burnup series with a projection
+type BurndownResponse struct {
+	GoalID     *primitive.ObjectID `json:"goalId,omitempty"`
+	Timezone   string              `json:"timezone"`
+	Series     []history.Point     `json:"series"`
+	Projection history.Projection  `json:"projection"`
+}
+
+// GetGoalBurndown returns the burndown series for a single goal
+func (h *HistoryHandler) GetGoalBurndown(c *gin.Context) {
+	goalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal ID"})
+		return
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	goal, _, err := findGoalWithRole(context.Background(), h.goalCollection, goalID, scope, models.RoleViewer)
+	if err != nil {
+		respondGoalAccessError(c, err)
+		return
+	}
+
+	h.respondBurndown(c, scope.UserID, bson.M{"goalId": goalID}, []models.Goal{*goal}, goal.EndDate, &goalID)
+}
+
+// GetBurndown returns the burndown series across all of the user's goals
+func (h *HistoryHandler) GetBurndown(c *gin.Context) {
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	cursor, err := h.goalCollection.Find(context.Background(), scope.ownedFilter())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list goals"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	var goals []models.Goal
+	if err := cursor.All(context.Background(), &goals); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode goals"})
+		return
+	}
+
+	// Compare against the latest end date among open goals
+	var endDate *time.Time
+	for _, goal := range goals {
+		if !goal.Completed && goal.EndDate != nil && (endDate == nil || goal.EndDate.After(*endDate)) {
+			endDate = goal.EndDate
+		}
+	}
+
+	// History entries aren't tenant-tagged, so select them by goal
+	goalIDs := make([]primitive.ObjectID, 0, len(goals))
+	for _, goal := range goals {
+		goalIDs = append(goalIDs, goal.ID)
+	}
+
+	h.respondBurndown(c, scope.UserID, bson.M{"goalId": bson.M{"$in": goalIDs}}, goals, endDate, nil)
+}
+
+func (h *HistoryHandler) respondBurndown(c *gin.Context, userID primitive.ObjectID, filter bson.M, goals []models.Goal, endDate *time.Time, goalID *primitive.ObjectID) {
+	days := defaultBurndownDays
+	if value := c.Query("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxBurndownDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 366"})
+			return
+		}
+		days = n
+	}
+
+	loc, err := resolveLocation(c, h.userCollection, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	from := now.AddDate(0, 0, -(days - 1))
+
+	entries, err := h.recorder.Entries(context.Background(), filter, from)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load progress history"})
+		return
+	}
+
+	// The current state is always the final data point, which also covers
+	// goals that predate history recording
+	for i := range goals {
+		entries = append(entries, models.NewProgressEntry(&goals[i], models.ProgressSourceChange, now))
+	}
+
+	series := history.BuildSeries(entries, from, now, loc)
+
+	c.JSON(http.StatusOK, BurndownResponse{
+		GoalID:     goalID,
+		Timezone:   loc.String(),
+		Series:     series,
+		Projection: history.Project(series, velocityWindowDays, endDate, now, loc),
+	})
+}
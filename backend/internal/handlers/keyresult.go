@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"task-management/internal/models"
+)
+
+// AddKeyResultRequest represents the add key result request. currentValue
+// defaults to startValue and weight to 1.
+type AddKeyResultRequest struct {
+	Title        string   `json:"title" validate:"required,max=200"`
+	StartValue   float64  `json:"startValue"`
+	TargetValue  float64  `json:"targetValue"`
+	CurrentValue *float64 `json:"currentValue,omitempty"`
+	Unit         string   `json:"unit,omitempty" validate:"max=30"`
+	Direction    string   `json:"direction" validate:"required,oneof=increase decrease"`
+	Weight       *float64 `json:"weight,omitempty"`
+}
+
+// UpdateKeyResultRequest represents the update key result request. The
+// current value is changed through check-ins.
+type UpdateKeyResultRequest struct {
+	Title       string   `json:"title,omitempty" validate:"max=200"`
+	StartValue  *float64 `json:"startValue,omitempty"`
+	TargetValue *float64 `json:"targetValue,omitempty"`
+	Unit        *string  `json:"unit,omitempty" validate:"omitempty,max=30"`
+	Direction   string   `json:"direction,omitempty" validate:"omitempty,oneof=increase decrease"`
+	Weight      *float64 `json:"weight,omitempty"`
+}
+
+// CheckInRequest represents a key result check-in
+type CheckInRequest struct {
+	Value *float64 `json:"value" validate:"required"`
+	Note  string   `json:"note,omitempty" validate:"max=2000"`
+}
+
+// AddKeyResult handles adding a key result to a goal
+func (h *GoalHandler) AddKeyResult(c *gin.Context) {
+	var req AddKeyResultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	keyResult := models.KeyResult{
+		ID:           primitive.NewObjectID(),
+		Title:        req.Title,
+		StartValue:   req.StartValue,
+		TargetValue:  req.TargetValue,
+		CurrentValue: req.StartValue,
+		Unit:         req.Unit,
+		Direction:    req.Direction,
+		Weight:       1,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if req.CurrentValue != nil {
+		keyResult.CurrentValue = *req.CurrentValue
+	}
+	if req.Weight != nil {
+		keyResult.Weight = *req.Weight
+	}
+	if err := keyResult.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, _, ok := h.loadGoal(c, models.RoleEditor)
+	if !ok {
+		return
+	}
+	before := goal.Clone()
+
+	if goal.Type == models.GoalTypeHabit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Habit goals are tracked by check-ins"})
+		return
+	}
+
+	goal.KeyResults = append(goal.KeyResults, keyResult)
+	goal.UpdateCompletion(now)
+
+	h.saveGoal(c, models.ActivityKeyResultCreated, nil, before, goal, http.StatusCreated)
+}
+
+// UpdateKeyResult handles updating a key result's definition
+func (h *GoalHandler) UpdateKeyResult(c *gin.Context) {
+	keyResultID, err := primitive.ObjectIDFromHex(c.Param("keyResultId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid key result ID"})
+		return
+	}
+
+	var req UpdateKeyResultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, _, ok := h.loadGoal(c, models.RoleEditor)
+	if !ok {
+		return
+	}
+	before := goal.Clone()
+
+	keyResult := goal.FindKeyResult(keyResultID)
+	if keyResult == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Key result not found"})
+		return
+	}
+
+	if req.Title != "" {
+		keyResult.Title = req.Title
+	}
+	if req.StartValue != nil {
+		keyResult.StartValue = *req.StartValue
+	}
+	if req.TargetValue != nil {
+		keyResult.TargetValue = *req.TargetValue
+	}
+	if req.Unit != nil {
+		keyResult.Unit = *req.Unit
+	}
+	if req.Direction != "" {
+		keyResult.Direction = req.Direction
+	}
+	if req.Weight != nil {
+		keyResult.Weight = *req.Weight
+	}
+	if err := keyResult.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	keyResult.UpdatedAt = now
+	goal.UpdateCompletion(now)
+
+	h.saveGoal(c, models.ActivityKeyResultUpdated, nil, before, goal, http.StatusOK)
+}
+
+// DeleteKeyResult handles removing a key result and its check-ins from a goal
+func (h *GoalHandler) DeleteKeyResult(c *gin.Context) {
+	keyResultID, err := primitive.ObjectIDFromHex(c.Param("keyResultId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid key result ID"})
+		return
+	}
+
+	goal, _, ok := h.loadGoal(c, models.RoleEditor)
+	if !ok {
+		return
+	}
+	before := goal.Clone()
+
+	remaining := make([]models.KeyResult, 0, len(goal.KeyResults))
+	for _, keyResult := range goal.KeyResults {
+		if keyResult.ID != keyResultID {
+			remaining = append(remaining, keyResult)
+		}
+	}
+	if len(remaining) == len(goal.KeyResults) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Key result not found"})
+		return
+	}
+
+	goal.KeyResults = remaining
+	goal.UpdateCompletion(time.Now())
+
+	h.saveGoal(c, models.ActivityKeyResultDeleted, nil, before, goal, http.StatusOK)
+}
+
+// CheckIn handles logging a new measurement of a key result, which becomes
+// its current value
+func (h *GoalHandler) CheckIn(c *gin.Context) {
+	keyResultID, err := primitive.ObjectIDFromHex(c.Param("keyResultId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid key result ID"})
+		return
+	}
+
+	var req CheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, scope, ok := h.loadGoal(c, models.RoleEditor)
+	if !ok {
+		return
+	}
+	before := goal.Clone()
+
+	keyResult := goal.FindKeyResult(keyResultID)
+	if keyResult == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Key result not found"})
+		return
+	}
+
+	now := time.Now()
+	checkIn := models.CheckIn{
+		ID:        primitive.NewObjectID(),
+		Value:     *req.Value,
+		Note:      req.Note,
+		UserID:    scope.UserID,
+		CreatedAt: now,
+	}
+	// Copy before appending so the snapshot in before keeps its check-ins
+	keyResult.CheckIns = append(append([]models.CheckIn{}, keyResult.CheckIns...), checkIn)
+	keyResult.CurrentValue = checkIn.Value
+	keyResult.UpdatedAt = now
+	goal.UpdateCompletion(now)
+
+	h.saveGoal(c, models.ActivityKeyResultCheckedIn, nil, before, goal, http.StatusCreated)
+}
+
+// ListCheckIns handles listing a key result's check-ins, newest first
+func (h *GoalHandler) ListCheckIns(c *gin.Context) {
+	keyResultID, err := primitive.ObjectIDFromHex(c.Param("keyResultId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid key result ID"})
+		return
+	}
+
+	goal, _, ok := h.loadGoal(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	keyResult := goal.FindKeyResult(keyResultID)
+	if keyResult == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Key result not found"})
+		return
+	}
+
+	checkIns := append([]models.CheckIn{}, keyResult.CheckIns...)
+	sort.SliceStable(checkIns, func(i, j int) bool {
+		return checkIns[i].CreatedAt.After(checkIns[j].CreatedAt)
+	})
+
+	c.JSON(http.StatusOK, checkIns)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/models"
+)
+
+// NotificationHandler handles the caller's notifications
+type NotificationHandler struct {
+	notificationCollection *mongo.Collection
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationCollection *mongo.Collection) *NotificationHandler {
+	return &NotificationHandler{
+		notificationCollection: notificationCollection,
+	}
+}
+
+// ListNotifications handles listing the caller's notifications, newest
+// first. unread=true limits the list to unread ones and before pages back
+// from a notification ID.
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	filter := bson.M{"userId": userID.(primitive.ObjectID)}
+	if c.Query("unread") == "true" {
+		filter["read"] = false
+	}
+	limit, err := pageQuery(c, filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cursor, err := h.notificationCollection.Find(context.Background(), filter,
+		options.Find().SetSort(bson.M{"_id": -1}).SetLimit(limit),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	notifications := []models.Notification{}
+	if err := cursor.All(context.Background(), &notifications); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode notifications"})
+		return
+	}
+
+	unread, err := h.notificationCollection.CountDocuments(context.Background(), bson.M{
+		"userId": userID.(primitive.ObjectID),
+		"read":   false,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notifications": notifications, "unread": unread})
+}
+
+// MarkNotificationRead handles marking one notification as read
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	notificationID, err := primitive.ObjectIDFromHex(c.Param("notificationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	result, err := h.notificationCollection.UpdateOne(context.Background(),
+		bson.M{"_id": notificationID, "userId": userID.(primitive.ObjectID)},
+		bson.M{"$set": bson.M{"read": true, "readAt": time.Now()}},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+// MarkAllNotificationsRead handles marking all of the caller's notifications as read
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	result, err := h.notificationCollection.UpdateMany(context.Background(),
+		bson.M{"userId": userID.(primitive.ObjectID), "read": false},
+		bson.M{"$set": bson.M{"read": true, "readAt": time.Now()}},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notifications marked as read", "updated": result.ModifiedCount})
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Feed page sizes
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// pageQuery reads the limit and before query parameters used by
+// newest-first feeds. before is the ID of the last item of the previous
+// page; ObjectIDs grow over time, so older items have smaller IDs.
+func pageQuery(c *gin.Context, filter bson.M) (int64, error) {
+	limit := defaultPageLimit
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxPageLimit {
+			return 0, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+		}
+		limit = n
+	}
+
+	if value := c.Query("before"); value != "" {
+		before, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			return 0, errors.New("invalid before ID")
+		}
+		filter["_id"] = bson.M{"$lt": before}
+	}
+
+	return int64(limit), nil
+}
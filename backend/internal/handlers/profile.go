@@ -0,0 +1,472 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/audit"
+	"task-management/internal/mail"
+	"task-management/internal/middleware"
+	"task-management/internal/models"
+)
+
+// emailTokenTTL is how long an email verification token can be used
+const emailTokenTTL = 24 * time.Hour
+
+// mailTimeout bounds how long sending a verification email can take
+const mailTimeout = 30 * time.Second
+
+// ProfileHandler handles the current user's profile, password and account
+// deletion
+type ProfileHandler struct {
+	userCollection      *mongo.Collection
+	workspaceCollection *mongo.Collection
+	jwtMiddleware       *middleware.JwtMiddleware
+	activity            *audit.Log
+	mailer              mail.Mailer
+	validator           *validator.Validate
+	deletionGrace       time.Duration
+}
+
+// NewProfileHandler creates a new profile handler. Email verification
+// tokens are sent with mailer, and accounts are deleted graceDays after
+// deletion is requested.
+func NewProfileHandler(userCollection, workspaceCollection *mongo.Collection, jwtMiddleware *middleware.JwtMiddleware, activityLog *audit.Log, mailer mail.Mailer, graceDays int) *ProfileHandler {
+	return &ProfileHandler{
+		userCollection:      userCollection,
+		workspaceCollection: workspaceCollection,
+		jwtMiddleware:       jwtMiddleware,
+		activity:            activityLog,
+		mailer:              mailer,
+		validator:           validator.New(),
+		deletionGrace:       time.Duration(graceDays) * 24 * time.Hour,
+	}
+}
+
+// UpdateProfileRequest represents the update profile request. Omitted
+// fields are left unchanged; a new email only replaces the current one once
+// it is verified.
+type UpdateProfileRequest struct {
+	Username  *string `json:"username,omitempty" validate:"omitnil,min=3,max=30"`
+	Email     *string `json:"email,omitempty" validate:"omitnil,email"`
+	FirstName *string `json:"firstName,omitempty" validate:"omitnil,max=100"`
+	LastName  *string `json:"lastName,omitempty" validate:"omitnil,max=100"`
+	Timezone  *string `json:"timezone,omitempty" validate:"omitnil,timezone"`
+}
+
+// VerifyEmailRequest represents the verify email request
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ChangePasswordRequest represents the change password request
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" validate:"required"`
+	NewPassword     string `json:"newPassword" validate:"required,min=6"`
+}
+
+// DeleteAccountRequest represents the delete account request
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// GetProfile handles getting the current user
+func (h *ProfileHandler) GetProfile(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// UpdateProfile handles updating the current user's names, username, time
+// zone and email. A new email is kept pending and a verification token is
+// issued for it.
+func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	update := bson.M{"updatedAt": now}
+	if req.FirstName != nil {
+		user.FirstName = *req.FirstName
+		update["firstName"] = user.FirstName
+	}
+	if req.LastName != nil {
+		user.LastName = *req.LastName
+		update["lastName"] = user.LastName
+	}
+	if req.Timezone != nil {
+		user.Timezone = *req.Timezone
+		update["timezone"] = user.Timezone
+	}
+
+	if req.Username != nil && *req.Username != user.Username {
+		// Check if username is taken
+		taken, err := h.userCollection.CountDocuments(context.Background(), bson.M{"username": *req.Username, "_id": bson.M{"$ne": user.ID}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check username"})
+			return
+		}
+		if taken > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Username is already taken"})
+			return
+		}
+		user.Username = *req.Username
+		update["username"] = user.Username
+	}
+
+	var emailToken string
+	if req.Email != nil && *req.Email != user.Email {
+		if !h.checkEmailFree(c, user.ID, *req.Email) {
+			return
+		}
+
+		token, hash, err := newEmailToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create verification token"})
+			return
+		}
+		expiresAt := now.Add(emailTokenTTL)
+		user.PendingEmail = *req.Email
+		update["pendingEmail"] = user.PendingEmail
+		update["emailTokenHash"] = hash
+		update["emailTokenExpiresAt"] = expiresAt
+		emailToken = token
+	}
+
+	if _, err := h.userCollection.UpdateOne(context.Background(), bson.M{"_id": user.ID}, bson.M{"$set": update}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		return
+	}
+
+	h.recordAccountEvent(c, models.ActivityProfileUpdated, nil)
+	if emailToken != "" {
+		// The change stays pending, so sending the email again issues a new token
+		ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+		defer cancel()
+		if err := h.mailer.Send(ctx, mail.Message{
+			To:      user.PendingEmail,
+			Subject: "Confirm your new email address",
+			Body:    "Use this token to confirm your new email address within 24 hours: " + emailToken,
+		}); err != nil {
+			log.Printf("Failed to send email verification: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// VerifyEmail handles confirming a pending email change with the token
+// issued for it
+func (h *ProfileHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	if user.PendingEmail == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "There is no email change to verify"})
+		return
+	}
+	if user.EmailTokenExpiresAt == nil || time.Now().After(*user.EmailTokenExpiresAt) || hashEmailToken(req.Token) != user.EmailTokenHash {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+		return
+	}
+
+	// Someone may have registered the address in the meantime
+	if !h.checkEmailFree(c, user.ID, user.PendingEmail) {
+		return
+	}
+
+	previous := user.Email
+	user.Email = user.PendingEmail
+	user.PendingEmail = ""
+	if _, err := h.userCollection.UpdateOne(context.Background(),
+		bson.M{"_id": user.ID},
+		bson.M{
+			"$set":   bson.M{"email": user.Email, "updatedAt": time.Now()},
+			"$unset": bson.M{"pendingEmail": "", "emailTokenHash": "", "emailTokenExpiresAt": ""},
+		},
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update email"})
+		return
+	}
+
+	h.recordAccountEvent(c, models.ActivityEmailChanged, map[string]string{"from": previous, "to": user.Email})
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// ChangePassword handles changing the current user's password. Every token
+// issued before the change stops working, so a new one is returned for
+// this session.
+func (h *ProfileHandler) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	// Compare passwords
+	if err := user.ComparePassword(req.CurrentPassword); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	// Hash password
+	user.Password = req.NewPassword
+	if err := user.HashPassword(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	// Bumping the token version revokes every token issued so far
+	now := time.Now()
+	err := h.userCollection.FindOneAndUpdate(context.Background(),
+		bson.M{"_id": user.ID},
+		bson.M{
+			"$set": bson.M{"password": user.Password, "passwordChangedAt": now, "updatedAt": now},
+			"$inc": bson.M{"tokenVersion": 1},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+
+	// Generate JWT token
+	token, err := h.jwtMiddleware.GenerateToken(user.ID, user.TokenVersion, 24)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	h.recordAccountEvent(c, models.ActivityPasswordChanged, nil)
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Token: token,
+		User:  user.ToResponse(),
+	})
+}
+
+// DeleteAccount handles scheduling the current user's account for deletion
+// after the grace period. Workspace owners have to transfer their
+// workspaces first.
+func (h *ProfileHandler) DeleteAccount(c *gin.Context) {
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	// Compare passwords
+	if err := user.ComparePassword(req.Password); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password is incorrect"})
+		return
+	}
+
+	cursor, err := h.workspaceCollection.Find(context.Background(),
+		bson.M{"ownerId": user.ID},
+		options.Find().SetProjection(bson.M{"name": 1}),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check workspaces"})
+		return
+	}
+	var owned []models.Workspace
+	if err := cursor.All(context.Background(), &owned); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check workspaces"})
+		return
+	}
+	if len(owned) > 0 {
+		names := make([]string, len(owned))
+		for i, workspace := range owned {
+			names[i] = workspace.Name
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": "Transfer the workspaces you own before deleting your account", "workspaces": names})
+		return
+	}
+
+	if user.DeleteAfter == nil {
+		now := time.Now()
+		deleteAfter := now.Add(h.deletionGrace)
+		if _, err := h.userCollection.UpdateOne(context.Background(),
+			bson.M{"_id": user.ID},
+			bson.M{"$set": bson.M{"deletionRequestedAt": now, "deleteAfter": deleteAfter, "updatedAt": now}},
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule account deletion"})
+			return
+		}
+		user.DeletionRequestedAt = &now
+		user.DeleteAfter = &deleteAfter
+
+		h.recordAccountEvent(c, models.ActivityDeletionScheduled, map[string]string{"deleteAfter": deleteAfter.UTC().Format(time.RFC3339)})
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":     "Account scheduled for deletion",
+		"deleteAfter": user.DeleteAfter,
+	})
+}
+
+// CancelAccountDeletion handles cancelling a scheduled account deletion
+// during the grace period
+func (h *ProfileHandler) CancelAccountDeletion(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	if user.DeleteAfter == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Account deletion is not scheduled"})
+		return
+	}
+
+	if _, err := h.userCollection.UpdateOne(context.Background(),
+		bson.M{"_id": user.ID},
+		bson.M{
+			"$set":   bson.M{"updatedAt": time.Now()},
+			"$unset": bson.M{"deletionRequestedAt": "", "deleteAfter": ""},
+		},
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel account deletion"})
+		return
+	}
+	user.DeletionRequestedAt = nil
+	user.DeleteAfter = nil
+
+	h.recordAccountEvent(c, models.ActivityDeletionCancelled, nil)
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// loadUser loads the current user, writing an error response and returning
+// false when it can't
+func (h *ProfileHandler) loadUser(c *gin.Context) (*models.User, bool) {
+	// Get user ID from context
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return nil, false
+	}
+
+	var user models.User
+	if err := h.userCollection.FindOne(context.Background(), bson.M{"_id": userID.(primitive.ObjectID)}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+		}
+		return nil, false
+	}
+
+	return &user, true
+}
+
+// checkEmailFree checks that no other user has email in any case, writing
+// an error response and returning false when one does
+func (h *ProfileHandler) checkEmailFree(c *gin.Context, userID primitive.ObjectID, email string) bool {
+	taken, err := h.userCollection.CountDocuments(context.Background(), bson.M{"email": emailFilter(email), "_id": bson.M{"$ne": userID}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check email"})
+		return false
+	}
+	if taken > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "User with this email already exists"})
+		return false
+	}
+	return true
+}
+
+// recordAccountEvent appends an account event by the current user to the
+// activity log
+func (h *ProfileHandler) recordAccountEvent(c *gin.Context, action string, metadata map[string]string) {
+	entry := newActivity(c, action)
+	entry.Metadata = metadata
+
+	if err := h.activity.Record(context.Background(), entry); err != nil {
+		log.Printf("Failed to record %s activity: %v", action, err)
+	}
+}
+
+// newEmailToken returns a random email verification token and the hash
+// that is stored in its place
+func newEmailToken() (string, string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(buf)
+	return token, hashEmailToken(token), nil
+}
+
+// hashEmailToken hashes an email verification token for storage
+func hashEmailToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
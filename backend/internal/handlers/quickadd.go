@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/models"
+	"task-management/internal/quickadd"
+)
+
+// maxGoalMatches caps how many goals a quick-add @goal reference is
+// compared against
+const maxGoalMatches = 5
+
+// Quick-add kinds
+const (
+	quickAddGoal    = "goal"
+	quickAddSubTask = "subtask"
+)
+
+// QuickAddRequest represents the quick-add request. goalId picks the goal
+// to add a subtask to, taking precedence over an @goal in the text.
+type QuickAddRequest struct {
+	Text    string              `json:"text" validate:"required,max=500"`
+	GoalID  *primitive.ObjectID `json:"goalId,omitempty"`
+	Preview bool                `json:"preview"`
+}
+
+// QuickAddTarget is the goal a quick-add subtask goes to
+type QuickAddTarget struct {
+	ID    primitive.ObjectID `json:"id"`
+	Title string             `json:"title"`
+}
+
+// QuickAddPreview shows how a quick-add line was understood and the request
+// it would be saved as. Problems explain why it can't be saved.
+type QuickAddPreview struct {
+	Parsed   quickadd.Result    `json:"parsed"`
+	Kind     string             `json:"kind"`
+	Target   *QuickAddTarget    `json:"target,omitempty"`
+	Goal     *CreateGoalRequest `json:"goal,omitempty"`
+	SubTask  *AddSubTaskRequest `json:"subTask,omitempty"`
+	Problems []string           `json:"problems,omitempty"`
+}
+
+// QuickAdd handles creating a goal, or a subtask of the @goal it names,
+// from one line of text such as "Finish report by friday 5pm #work !high".
+// Dates are read in the user's time zone. In preview mode nothing is saved
+// and the parse result is returned instead.
+func (h *GoalHandler) QuickAdd(c *gin.Context) {
+	var req QuickAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	loc, err := resolveLocation(c, h.userCollection, scope.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	now := time.Now().In(loc)
+
+	preview := QuickAddPreview{Parsed: quickadd.Parse(req.Text, now), Kind: quickAddGoal}
+	if preview.Parsed.Title == "" {
+		preview.Problems = append(preview.Problems, "The text has no title left once the date, tags, priority and goal are taken out")
+	}
+
+	var target *models.Goal
+	if req.GoalID != nil || preview.Parsed.Goal != "" {
+		preview.Kind = quickAddSubTask
+		target, err = h.findQuickAddTarget(context.Background(), scope, req.GoalID, preview.Parsed.Goal)
+		if err != nil {
+			preview.Problems = append(preview.Problems, err.Error())
+		} else {
+			preview.Target = &QuickAddTarget{ID: target.ID, Title: target.Title}
+		}
+	}
+
+	if preview.Kind == quickAddGoal {
+		preview.Goal = &CreateGoalRequest{
+			Title:     preview.Parsed.Title,
+			Tags:      preview.Parsed.Tags,
+			Priority:  preview.Parsed.Priority,
+			StartDate: now,
+			EndDate:   preview.Parsed.Due,
+		}
+	} else {
+		preview.SubTask = &AddSubTaskRequest{
+			Title:    preview.Parsed.Title,
+			Tags:     preview.Parsed.Tags,
+			Priority: preview.Parsed.Priority,
+			DueDate:  preview.Parsed.Due,
+		}
+	}
+
+	if req.Preview {
+		c.JSON(http.StatusOK, preview)
+		return
+	}
+	if len(preview.Problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": preview.Problems[0], "preview": preview})
+		return
+	}
+
+	if preview.Kind == quickAddSubTask {
+		h.addSubTask(c, scope, target, preview.SubTask)
+		return
+	}
+	goal, ok := h.createGoal(c, scope, preview.Goal)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusCreated, goal)
+}
+
+// findQuickAddTarget finds the goal a quick-add subtask goes to, by ID or by
+// the @goal reference: a goal ID, or a title matched exactly and then by
+// prefix, ignoring case. The caller has to be able to edit it.
+func (h *GoalHandler) findQuickAddTarget(ctx context.Context, scope goalScope, goalID *primitive.ObjectID, ref string) (*models.Goal, error) {
+	if goalID == nil {
+		if id, err := primitive.ObjectIDFromHex(ref); err == nil {
+			goalID = &id
+		}
+	}
+
+	if goalID == nil {
+		for _, pattern := range []string{"^" + regexp.QuoteMeta(ref) + "$", "^" + regexp.QuoteMeta(ref)} {
+			filter := scope.accessibleFilter()
+			filter["title"] = bson.M{"$regex": pattern, "$options": "i"}
+			cursor, err := h.goalCollection.Find(ctx, filter,
+				options.Find().SetProjection(bson.M{"title": 1}).SetLimit(maxGoalMatches),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to look up @%s", ref)
+			}
+			var matches []models.Goal
+			if err := cursor.All(ctx, &matches); err != nil {
+				return nil, fmt.Errorf("Failed to look up @%s", ref)
+			}
+
+			if len(matches) == 1 {
+				goalID = &matches[0].ID
+				break
+			}
+			if len(matches) > 1 {
+				titles := make([]string, len(matches))
+				for i, match := range matches {
+					titles[i] = match.Title
+				}
+				return nil, fmt.Errorf("@%s matches several goals: %s", ref, strings.Join(titles, ", "))
+			}
+		}
+		if goalID == nil {
+			return nil, fmt.Errorf("No goal matches @%s", ref)
+		}
+	}
+
+	goal, _, err := findGoalWithRole(ctx, h.goalCollection, *goalID, scope, models.RoleEditor)
+	switch err {
+	case nil:
+		return goal, nil
+	case errGoalNotFound:
+		return nil, fmt.Errorf("Goal not found")
+	case errForbidden:
+		return nil, fmt.Errorf("You don't have permission to add subtasks to this goal")
+	default:
+		return nil, fmt.Errorf("Failed to get goal")
+	}
+}
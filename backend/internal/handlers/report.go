@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/models"
+	"task-management/internal/report"
+)
+
+// ReportHandler handles goal report routes
+type ReportHandler struct {
+	goalCollection *mongo.Collection
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(goalCollection *mongo.Collection) *ReportHandler {
+	return &ReportHandler{
+		goalCollection: goalCollection,
+	}
+}
+
+// GetReport renders the user's goals as a Markdown or PDF report
+func (h *ReportHandler) GetReport(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", "markdown"))
+	if format != "markdown" && format != "md" && format != "pdf" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be markdown or pdf"})
+		return
+	}
+
+	filters, err := parseReportFilters(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	cursor, err := h.goalCollection.Find(context.Background(),
+		reportQuery(scope, filters, now),
+		options.Find().SetSort(bson.M{"startDate": 1}),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load goals"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	goals := []models.Goal{}
+	if err := cursor.All(context.Background(), &goals); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode goals"})
+		return
+	}
+
+	rep := report.Build("Goal Report", goals, filters, now)
+
+	var buf bytes.Buffer
+	contentType, ext := "text/markdown; charset=utf-8", "md"
+	if format == "pdf" {
+		contentType, ext = "application/pdf", "pdf"
+		err = report.RenderPDF(&buf, rep)
+	} else {
+		err = report.RenderMarkdown(&buf, rep)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render report"})
+		return
+	}
+
+	filename := fmt.Sprintf("goal-report-%s.%s", now.Format("20060102"), ext)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// parseReportFilters reads the range, tag and status query parameters
+func parseReportFilters(c *gin.Context) (report.Filters, error) {
+	filters := report.Filters{
+		Tags:   c.QueryArray("tag"),
+		Status: strings.ToLower(c.DefaultQuery("status", report.StatusAll)),
+	}
+
+	switch filters.Status {
+	case report.StatusAll, report.StatusOpen, report.StatusCompleted, report.StatusOverdue:
+	default:
+		return filters, fmt.Errorf("status must be all, open, completed or overdue")
+	}
+
+	var err error
+	if filters.From, err = parseQueryDate(c.Query("from")); err != nil {
+		return filters, fmt.Errorf("invalid from date: %w", err)
+	}
+	if filters.To, err = parseQueryDate(c.Query("to")); err != nil {
+		return filters, fmt.Errorf("invalid to date: %w", err)
+	}
+	if filters.From != nil && filters.To != nil && filters.To.Before(*filters.From) {
+		return filters, fmt.Errorf("to must not be before from")
+	}
+
+	return filters, nil
+}
+
+// parseQueryDate accepts a YYYY-MM-DD date or an RFC 3339 timestamp
+func parseQueryDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("expected YYYY-MM-DD or RFC 3339, got %q", value)
+}
+
+// reportQuery selects the goals that were active during the report range:
+// created before it ended and not completed before it started
+func reportQuery(scope goalScope, filters report.Filters, now time.Time) bson.M {
+	query := scope.ownedFilter()
+
+	if len(filters.Tags) > 0 {
+		query["tags"] = bson.M{"$in": filters.Tags}
+	}
+
+	switch filters.Status {
+	case report.StatusOpen:
+		query["completed"] = false
+	case report.StatusCompleted:
+		query["completed"] = true
+	case report.StatusOverdue:
+		query["completed"] = false
+		query["endDate"] = bson.M{"$lt": now}
+	}
+
+	if filters.To != nil {
+		end := *filters.To
+		if end.Equal(end.Truncate(24 * time.Hour)) {
+			// A plain date covers the whole day
+			end = end.Add(24 * time.Hour)
+		}
+		query["createdAt"] = bson.M{"$lt": end}
+	}
+	if filters.From != nil {
+		query["$or"] = bson.A{
+			bson.M{"completed": false},
+			bson.M{"completedAt": bson.M{"$gte": *filters.From}},
+		}
+	}
+
+	return query
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"task-management/internal/audit"
+	"task-management/internal/models"
+	"task-management/internal/revisions"
+)
+
+// ListRevisions handles listing a goal's revisions, newest first
+func (h *GoalHandler) ListRevisions(c *gin.Context) {
+	goal, _, ok := h.loadGoal(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	list, err := h.revisions.List(context.Background(), goal.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch revisions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// GetRevision handles getting the full snapshot of one revision
+func (h *GoalHandler) GetRevision(c *gin.Context) {
+	goal, _, ok := h.loadGoal(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	revision, ok := h.loadRevision(c, goal, c.Param("number"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffRevisions handles comparing two revisions field by field. from and to
+// are revision numbers; to defaults to the latest revision.
+func (h *GoalHandler) DiffRevisions(c *gin.Context) {
+	goal, _, ok := h.loadGoal(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	if c.Query("from") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from is required"})
+		return
+	}
+	from, ok := h.loadRevision(c, goal, c.Query("from"))
+	if !ok {
+		return
+	}
+	to, ok := h.loadRevision(c, goal, c.DefaultQuery("to", "latest"))
+	if !ok {
+		return
+	}
+
+	changes, err := audit.Diff(from.Goal, to.Goal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compare revisions"})
+		return
+	}
+	if changes == nil {
+		changes = []models.FieldChange{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"from": from.Number, "to": to.Number, "changes": changes})
+}
+
+// RestoreRevision handles restoring a goal to an earlier revision. Ownership,
+// workspace, sharing and the goal's parent are left as they are now, and the
+// restore is recorded as a new revision.
+func (h *GoalHandler) RestoreRevision(c *gin.Context) {
+	goal, _, ok := h.loadGoal(c, models.RoleEditor)
+	if !ok {
+		return
+	}
+
+	revision, ok := h.loadRevision(c, goal, c.Param("number"))
+	if !ok {
+		return
+	}
+
+	restored := revision.Goal.Clone()
+	restored.ID = goal.ID
+	restored.UserID = goal.UserID
+	restored.WorkspaceID = goal.WorkspaceID
+	restored.ParentID = goal.ParentID
+	restored.Members = goal.Members
+	restored.CreatedAt = goal.CreatedAt
+	restored.UpdatedAt = time.Now()
+
+	if err := h.replaceGoal(context.Background(), goal, restored); err != nil {
+		if err == errGoalConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Goal was modified by another request, please retry"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore goal"})
+		}
+		return
+	}
+	h.goalChanged(c, models.ActivityGoalRestored, nil, goal, restored)
+
+	c.JSON(http.StatusOK, restored)
+}
+
+// loadRevision fetches a goal's revision by number, or the latest one for
+// "latest", writing the error response and returning false when it can't
+func (h *GoalHandler) loadRevision(c *gin.Context, goal *models.Goal, value string) (*models.Revision, bool) {
+	number := 0
+	if value != "latest" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision number"})
+			return nil, false
+		}
+		number = n
+	}
+
+	revision, err := h.revisions.Get(context.Background(), goal.ID, number)
+	if err != nil {
+		if err == revisions.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get revision"})
+		return nil, false
+	}
+	if revision.Goal == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Revision has no snapshot"})
+		return nil, false
+	}
+
+	return revision, true
+}
@@ -1,21 +1,66 @@
 package handlers
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
 
+	"task-management/configs"
+	"task-management/internal/audit"
+	"task-management/internal/events"
+	"task-management/internal/history"
+	"task-management/internal/mail"
 	"task-management/internal/middleware"
+	"task-management/internal/revisions"
+	"task-management/internal/undo"
 )
 
-// SetupRoutes sets up all the routes for the application
-func SetupRoutes(router *gin.Engine, db *mongo.Database, jwtMiddleware *middleware.JwtMiddleware) {
+// SetupRoutes sets up all the routes for the application. Outgoing email is
+// sent with mailer.
+func SetupRoutes(router *gin.Engine, db *mongo.Database, jwtMiddleware *middleware.JwtMiddleware, mailer mail.Mailer, config *configs.Config) {
 	// Collections
 	userCollection := db.Collection("users")
 	goalCollection := db.Collection("goals")
+	historyCollection := db.Collection(history.CollectionName)
+	invitationCollection := db.Collection("invitations")
+	workspaceCollection := db.Collection("workspaces")
+	commentCollection := db.Collection("comments")
+	notificationCollection := db.Collection("notifications")
+	activityCollection := db.Collection(audit.CollectionName)
+	revisionCollection := db.Collection(revisions.CollectionName)
+	timeEntryCollection := db.Collection("time_entries")
+	timerCollection := db.Collection("timers")
+	focusCollection := db.Collection("focus_sessions")
+	templateCollection := db.Collection("templates")
+	filterCollection := db.Collection("saved_filters")
+
+	// Shared services
+	statsCache := NewStatsCache(15 * time.Minute)
+	recorder := history.NewRecorder(historyCollection)
+	activityLog := audit.NewLog(activityCollection)
+	undoManager := undo.NewManager(10*time.Minute, 20)
+	broker := events.NewBroker()
+	revisionStore := revisions.NewStore(revisionCollection, revisions.NewPolicy(config.RevisionKeepLast, config.RevisionKeepDays))
 
 	// Handlers
-	authHandler := NewAuthHandler(userCollection, jwtMiddleware)
-	goalHandler := NewGoalHandler(goalCollection)
+	authHandler := NewAuthHandler(userCollection, jwtMiddleware, activityLog)
+	goalHandler := NewGoalHandler(goalCollection, userCollection, statsCache, recorder, activityLog, revisionStore, undoManager)
+	transferHandler := NewTransferHandler(goalCollection, goalHandler)
+	reportHandler := NewReportHandler(goalCollection)
+	statsHandler := NewStatsHandler(goalCollection, userCollection, statsCache)
+	historyHandler := NewHistoryHandler(goalCollection, userCollection, recorder)
+	sharingHandler := NewSharingHandler(goalCollection, userCollection, invitationCollection, workspaceCollection, goalHandler)
+	workspaceHandler := NewWorkspaceHandler(workspaceCollection, userCollection, goalCollection, invitationCollection, goalHandler)
+	taskHandler := NewTaskHandler(goalCollection, userCollection)
+	commentHandler := NewCommentHandler(commentCollection, goalCollection, userCollection, workspaceCollection, notificationCollection)
+	notificationHandler := NewNotificationHandler(notificationCollection)
+	activityHandler := NewActivityHandler(activityCollection, goalCollection, userCollection)
+	timeHandler := NewTimeHandler(timeEntryCollection, timerCollection, goalCollection, userCollection)
+	templateHandler := NewTemplateHandler(templateCollection, goalCollection, userCollection, goalHandler)
+	focusHandler := NewFocusHandler(focusCollection, goalCollection, userCollection, broker)
+	viewHandler := NewViewHandler(filterCollection, goalCollection, userCollection)
+	profileHandler := NewProfileHandler(userCollection, workspaceCollection, jwtMiddleware, activityLog, mailer, config.DeletionGraceDays)
 
 	// Auth routes
 	auth := router.Group("/api/auth")
@@ -24,15 +69,189 @@ func SetupRoutes(router *gin.Engine, db *mongo.Database, jwtMiddleware *middlewa
 		auth.POST("/login", authHandler.Login)
 	}
 
-	// Goal routes (protected)
-	goals := router.Group("/api/goals")
-	goals.Use(jwtMiddleware.AuthRequired())
-	{
+	// Goal routes (protected). They are served for the personal space or the
+	// workspace in the X-Workspace-ID header, and under a workspace path.
+	goalRoutes := func(goals *gin.RouterGroup) {
 		goals.POST("", goalHandler.CreateGoal)
 		goals.GET("", goalHandler.ListGoals)
+		goals.GET("/tree", goalHandler.GetGoalTree)
+		goals.POST("/bulk", goalHandler.BulkGoals)
+		goals.POST("/quick-add", goalHandler.QuickAdd)
 		goals.GET("/:id", goalHandler.GetGoal)
 		goals.PUT("/:id", goalHandler.UpdateGoal)
 		goals.DELETE("/:id", goalHandler.DeleteGoal)
+		goals.GET("/:id/tree", goalHandler.GetGoalSubtree)
+		goals.GET("/:id/effort", goalHandler.GetGoalEffort)
+		goals.POST("/:id/move", goalHandler.MoveGoal)
+		goals.POST("/:id/duplicate", goalHandler.DuplicateGoal)
+		goals.POST("/:id/template", templateHandler.SaveGoalAsTemplate)
+		goals.POST("/:id/subtasks", goalHandler.AddSubTask)
+		goals.PUT("/:id/subtasks/:subtaskId", goalHandler.UpdateSubTask)
+		goals.DELETE("/:id/subtasks/:subtaskId", goalHandler.DeleteSubTask)
+		goals.POST("/:id/subtasks/:subtaskId/dependencies", goalHandler.AddDependency)
+		goals.DELETE("/:id/subtasks/:subtaskId/dependencies/:dependencyId", goalHandler.RemoveDependency)
+		goals.GET("/:id/schedule", goalHandler.GetSchedule)
+		goals.GET("/:id/gantt", goalHandler.GetGantt)
+		goals.POST("/:id/key-results", goalHandler.AddKeyResult)
+		goals.PUT("/:id/key-results/:keyResultId", goalHandler.UpdateKeyResult)
+		goals.DELETE("/:id/key-results/:keyResultId", goalHandler.DeleteKeyResult)
+		goals.GET("/:id/key-results/:keyResultId/check-ins", goalHandler.ListCheckIns)
+		goals.POST("/:id/key-results/:keyResultId/check-ins", goalHandler.CheckIn)
+		goals.PUT("/:id/habit", goalHandler.UpdateHabit)
+		goals.POST("/:id/habit/check-ins", goalHandler.CheckInHabit)
+		goals.DELETE("/:id/habit/check-ins/:date", goalHandler.UncheckHabit)
+		goals.GET("/:id/habit/stats", goalHandler.GetHabitStats)
+		goals.GET("/:id/habit/heatmap", goalHandler.GetHabitHeatmap)
+		goals.GET("/:id/burndown", historyHandler.GetGoalBurndown)
+		goals.GET("/:id/members", sharingHandler.ListMembers)
+		goals.PUT("/:id/members/:userId", sharingHandler.UpdateMember)
+		goals.DELETE("/:id/members/:userId", sharingHandler.RemoveMember)
+		goals.POST("/:id/invitations", sharingHandler.CreateInvitation)
+		goals.GET("/:id/invitations", sharingHandler.ListGoalInvitations)
+		goals.DELETE("/:id/invitations/:invitationId", sharingHandler.RevokeInvitation)
+		goals.GET("/:id/comments", commentHandler.ListComments)
+		goals.POST("/:id/comments", commentHandler.CreateComment)
+		goals.PUT("/:id/comments/:commentId", commentHandler.UpdateComment)
+		goals.DELETE("/:id/comments/:commentId", commentHandler.DeleteComment)
+		goals.GET("/:id/comments/:commentId/history", commentHandler.GetCommentHistory)
+		goals.GET("/:id/activity", activityHandler.GetGoalActivity)
+		goals.GET("/:id/revisions", goalHandler.ListRevisions)
+		goals.GET("/:id/revisions/diff", goalHandler.DiffRevisions)
+		goals.GET("/:id/revisions/:number", goalHandler.GetRevision)
+		goals.POST("/:id/revisions/:number/restore", goalHandler.RestoreRevision)
+		goals.GET("/:id/subtasks/:subtaskId/comments", commentHandler.ListComments)
+		goals.POST("/:id/subtasks/:subtaskId/comments", commentHandler.CreateComment)
+	}
+
+	goals := router.Group("/api/goals")
+	goals.Use(jwtMiddleware.AuthRequired())
+	goalRoutes(goals)
+
+	// Template routes (protected)
+	templates := router.Group("/api/templates")
+	templates.Use(jwtMiddleware.AuthRequired())
+	{
+		templates.POST("", templateHandler.CreateTemplate)
+		templates.GET("", templateHandler.ListTemplates)
+		templates.GET("/:templateId", templateHandler.GetTemplate)
+		templates.PUT("/:templateId", templateHandler.UpdateTemplate)
+		templates.DELETE("/:templateId", templateHandler.DeleteTemplate)
+		templates.POST("/:templateId/instantiate", templateHandler.InstantiateTemplate)
+	}
+
+	// Smart view and saved filter routes (protected)
+	views := router.Group("/api")
+	views.Use(jwtMiddleware.AuthRequired())
+	{
+		views.GET("/views/:view", viewHandler.GetView)
+		views.GET("/filters", viewHandler.ListSavedFilters)
+		views.POST("/filters", viewHandler.CreateSavedFilter)
+		views.GET("/filters/:filterId", viewHandler.GetSavedFilter)
+		views.PUT("/filters/:filterId", viewHandler.UpdateSavedFilter)
+		views.DELETE("/filters/:filterId", viewHandler.DeleteSavedFilter)
+		views.GET("/filters/:filterId/results", viewHandler.RunSavedFilter)
+	}
+
+	// Workspace routes (protected)
+	workspaces := router.Group("/api/workspaces")
+	workspaces.Use(jwtMiddleware.AuthRequired())
+	{
+		workspaces.POST("", workspaceHandler.CreateWorkspace)
+		workspaces.GET("", workspaceHandler.ListWorkspaces)
+		workspaces.GET("/:workspaceId", workspaceHandler.GetWorkspace)
+		workspaces.PUT("/:workspaceId", workspaceHandler.UpdateWorkspace)
+		workspaces.POST("/:workspaceId/invitations", workspaceHandler.InviteMember)
+		workspaces.GET("/:workspaceId/invitations", workspaceHandler.ListInvitations)
+		workspaces.DELETE("/:workspaceId/invitations/:invitationId", workspaceHandler.RevokeInvitation)
+		workspaces.PUT("/:workspaceId/members/:userId", workspaceHandler.UpdateMember)
+		workspaces.DELETE("/:workspaceId/members/:userId", workspaceHandler.RemoveMember)
+		workspaces.POST("/:workspaceId/transfer", workspaceHandler.TransferOwnership)
+		goalRoutes(workspaces.Group("/:workspaceId/goals"))
+	}
+
+	// Invitation routes (protected)
+	invitations := router.Group("/api/invitations")
+	invitations.Use(jwtMiddleware.AuthRequired())
+	{
+		invitations.GET("", sharingHandler.ListMyInvitations)
+		invitations.POST("/:invitationId/accept", sharingHandler.AcceptInvitation)
+		invitations.POST("/:invitationId/decline", sharingHandler.DeclineInvitation)
+	}
+
+	// Notification routes (protected)
+	notifications := router.Group("/api/notifications")
+	notifications.Use(jwtMiddleware.AuthRequired())
+	{
+		notifications.GET("", notificationHandler.ListNotifications)
+		notifications.POST("/read", notificationHandler.MarkAllNotificationsRead)
+		notifications.POST("/:notificationId/read", notificationHandler.MarkNotificationRead)
+	}
+
+	// Current user routes (protected)
+	me := router.Group("/api/me")
+	me.Use(jwtMiddleware.AuthRequired())
+	{
+		me.GET("", profileHandler.GetProfile)
+		me.PUT("", profileHandler.UpdateProfile)
+		me.DELETE("", profileHandler.DeleteAccount)
+		me.POST("/email/verify", profileHandler.VerifyEmail)
+		me.PUT("/password", profileHandler.ChangePassword)
+		me.POST("/deletion/cancel", profileHandler.CancelAccountDeletion)
+		me.GET("/tasks", taskHandler.MyTasks)
+		me.GET("/activity", activityHandler.GetMyActivity)
+	}
+
+	// Time tracking routes (protected)
+	timeTracking := router.Group("/api")
+	timeTracking.Use(jwtMiddleware.AuthRequired())
+	{
+		timeTracking.GET("/timer", timeHandler.GetTimer)
+		timeTracking.POST("/timer/start", timeHandler.StartTimer)
+		timeTracking.POST("/timer/stop", timeHandler.StopTimer)
+		timeTracking.GET("/time-entries", timeHandler.ListTimeEntries)
+		timeTracking.POST("/time-entries", timeHandler.CreateTimeEntry)
+		timeTracking.PUT("/time-entries/:entryId", timeHandler.UpdateTimeEntry)
+		timeTracking.DELETE("/time-entries/:entryId", timeHandler.DeleteTimeEntry)
+		timeTracking.GET("/time-reports", timeHandler.GetTimeReport)
+		timeTracking.GET("/time-reports/timesheet", timeHandler.ExportTimesheet)
+	}
+
+	// Focus session routes (protected)
+	focus := router.Group("/api/focus")
+	focus.Use(jwtMiddleware.AuthRequired())
+	{
+		focus.GET("/sessions", focusHandler.ListFocusSessions)
+		focus.POST("/sessions", focusHandler.StartFocusSession)
+		focus.GET("/sessions/current", focusHandler.GetCurrentFocusSession)
+		focus.POST("/sessions/:sessionId/pause", focusHandler.PauseFocusSession)
+		focus.POST("/sessions/:sessionId/resume", focusHandler.ResumeFocusSession)
+		focus.POST("/sessions/:sessionId/complete", focusHandler.CompleteFocusSession)
+		focus.POST("/sessions/:sessionId/abandon", focusHandler.AbandonFocusSession)
+		focus.GET("/stats", focusHandler.GetFocusStats)
+		focus.GET("/events", focusHandler.FocusEvents)
+	}
+
+	// Admin routes (protected; handlers check the admin flag)
+	admin := router.Group("/api/admin")
+	admin.Use(jwtMiddleware.AuthRequired())
+	{
+		admin.GET("/audit", activityHandler.ExportAudit)
+	}
+
+	// Import/export, report and stats routes (protected)
+	data := router.Group("/api")
+	data.Use(jwtMiddleware.AuthRequired())
+	{
+		data.GET("/export", transferHandler.Export)
+		data.POST("/import", transferHandler.Import)
+		data.POST("/import/:source", transferHandler.ImportFromSource)
+		data.GET("/reports", reportHandler.GetReport)
+		data.GET("/stats", statsHandler.GetStats)
+		data.GET("/capacity", statsHandler.GetCapacity)
+		data.GET("/burndown", historyHandler.GetBurndown)
+		data.GET("/undo", goalHandler.ListUndo)
+		data.POST("/undo", goalHandler.Undo)
+		data.POST("/redo", goalHandler.Redo)
 	}
 
 	// Health check
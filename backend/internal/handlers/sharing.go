@@ -0,0 +1,559 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/models"
+)
+
+// SharingHandler handles goal membership and invitation routes
+type SharingHandler struct {
+	goalCollection       *mongo.Collection
+	userCollection       *mongo.Collection
+	invitationCollection *mongo.Collection
+	workspaceCollection  *mongo.Collection
+	goals                *GoalHandler
+	validator            *validator.Validate
+}
+
+// NewSharingHandler creates a new sharing handler. Membership changes are
+// written through goals so they get the same bookkeeping as other changes.
+func NewSharingHandler(goalCollection, userCollection, invitationCollection, workspaceCollection *mongo.Collection, goals *GoalHandler) *SharingHandler {
+	return &SharingHandler{
+		goalCollection:       goalCollection,
+		userCollection:       userCollection,
+		invitationCollection: invitationCollection,
+		workspaceCollection:  workspaceCollection,
+		goals:                goals,
+		validator:            validator.New(),
+	}
+}
+
+// InviteRequest represents the invite collaborator request
+type InviteRequest struct {
+	Email    string `json:"email,omitempty" validate:"required_without=Username,omitempty,email"`
+	Username string `json:"username,omitempty" validate:"required_without=Email"`
+	Role     string `json:"role" validate:"required,oneof=editor commenter viewer"`
+}
+
+// UpdateMemberRequest represents the change member role request
+type UpdateMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=editor commenter viewer"`
+}
+
+// MemberResponse represents a goal member with their public details
+type MemberResponse struct {
+	UserID    primitive.ObjectID `json:"userId"`
+	Username  string             `json:"username"`
+	FirstName string             `json:"firstName,omitempty"`
+	LastName  string             `json:"lastName,omitempty"`
+	Role      string             `json:"role"`
+	AddedAt   *time.Time         `json:"addedAt,omitempty"`
+}
+
+// CreateInvitation handles inviting a user to a goal by email or username
+func (h *SharingHandler) CreateInvitation(c *gin.Context) {
+	var req InviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, scope, ok := h.loadGoal(c, models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	invitation := models.Invitation{
+		ID:        primitive.NewObjectID(),
+		GoalID:    &goal.ID,
+		GoalTitle: goal.Title,
+		InviterID: scope.UserID,
+		Role:      req.Role,
+		Status:    models.InvitationPending,
+		CreatedAt: time.Now(),
+	}
+
+	// Resolve the invitee; unknown emails are kept so they can accept after registering
+	lookup := bson.M{"username": req.Username}
+	if req.Email != "" {
+		invitation.Email = strings.ToLower(req.Email)
+		lookup = bson.M{"email": emailFilter(invitation.Email)}
+	}
+
+	var invitee models.User
+	err := h.userCollection.FindOne(context.Background(), lookup).Decode(&invitee)
+	switch {
+	case err == nil:
+		if goal.RoleOf(invitee.ID) != "" {
+			c.JSON(http.StatusConflict, gin.H{"error": "User already has access to this goal"})
+			return
+		}
+		if goal.WorkspaceID != nil {
+			count, err := h.workspaceCollection.CountDocuments(context.Background(), bson.M{
+				"_id":            *goal.WorkspaceID,
+				"members.userId": invitee.ID,
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up workspace"})
+				return
+			}
+			if count == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "User is not a member of this workspace"})
+				return
+			}
+		}
+		invitation.InviteeID = &invitee.ID
+		invitation.Email = strings.ToLower(invitee.Email)
+	case err == mongo.ErrNoDocuments && (req.Email == "" || goal.WorkspaceID != nil):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	case err != mongo.ErrNoDocuments:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up user"})
+		return
+	}
+
+	// Only one pending invitation per goal and invitee
+	count, err := h.invitationCollection.CountDocuments(context.Background(), bson.M{
+		"goalId": goal.ID,
+		"email":  invitation.Email,
+		"status": models.InvitationPending,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "An invitation is already pending for this user"})
+		return
+	}
+
+	if _, err := h.invitationCollection.InsertOne(context.Background(), invitation); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// ListGoalInvitations handles listing the pending invitations for a goal
+func (h *SharingHandler) ListGoalInvitations(c *gin.Context) {
+	goal, _, ok := h.loadGoal(c, models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	respondInvitations(c, h.invitationCollection, bson.M{"goalId": goal.ID, "status": models.InvitationPending})
+}
+
+// RevokeInvitation handles cancelling a pending invitation
+func (h *SharingHandler) RevokeInvitation(c *gin.Context) {
+	invitationID, err := primitive.ObjectIDFromHex(c.Param("invitationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	goal, _, ok := h.loadGoal(c, models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	result, err := h.invitationCollection.UpdateOne(context.Background(),
+		bson.M{"_id": invitationID, "goalId": goal.ID, "status": models.InvitationPending},
+		bson.M{"$set": bson.M{"status": models.InvitationRevoked, "respondedAt": now}},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// ListMyInvitations handles listing the caller's pending invitations
+func (h *SharingHandler) ListMyInvitations(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	respondInvitations(c, h.invitationCollection, bson.M{
+		"status": models.InvitationPending,
+		"$or": bson.A{
+			bson.M{"inviteeId": user.ID},
+			bson.M{"email": strings.ToLower(user.Email)},
+		},
+	})
+}
+
+// AcceptInvitation handles accepting an invitation, adding the caller as a
+// member of the goal or workspace
+func (h *SharingHandler) AcceptInvitation(c *gin.Context) {
+	invitation, user, ok := h.loadInvitation(c)
+	if !ok {
+		return
+	}
+	if invitation.WorkspaceID != nil {
+		h.joinWorkspace(c, invitation, user)
+		return
+	}
+
+	now := time.Now()
+	var goal models.Goal
+	err := h.goalCollection.FindOne(context.Background(), bson.M{"_id": *invitation.GoalID}).Decode(&goal)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			h.setInvitationStatus(invitation.ID, models.InvitationRevoked, now)
+			c.JSON(http.StatusGone, gin.H{"error": "The goal no longer exists"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		}
+		return
+	}
+
+	// Users who already have access to the goal keep their role
+	if goal.RoleOf(user.ID) == "" {
+		after := goal.Clone()
+		after.Members = append(after.Members, models.GoalMember{
+			UserID:  user.ID,
+			Role:    invitation.Role,
+			AddedBy: invitation.InviterID,
+			AddedAt: now,
+		})
+		if !h.saveMembers(c, models.ActivityMemberAdded, &goal, after) {
+			return
+		}
+	}
+
+	h.setInvitationStatus(invitation.ID, models.InvitationAccepted, now)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation accepted", "goalId": invitation.GoalID, "role": invitation.Role})
+}
+
+// joinWorkspace accepts an invitation to a workspace. Users who are already
+// members keep their role.
+func (h *SharingHandler) joinWorkspace(c *gin.Context, invitation *models.Invitation, user *models.User) {
+	now := time.Now()
+	var workspace models.Workspace
+	err := h.workspaceCollection.FindOne(context.Background(), bson.M{"_id": *invitation.WorkspaceID}).Decode(&workspace)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			h.setInvitationStatus(invitation.ID, models.InvitationRevoked, now)
+			c.JSON(http.StatusGone, gin.H{"error": "The workspace no longer exists"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		}
+		return
+	}
+
+	if workspace.RoleOf(user.ID) == "" {
+		result, err := h.workspaceCollection.UpdateOne(context.Background(),
+			bson.M{"_id": workspace.ID, "updatedAt": workspace.UpdatedAt},
+			bson.M{
+				"$push": bson.M{"members": models.WorkspaceMember{UserID: user.ID, Role: invitation.Role, JoinedAt: now}},
+				"$set":  bson.M{"updatedAt": now},
+			},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Workspace was modified by another request, please retry"})
+			return
+		}
+	}
+
+	h.setInvitationStatus(invitation.ID, models.InvitationAccepted, now)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation accepted", "workspaceId": workspace.ID, "role": invitation.Role})
+}
+
+// DeclineInvitation handles declining an invitation
+func (h *SharingHandler) DeclineInvitation(c *gin.Context) {
+	invitation, _, ok := h.loadInvitation(c)
+	if !ok {
+		return
+	}
+
+	if err := h.setInvitationStatus(invitation.ID, models.InvitationDeclined, time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline invitation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation declined"})
+}
+
+// ListMembers handles listing a goal's owner and members
+func (h *SharingHandler) ListMembers(c *gin.Context) {
+	goal, _, ok := h.loadGoal(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	ids := []primitive.ObjectID{goal.UserID}
+	for _, member := range goal.Members {
+		ids = append(ids, member.UserID)
+	}
+
+	cursor, err := h.userCollection.Find(context.Background(), bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list members"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	var users []models.User
+	if err := cursor.All(context.Background(), &users); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode members"})
+		return
+	}
+
+	byID := make(map[primitive.ObjectID]models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	response := []MemberResponse{memberResponse(byID[goal.UserID], goal.UserID, models.RoleOwner, nil)}
+	for _, member := range goal.Members {
+		addedAt := member.AddedAt
+		response = append(response, memberResponse(byID[member.UserID], member.UserID, member.Role, &addedAt))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateMember handles changing a member's role
+func (h *SharingHandler) UpdateMember(c *gin.Context) {
+	memberID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, _, ok := h.loadGoal(c, models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	after := goal.Clone()
+	found := false
+	for i := range after.Members {
+		if after.Members[i].UserID == memberID {
+			after.Members[i].Role = req.Role
+			found = true
+		}
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+	if !h.saveMembers(c, models.ActivityMemberUpdated, goal, after) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member updated successfully", "userId": memberID, "role": req.Role})
+}
+
+// RemoveMember handles removing a member. Owners can remove anyone and
+// members can remove themselves to leave a goal.
+func (h *SharingHandler) RemoveMember(c *gin.Context) {
+	memberID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	goal, scope, ok := h.loadGoal(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	if memberID == goal.UserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The owner can't be removed from a goal"})
+		return
+	}
+	if memberID != scope.UserID && scope.roleOn(goal) != models.RoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to do this"})
+		return
+	}
+
+	after := goal.Clone()
+	if !after.RemoveMember(memberID, scope.UserID, time.Now()) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+	if !h.saveMembers(c, models.ActivityMemberRemoved, goal, after) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// saveMembers writes a goal whose members changed, writing the error
+// response and returning false when the write fails. Like saveGoal, it only
+// succeeds if the goal hasn't changed since before was read.
+func (h *SharingHandler) saveMembers(c *gin.Context, action string, before, after *models.Goal) bool {
+	after.UpdatedAt = time.Now()
+
+	if err := h.goals.replaceGoal(context.Background(), before, after); err != nil {
+		if err == errGoalConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Goal was modified by another request, please retry"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update members"})
+		}
+		return false
+	}
+	h.goals.goalChanged(c, action, nil, before, after)
+	return true
+}
+
+// loadGoal fetches the goal named by the :id route parameter for the caller
+func (h *SharingHandler) loadGoal(c *gin.Context, minRole string) (*models.Goal, goalScope, bool) {
+	goalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal ID"})
+		return nil, goalScope{}, false
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return nil, goalScope{}, false
+	}
+
+	goal, _, err := findGoalWithRole(context.Background(), h.goalCollection, goalID, scope, minRole)
+	if err != nil {
+		respondGoalAccessError(c, err)
+		return nil, goalScope{}, false
+	}
+
+	return goal, scope, true
+}
+
+// currentUser loads the authenticated user
+func (h *SharingHandler) currentUser(c *gin.Context) (*models.User, bool) {
+	// Get user ID from context
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return nil, false
+	}
+
+	var user models.User
+	if err := h.userCollection.FindOne(context.Background(), bson.M{"_id": userID.(primitive.ObjectID)}).Decode(&user); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return nil, false
+	}
+
+	return &user, true
+}
+
+// loadInvitation fetches a pending invitation addressed to the caller
+func (h *SharingHandler) loadInvitation(c *gin.Context) (*models.Invitation, *models.User, bool) {
+	invitationID, err := primitive.ObjectIDFromHex(c.Param("invitationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return nil, nil, false
+	}
+
+	user, ok := h.currentUser(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	var invitation models.Invitation
+	err = h.invitationCollection.FindOne(context.Background(), bson.M{
+		"_id":    invitationID,
+		"status": models.InvitationPending,
+		"$or": bson.A{
+			bson.M{"inviteeId": user.ID},
+			bson.M{"email": strings.ToLower(user.Email)},
+		},
+	}).Decode(&invitation)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get invitation"})
+		}
+		return nil, nil, false
+	}
+
+	return &invitation, user, true
+}
+
+func (h *SharingHandler) setInvitationStatus(invitationID primitive.ObjectID, status string, at time.Time) error {
+	_, err := h.invitationCollection.UpdateOne(context.Background(),
+		bson.M{"_id": invitationID},
+		bson.M{"$set": bson.M{"status": status, "respondedAt": at}},
+	)
+	return err
+}
+
+// respondInvitations responds with the invitations matching filter, newest
+// first
+func respondInvitations(c *gin.Context, invitationCollection *mongo.Collection, filter bson.M) {
+	cursor, err := invitationCollection.Find(context.Background(), filter,
+		options.Find().SetSort(bson.M{"createdAt": -1}),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list invitations"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	invitations := []models.Invitation{}
+	if err := cursor.All(context.Background(), &invitations); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+func memberResponse(user models.User, userID primitive.ObjectID, role string, addedAt *time.Time) MemberResponse {
+	return MemberResponse{
+		UserID:    userID,
+		Username:  user.Username,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Role:      role,
+		AddedAt:   addedAt,
+	}
+}
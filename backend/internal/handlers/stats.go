@@ -0,0 +1,447 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Number of periods returned in the weekly and monthly series
+const (
+	statsWeeks  = 26
+	statsMonths = 12
+)
+
+// dayLayout formats calendar days for streak calculations
+const dayLayout = "2006-01-02"
+
+// StatsCache caches computed statistics per user and time zone until the
+// user's goals change or the entry expires
+type StatsCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[primitive.ObjectID]map[string]statsCacheEntry
+}
+
+type statsCacheEntry struct {
+	stats     *StatsResponse
+	expiresAt time.Time
+}
+
+// NewStatsCache creates a new stats cache
+func NewStatsCache(ttl time.Duration) *StatsCache {
+	return &StatsCache{
+		ttl:     ttl,
+		entries: make(map[primitive.ObjectID]map[string]statsCacheEntry),
+	}
+}
+
+// Get returns cached statistics if they are still fresh
+func (c *StatsCache) Get(userID primitive.ObjectID, tz string) (*StatsResponse, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[userID][tz]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.stats, true
+}
+
+// Set stores statistics for a user and time zone
+func (c *StatsCache) Set(userID primitive.ObjectID, tz string, stats *StatsResponse) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries[userID] == nil {
+		c.entries[userID] = make(map[string]statsCacheEntry)
+	}
+	c.entries[userID][tz] = statsCacheEntry{stats: stats, expiresAt: time.Now().Add(c.ttl)}
+}
+
+// Invalidate drops all cached statistics for a user
+func (c *StatsCache) Invalidate(userID primitive.ObjectID) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, userID)
+}
+
+// StatsHandler handles goal statistics routes
+type StatsHandler struct {
+	goalCollection *mongo.Collection
+	userCollection *mongo.Collection
+	cache          *StatsCache
+}
+
+// NewStatsHandler creates a new stats handler
+func NewStatsHandler(goalCollection, userCollection *mongo.Collection, cache *StatsCache) *StatsHandler {
+	return &StatsHandler{
+		goalCollection: goalCollection,
+		userCollection: userCollection,
+		cache:          cache,
+	}
+}
+
+// PeriodStats represents completion numbers for a week or month
+type PeriodStats struct {
+	Start          time.Time `json:"start"`
+	Created        int       `json:"created"`
+	Completed      int       `json:"completed"`
+	CompletionRate float64   `json:"completionRate"`
+}
+
+// DistributionBucket represents the number of goals within a progress range
+type DistributionBucket struct {
+	Range string `json:"range"`
+	Count int    `json:"count"`
+}
+
+// StatsResponse represents the statistics response
+type StatsResponse struct {
+	Timezone    string        `json:"timezone"`
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Weekly      []PeriodStats `json:"weekly"`
+	Monthly     []PeriodStats `json:"monthly"`
+	// Average time from creation to completion, in hours
+	AverageCompletionHours struct {
+		Goals    float64 `json:"goals"`
+		SubTasks float64 `json:"subTasks"`
+	} `json:"averageCompletionHours"`
+	Overdue struct {
+		Goals    int `json:"goals"`
+		SubTasks int `json:"subTasks"`
+	} `json:"overdue"`
+	Streaks struct {
+		Current         int    `json:"current"`
+		Longest         int    `json:"longest"`
+		LastCompletedOn string `json:"lastCompletedOn,omitempty"`
+	} `json:"streaks"`
+	ProgressDistribution []DistributionBucket `json:"progressDistribution"`
+}
+
+// statsFacets is the decoded result of the statistics aggregation
+type statsFacets struct {
+	WeeklyCreated    []periodCount `bson:"weeklyCreated"`
+	WeeklyCompleted  []periodCount `bson:"weeklyCompleted"`
+	MonthlyCreated   []periodCount `bson:"monthlyCreated"`
+	MonthlyCompleted []periodCount `bson:"monthlyCompleted"`
+	Durations        []struct {
+		Kind    string  `bson:"_id"`
+		AvgMs   float64 `bson:"avgMs"`
+		Samples int     `bson:"samples"`
+	} `bson:"durations"`
+	Overdue []struct {
+		Kind  string `bson:"_id"`
+		Count int    `bson:"count"`
+	} `bson:"overdue"`
+	CompletionDays []struct {
+		Day string `bson:"_id"`
+	} `bson:"completionDays"`
+	Progress []struct {
+		Lower interface{} `bson:"_id"`
+		Count int         `bson:"count"`
+	} `bson:"progress"`
+}
+
+type periodCount struct {
+	Start     time.Time `bson:"_id"`
+	Count     int       `bson:"count"`
+	Completed int       `bson:"completed"`
+}
+
+// GetStats returns completion statistics for the user's goals
+func (h *StatsHandler) GetStats(c *gin.Context) {
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	loc, err := resolveLocation(c, h.userCollection, scope.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Cached per timezone and workspace
+	cacheKey := loc.String() + "|" + scope.key()
+	if stats, ok := h.cache.Get(scope.UserID, cacheKey); ok {
+		c.JSON(http.StatusOK, stats)
+		return
+	}
+
+	now := time.Now().In(loc)
+	cursor, err := h.goalCollection.Aggregate(context.Background(), statsPipeline(scope.ownedFilter(), loc.String(), now))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute statistics"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	var results []statsFacets
+	if err := cursor.All(context.Background(), &results); err != nil || len(results) != 1 {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode statistics"})
+		return
+	}
+
+	stats := buildStats(results[0], loc, now)
+	h.cache.Set(scope.UserID, cacheKey, stats)
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// statsPipeline builds a single aggregation that computes every statistic
+// in its own $facet. Goals and subtasks are flattened into "items" for the
+// period, duration and streak facets.
+func statsPipeline(match bson.M, tz string, now time.Time) mongo.Pipeline {
+	items := bson.D{{Key: "$concatArrays", Value: bson.A{
+		bson.A{bson.M{
+			"kind":        "goal",
+			"createdAt":   "$createdAt",
+			"completed":   "$completed",
+			"completedAt": "$completedAt",
+			"due":         "$endDate",
+		}},
+		bson.M{"$map": bson.M{
+			"input": bson.M{"$ifNull": bson.A{"$subTasks", bson.A{}}},
+			"as":    "t",
+			"in": bson.M{
+				"kind":        "subtask",
+				"createdAt":   "$$t.createdAt",
+				"completed":   "$$t.completed",
+				"completedAt": "$$t.completedAt",
+				"due":         "$$t.dueDate",
+			},
+		}},
+	}}}
+
+	flatten := []bson.D{
+		{{Key: "$project", Value: bson.M{"item": items}}},
+		{{Key: "$unwind", Value: "$item"}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$item"}}},
+	}
+
+	trunc := func(field, unit string) bson.M {
+		spec := bson.M{"date": field, "unit": unit, "timezone": tz}
+		if unit == "week" {
+			spec["startOfWeek"] = "monday"
+		}
+		return bson.M{"$dateTrunc": spec}
+	}
+
+	periods := func(field, unit string, since time.Time) []bson.D {
+		stages := append([]bson.D{}, flatten...)
+		return append(stages,
+			bson.D{{Key: "$match", Value: bson.M{field: bson.M{"$gte": since}}}},
+			bson.D{{Key: "$group", Value: bson.M{
+				"_id":       trunc("$"+field, unit),
+				"count":     bson.M{"$sum": 1},
+				"completed": bson.M{"$sum": bson.M{"$cond": bson.A{"$completed", 1, 0}}},
+			}}},
+			bson.D{{Key: "$sort", Value: bson.M{"_id": 1}}},
+		)
+	}
+
+	weeksAgo := now.AddDate(0, 0, -7*statsWeeks)
+	monthsAgo := now.AddDate(0, -statsMonths, 0)
+
+	durations := append(append([]bson.D{}, flatten...),
+		bson.D{{Key: "$match", Value: bson.M{"completed": true, "completedAt": bson.M{"$ne": nil}}}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":     "$kind",
+			"avgMs":   bson.M{"$avg": bson.M{"$subtract": bson.A{"$completedAt", "$createdAt"}}},
+			"samples": bson.M{"$sum": 1},
+		}}},
+	)
+
+	overdue := append(append([]bson.D{}, flatten...),
+		bson.D{{Key: "$match", Value: bson.M{"completed": false, "due": bson.M{"$lt": now}}}},
+		bson.D{{Key: "$group", Value: bson.M{"_id": "$kind", "count": bson.M{"$sum": 1}}}},
+	)
+
+	completionDays := append(append([]bson.D{}, flatten...),
+		bson.D{{Key: "$match", Value: bson.M{"completed": true, "completedAt": bson.M{"$ne": nil}}}},
+		bson.D{{Key: "$group", Value: bson.M{"_id": bson.M{"$dateToString": bson.M{
+			"format":   "%Y-%m-%d",
+			"date":     "$completedAt",
+			"timezone": tz,
+		}}}}},
+		bson.D{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	)
+
+	progress := []bson.D{
+		{{Key: "$bucket", Value: bson.M{
+			"groupBy":    "$progress",
+			"boundaries": bson.A{0, 25, 50, 75, 100, 101},
+			"default":    "other",
+			"output":     bson.M{"count": bson.M{"$sum": 1}},
+		}}},
+	}
+
+	// The completed series is bucketed by completion date, not creation
+	completedPeriods := func(unit string, since time.Time) []bson.D {
+		stages := append([]bson.D{}, flatten...)
+		return append(stages,
+			bson.D{{Key: "$match", Value: bson.M{"completed": true, "completedAt": bson.M{"$gte": since}}}},
+			bson.D{{Key: "$group", Value: bson.M{
+				"_id":   trunc("$completedAt", unit),
+				"count": bson.M{"$sum": 1},
+			}}},
+			bson.D{{Key: "$sort", Value: bson.M{"_id": 1}}},
+		)
+	}
+
+	return mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$facet", Value: bson.M{
+			"weeklyCreated":    periods("createdAt", "week", weeksAgo),
+			"weeklyCompleted":  completedPeriods("week", weeksAgo),
+			"monthlyCreated":   periods("createdAt", "month", monthsAgo),
+			"monthlyCompleted": completedPeriods("month", monthsAgo),
+			"durations":        durations,
+			"overdue":          overdue,
+			"completionDays":   completionDays,
+			"progress":         progress,
+		}}},
+	}
+}
+
+// buildStats converts the aggregation output into the response
+func buildStats(f statsFacets, loc *time.Location, now time.Time) *StatsResponse {
+	stats := &StatsResponse{
+		Timezone:    loc.String(),
+		GeneratedAt: now,
+		Weekly:      mergePeriods(f.WeeklyCreated, f.WeeklyCompleted, loc),
+		Monthly:     mergePeriods(f.MonthlyCreated, f.MonthlyCompleted, loc),
+	}
+
+	for _, d := range f.Durations {
+		hours := d.AvgMs / float64(time.Hour/time.Millisecond)
+		if d.Kind == "goal" {
+			stats.AverageCompletionHours.Goals = hours
+		} else {
+			stats.AverageCompletionHours.SubTasks = hours
+		}
+	}
+
+	for _, o := range f.Overdue {
+		if o.Kind == "goal" {
+			stats.Overdue.Goals = o.Count
+		} else {
+			stats.Overdue.SubTasks = o.Count
+		}
+	}
+
+	days := make([]string, 0, len(f.CompletionDays))
+	for _, d := range f.CompletionDays {
+		days = append(days, d.Day)
+	}
+	stats.Streaks.Current, stats.Streaks.Longest = completionStreaks(days, now.In(loc))
+	if len(days) > 0 {
+		stats.Streaks.LastCompletedOn = days[len(days)-1]
+	}
+
+	labels := map[string]string{"0": "0-25", "25": "25-50", "50": "50-75", "75": "75-100", "100": "100"}
+	counts := make(map[string]int)
+	for _, b := range f.Progress {
+		counts[labelFor(b.Lower)] = b.Count
+	}
+	for _, lower := range []string{"0", "25", "50", "75", "100"} {
+		stats.ProgressDistribution = append(stats.ProgressDistribution, DistributionBucket{
+			Range: labels[lower],
+			Count: counts[lower],
+		})
+	}
+
+	return stats
+}
+
+// labelFor normalises a $bucket boundary, which may decode as any numeric type
+func labelFor(lower interface{}) string {
+	switch v := lower.(type) {
+	case int32:
+		return strconv.Itoa(int(v))
+	case int64:
+		return strconv.Itoa(int(v))
+	case float64:
+		return strconv.Itoa(int(v))
+	}
+	return "other"
+}
+
+// mergePeriods joins creation and completion counts on the period start
+func mergePeriods(created, completed []periodCount, loc *time.Location) []PeriodStats {
+	byStart := make(map[time.Time]*PeriodStats)
+	for _, p := range created {
+		byStart[p.Start] = &PeriodStats{Start: p.Start, Created: p.Count}
+		if p.Count > 0 {
+			// Share of items created in the period that have since been completed
+			byStart[p.Start].CompletionRate = float64(p.Completed) / float64(p.Count) * 100
+		}
+	}
+	for _, p := range completed {
+		if byStart[p.Start] == nil {
+			byStart[p.Start] = &PeriodStats{Start: p.Start}
+		}
+		byStart[p.Start].Completed = p.Count
+	}
+
+	periods := make([]PeriodStats, 0, len(byStart))
+	for _, p := range byStart {
+		p.Start = p.Start.In(loc)
+		periods = append(periods, *p)
+	}
+	sort.Slice(periods, func(i, j int) bool { return periods[i].Start.Before(periods[j].Start) })
+	return periods
+}
+
+// completionStreaks returns the current and longest runs of consecutive days
+// with at least one completion. days must be sorted YYYY-MM-DD strings. The
+// current streak survives until the end of the day after the last completion.
+func completionStreaks(days []string, now time.Time) (current, longest int) {
+	var prev time.Time
+	run := 0
+	for _, day := range days {
+		t, err := time.Parse(dayLayout, day)
+		if err != nil {
+			continue
+		}
+		if !prev.IsZero() && t.Sub(prev) == 24*time.Hour {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+		prev = t
+	}
+
+	if prev.IsZero() {
+		return 0, longest
+	}
+
+	today, _ := time.Parse(dayLayout, now.Format(dayLayout))
+	if gap := today.Sub(prev); gap <= 24*time.Hour {
+		current = run
+	}
+	return current, longest
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStatsCache(t *testing.T) {
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	stats := &StatsResponse{Timezone: "UTC"}
+
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		run  func(cache *StatsCache)
+		user primitive.ObjectID
+		tz   string
+		want bool
+	}{
+		{
+			name: "fresh entry",
+			ttl:  time.Minute,
+			run:  func(cache *StatsCache) { cache.Set(alice, "UTC", stats) },
+			user: alice, tz: "UTC", want: true,
+		},
+		{
+			name: "other time zone",
+			ttl:  time.Minute,
+			run:  func(cache *StatsCache) { cache.Set(alice, "UTC", stats) },
+			user: alice, tz: "Europe/Berlin", want: false,
+		},
+		{
+			name: "expired entry",
+			ttl:  -time.Second,
+			run:  func(cache *StatsCache) { cache.Set(alice, "UTC", stats) },
+			user: alice, tz: "UTC", want: false,
+		},
+		{
+			name: "invalidated user",
+			ttl:  time.Minute,
+			run: func(cache *StatsCache) {
+				cache.Set(alice, "UTC", stats)
+				cache.Set(alice, "Europe/Berlin", stats)
+				cache.Invalidate(alice)
+			},
+			user: alice, tz: "Europe/Berlin", want: false,
+		},
+		{
+			name: "other user invalidated",
+			ttl:  time.Minute,
+			run: func(cache *StatsCache) {
+				cache.Set(alice, "UTC", stats)
+				cache.Invalidate(bob)
+			},
+			user: alice, tz: "UTC", want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewStatsCache(tt.ttl)
+			tt.run(cache)
+			got, ok := cache.Get(tt.user, tt.tz)
+			if ok != tt.want {
+				t.Fatalf("Get() ok = %v, want %v", ok, tt.want)
+			}
+			if ok && got != stats {
+				t.Errorf("Get() returned a different response")
+			}
+		})
+	}
+}
+
+func TestStatsCacheNil(t *testing.T) {
+	var cache *StatsCache
+	cache.Set(primitive.NewObjectID(), "UTC", &StatsResponse{})
+	cache.Invalidate(primitive.NewObjectID())
+	if _, ok := cache.Get(primitive.NewObjectID(), "UTC"); ok {
+		t.Error("nil cache should never hit")
+	}
+}
+
+func TestCompletionStreaks(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		days             []string
+		current, longest int
+	}{
+		{"no completions", nil, 0, 0},
+		{"today only", []string{"2026-03-10"}, 1, 1},
+		{"run ending yesterday", []string{"2026-03-08", "2026-03-09"}, 2, 2},
+		{"run ended two days ago", []string{"2026-03-07", "2026-03-08"}, 0, 2},
+		{"gap resets the run", []string{"2026-03-01", "2026-03-02", "2026-03-03", "2026-03-09", "2026-03-10"}, 2, 3},
+		{"across a month boundary", []string{"2026-02-27", "2026-02-28", "2026-03-01"}, 0, 3},
+		{"invalid days are skipped", []string{"2026-03-09", "not-a-day", "2026-03-10"}, 2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, longest := completionStreaks(tt.days, now)
+			if current != tt.current || longest != tt.longest {
+				t.Errorf("completionStreaks() = %d, %d, want %d, %d", current, longest, tt.current, tt.longest)
+			}
+		})
+	}
+}
+
+func TestMergePeriods(t *testing.T) {
+	week1 := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	week2 := week1.AddDate(0, 0, 7)
+
+	periods := mergePeriods(
+		[]periodCount{{Start: week2, Count: 4, Completed: 1}, {Start: week1, Count: 2, Completed: 2}},
+		[]periodCount{{Start: week2, Count: 3}, {Start: week1.AddDate(0, 0, -7), Count: 1}},
+		time.UTC,
+	)
+
+	want := []PeriodStats{
+		{Start: week1.AddDate(0, 0, -7), Completed: 1},
+		{Start: week1, Created: 2, CompletionRate: 100},
+		{Start: week2, Created: 4, Completed: 3, CompletionRate: 25},
+	}
+	if len(periods) != len(want) {
+		t.Fatalf("got %d periods, want %d", len(periods), len(want))
+	}
+	for i := range want {
+		if !periods[i].Start.Equal(want[i].Start) || periods[i].Created != want[i].Created ||
+			periods[i].Completed != want[i].Completed || periods[i].CompletionRate != want[i].CompletionRate {
+			t.Errorf("period %d = %+v, want %+v", i, periods[i], want[i])
+		}
+	}
+}
+
+func TestBuildStatsDistribution(t *testing.T) {
+	var facets statsFacets
+	for _, bucket := range []struct {
+		lower interface{}
+		count int
+	}{{int32(0), 3}, {int64(50), 2}, {float64(100), 1}, {"bogus", 9}} {
+		facets.Progress = append(facets.Progress, struct {
+			Lower interface{} `bson:"_id"`
+			Count int         `bson:"count"`
+		}{bucket.lower, bucket.count})
+	}
+
+	stats := buildStats(facets, time.UTC, time.Now())
+
+	want := map[string]int{"0-25": 3, "25-50": 0, "50-75": 2, "75-100": 0, "100": 1}
+	if len(stats.ProgressDistribution) != len(want) {
+		t.Fatalf("got %d buckets, want %d", len(stats.ProgressDistribution), len(want))
+	}
+	for _, bucket := range stats.ProgressDistribution {
+		if bucket.Count != want[bucket.Range] {
+			t.Errorf("bucket %s = %d, want %d", bucket.Range, bucket.Count, want[bucket.Range])
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"task-management/internal/models"
+)
+
+// errGoalConflict is returned when a goal changed between being read and written
+var errGoalConflict = errors.New("goal was modified concurrently")
+
+// UpdateSubTaskRequest represents the update subtask request
+type UpdateSubTaskRequest struct {
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+	DueDate     *time.Time `json:"dueDate,omitempty"`
+	Completed   *bool      `json:"completed,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	Priority    string     `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent"`
+	Weight      *float64   `json:"weight,omitempty" validate:"omitempty,gt=0"`
+	Estimate    *float64   `json:"estimate,omitempty" validate:"omitempty,gte=0"`
+	// AssigneeID assigns the subtask to a goal member; an empty string
+	// unassigns it
+	AssigneeID *string `json:"assigneeId,omitempty"`
+}
+
+// AddSubTask handles adding a subtask to a goal
+func (h *GoalHandler) AddSubTask(c *gin.Context) {
+	var req AddSubTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, scope, ok := h.loadGoal(c, models.RoleEditor)
+	if !ok {
+		return
+	}
+
+	h.addSubTask(c, scope, goal, &req)
+}
+
+// addSubTask adds a subtask from a validated request to a goal the caller
+// can edit, and responds with the goal
+func (h *GoalHandler) addSubTask(c *gin.Context, scope goalScope, goal *models.Goal, req *AddSubTaskRequest) {
+	before := goal.Clone()
+
+	if goal.Type == models.GoalTypeHabit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Habit goals are tracked by check-ins"})
+		return
+	}
+
+	if req.AssigneeID != nil && goal.RoleOf(*req.AssigneeID) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Assignee must be a member of the goal"})
+		return
+	}
+
+	now := time.Now()
+	task := models.SubTask{
+		ID:          primitive.NewObjectID(),
+		Title:       req.Title,
+		Description: req.Description,
+		DueDate:     req.DueDate,
+		Tags:        req.Tags,
+		Priority:    req.Priority,
+		Weight:      req.Weight,
+		Estimate:    req.Estimate,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if req.AssigneeID != nil {
+		task.Assign(req.AssigneeID, scope.UserID, now)
+	}
+	goal.SubTasks = append(goal.SubTasks, task)
+	goal.UpdateCompletion(now)
+
+	h.saveGoal(c, models.ActivitySubTaskCreated, &task.ID, before, goal, http.StatusCreated)
+}
+
+// UpdateSubTask handles updating a subtask
+func (h *GoalHandler) UpdateSubTask(c *gin.Context) {
+	subTaskID, err := primitive.ObjectIDFromHex(c.Param("subtaskId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subtask ID"})
+		return
+	}
+
+	var req UpdateSubTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, scope, ok := h.loadGoal(c, models.RoleEditor)
+	if !ok {
+		return
+	}
+	before := goal.Clone()
+
+	task := goal.FindSubTask(subTaskID)
+	if task == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subtask not found"})
+		return
+	}
+
+	var assigneeID *primitive.ObjectID
+	if req.AssigneeID != nil && *req.AssigneeID != "" {
+		id, err := primitive.ObjectIDFromHex(*req.AssigneeID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignee ID"})
+			return
+		}
+		if goal.RoleOf(id) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Assignee must be a member of the goal"})
+			return
+		}
+		assigneeID = &id
+	}
+
+	now := time.Now()
+	if req.Title != "" {
+		task.Title = req.Title
+	}
+	if req.Description != "" {
+		task.Description = req.Description
+	}
+	if req.DueDate != nil {
+		task.DueDate = req.DueDate
+	}
+	if req.Tags != nil {
+		task.Tags = req.Tags
+	}
+	if req.Priority != "" {
+		task.Priority = req.Priority
+	}
+	if req.Weight != nil {
+		task.Weight = req.Weight
+	}
+	if req.Estimate != nil {
+		task.Estimate = req.Estimate
+	}
+	if req.AssigneeID != nil {
+		task.Assign(assigneeID, scope.UserID, now)
+	}
+	if req.Completed != nil && *req.Completed != task.Completed {
+		task.Completed = *req.Completed
+		if task.Completed {
+			task.CompletedAt = &now
+		} else {
+			task.CompletedAt = nil
+		}
+	}
+	task.UpdatedAt = now
+	goal.UpdateCompletion(now)
+
+	h.saveGoal(c, models.ActivitySubTaskUpdated, &subTaskID, before, goal, http.StatusOK)
+}
+
+// DeleteSubTask handles removing a subtask from a goal
+func (h *GoalHandler) DeleteSubTask(c *gin.Context) {
+	subTaskID, err := primitive.ObjectIDFromHex(c.Param("subtaskId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subtask ID"})
+		return
+	}
+
+	goal, _, ok := h.loadGoal(c, models.RoleEditor)
+	if !ok {
+		return
+	}
+	before := goal.Clone()
+
+	if !removeSubTask(goal, subTaskID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subtask not found"})
+		return
+	}
+	goal.UpdateCompletion(time.Now())
+
+	h.saveGoal(c, models.ActivitySubTaskDeleted, &subTaskID, before, goal, http.StatusOK)
+}
+
+// loadGoal fetches the goal named by the :id route parameter and checks
+// that the caller has at least minRole on it, writing the error response and
+// returning false when it can't be loaded
+func (h *GoalHandler) loadGoal(c *gin.Context, minRole string) (*models.Goal, goalScope, bool) {
+	goalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal ID"})
+		return nil, goalScope{}, false
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return nil, goalScope{}, false
+	}
+
+	goal, _, err := findGoalWithRole(context.Background(), h.goalCollection, goalID, scope, minRole)
+	if err != nil {
+		respondGoalAccessError(c, err)
+		return nil, goalScope{}, false
+	}
+
+	return goal, scope, true
+}
+
+// removeSubTask removes a subtask from a goal, along with dependencies on it
+// within the goal. Subtasks of other goals that depend on it are simply no
+// longer blocked by it. It reports whether the subtask was found.
+func removeSubTask(goal *models.Goal, subTaskID primitive.ObjectID) bool {
+	remaining := make([]models.SubTask, 0, len(goal.SubTasks))
+	for _, task := range goal.SubTasks {
+		if task.ID != subTaskID {
+			remaining = append(remaining, task)
+		}
+	}
+	if len(remaining) == len(goal.SubTasks) {
+		return false
+	}
+
+	for i := range remaining {
+		dependsOn := make([]models.Dependency, 0, len(remaining[i].DependsOn))
+		for _, dependency := range remaining[i].DependsOn {
+			if dependency.SubTaskID != subTaskID {
+				dependsOn = append(dependsOn, dependency)
+			}
+		}
+		if len(dependsOn) != len(remaining[i].DependsOn) {
+			remaining[i].DependsOn = dependsOn
+		}
+	}
+
+	goal.SubTasks = remaining
+	return true
+}
+
+// saveGoal writes a modified goal and responds with it. The write only
+// succeeds if the goal hasn't changed since before was read.
+func (h *GoalHandler) saveGoal(c *gin.Context, action string, subTaskID *primitive.ObjectID, before, after *models.Goal, status int) {
+	after.UpdatedAt = time.Now()
+
+	if err := h.replaceGoal(context.Background(), before, after); err != nil {
+		if err == errGoalConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Goal was modified by another request, please retry"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update goal"})
+		}
+		return
+	}
+	h.goalChanged(c, action, subTaskID, before, after)
+
+	// Blocked flags are only computed for the response
+	response := after.Clone()
+	h.markBlocked(context.Background(), response)
+
+	c.JSON(status, response)
+}
+
+// replaceGoal replaces a goal document using its previous updatedAt as an
+// optimistic concurrency check
+func (h *GoalHandler) replaceGoal(ctx context.Context, before, after *models.Goal) error {
+	result, err := h.goalCollection.ReplaceOne(ctx, bson.M{
+		"_id":       before.ID,
+		"updatedAt": before.UpdatedAt,
+	}, after)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errGoalConflict
+	}
+	return nil
+}
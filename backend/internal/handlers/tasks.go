@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"task-management/internal/models"
+)
+
+// Due date buckets, relative to the current day in the user's time zone
+const (
+	bucketOverdue  = "overdue"
+	bucketToday    = "today"
+	bucketUpcoming = "upcoming"
+	bucketNoDate   = "noDate"
+)
+
+// TaskHandler handles the per-user task inbox
+type TaskHandler struct {
+	goalCollection *mongo.Collection
+	userCollection *mongo.Collection
+}
+
+// NewTaskHandler creates a new task handler
+func NewTaskHandler(goalCollection, userCollection *mongo.Collection) *TaskHandler {
+	return &TaskHandler{
+		goalCollection: goalCollection,
+		userCollection: userCollection,
+	}
+}
+
+// AssignedTask is a subtask together with the goal it belongs to
+type AssignedTask struct {
+	models.SubTask
+	GoalID    string `json:"goalId"`
+	GoalTitle string `json:"goalTitle"`
+}
+
+// TaskInbox groups open assigned subtasks by due date
+type TaskInbox struct {
+	Overdue  []AssignedTask `json:"overdue"`
+	Today    []AssignedTask `json:"today"`
+	Upcoming []AssignedTask `json:"upcoming"`
+	NoDate   []AssignedTask `json:"noDate"`
+}
+
+// MyTasks handles listing the open subtasks assigned to the caller across
+// all goals they can access
+func (h *TaskHandler) MyTasks(c *gin.Context) {
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	loc, err := resolveLocation(c, h.userCollection, scope.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := scope.accessibleFilter()
+	filter["subTasks"] = bson.M{"$elemMatch": bson.M{"assigneeId": scope.UserID, "completed": false}}
+
+	cursor, err := h.goalCollection.Find(context.Background(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	var goals []models.Goal
+	if err := cursor.All(context.Background(), &goals); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode tasks"})
+		return
+	}
+
+	now := time.Now().In(loc)
+	inbox := TaskInbox{
+		Overdue:  []AssignedTask{},
+		Today:    []AssignedTask{},
+		Upcoming: []AssignedTask{},
+		NoDate:   []AssignedTask{},
+	}
+	for _, goal := range goals {
+		for _, task := range goal.SubTasks {
+			if task.Completed || task.AssigneeID == nil || *task.AssigneeID != scope.UserID {
+				continue
+			}
+
+			item := AssignedTask{SubTask: task, GoalID: goal.ID.Hex(), GoalTitle: goal.Title}
+			switch dueBucket(task.DueDate, now) {
+			case bucketOverdue:
+				inbox.Overdue = append(inbox.Overdue, item)
+			case bucketToday:
+				inbox.Today = append(inbox.Today, item)
+			case bucketUpcoming:
+				inbox.Upcoming = append(inbox.Upcoming, item)
+			default:
+				inbox.NoDate = append(inbox.NoDate, item)
+			}
+		}
+	}
+
+	for _, tasks := range [][]AssignedTask{inbox.Overdue, inbox.Today, inbox.Upcoming} {
+		sort.SliceStable(tasks, func(i, j int) bool {
+			return tasks[i].DueDate.Before(*tasks[j].DueDate)
+		})
+	}
+	sort.SliceStable(inbox.NoDate, func(i, j int) bool {
+		return inbox.NoDate[i].CreatedAt.Before(inbox.NoDate[j].CreatedAt)
+	})
+
+	c.JSON(http.StatusOK, inbox)
+}
+
+// dueBucket classifies a due date relative to the calendar day of now, which
+// should already be in the user's time zone
+func dueBucket(due *time.Time, now time.Time) string {
+	if due == nil {
+		return bucketNoDate
+	}
+
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	startOfTomorrow := startOfToday.AddDate(0, 0, 1)
+
+	switch {
+	case due.Before(startOfToday):
+		return bucketOverdue
+	case due.Before(startOfTomorrow):
+		return bucketToday
+	default:
+		return bucketUpcoming
+	}
+}
@@ -0,0 +1,400 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/models"
+)
+
+// TemplateHandler handles goal template routes. Goals are created through
+// the goal handler so they are recorded like any other new goal.
+type TemplateHandler struct {
+	templateCollection *mongo.Collection
+	goalCollection     *mongo.Collection
+	userCollection     *mongo.Collection
+	goals              *GoalHandler
+	validator          *validator.Validate
+}
+
+// NewTemplateHandler creates a new template handler
+func NewTemplateHandler(templateCollection, goalCollection, userCollection *mongo.Collection, goals *GoalHandler) *TemplateHandler {
+	return &TemplateHandler{
+		templateCollection: templateCollection,
+		goalCollection:     goalCollection,
+		userCollection:     userCollection,
+		goals:              goals,
+		validator:          validator.New(),
+	}
+}
+
+// TemplateRequest represents the create and update template request.
+// Offsets are in days after the date the template is instantiated for.
+type TemplateRequest struct {
+	Title         string                   `json:"title" validate:"required,max=200"`
+	Description   string                   `json:"description,omitempty"`
+	Tags          []string                 `json:"tags,omitempty"`
+	EstimateUnit  string                   `json:"estimateUnit,omitempty" validate:"omitempty,oneof=points hours"`
+	EndOffsetDays *int                     `json:"endOffsetDays,omitempty"`
+	SubTasks      []models.TemplateSubTask `json:"subTasks" validate:"dive"`
+	Shared        bool                     `json:"shared"`
+}
+
+// InstantiateTemplateRequest represents the instantiate template request.
+// anchorDate is a day in the user's time zone or an RFC 3339 time.
+type InstantiateTemplateRequest struct {
+	AnchorDate string              `json:"anchorDate" validate:"required"`
+	Title      string              `json:"title,omitempty" validate:"max=200"`
+	ParentID   *primitive.ObjectID `json:"parentId,omitempty"`
+}
+
+// SaveAsTemplateRequest represents the save goal as template request. The
+// title defaults to the goal's.
+type SaveAsTemplateRequest struct {
+	Title  string `json:"title,omitempty" validate:"max=200"`
+	Shared bool   `json:"shared"`
+}
+
+// CreateTemplate handles creating a goal template
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	var req TemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+	if !checkTemplateSharing(c, scope, req.Shared) {
+		return
+	}
+
+	now := time.Now()
+	template := models.GoalTemplate{
+		ID:            primitive.NewObjectID(),
+		UserID:        scope.UserID,
+		WorkspaceID:   scope.WorkspaceID,
+		Title:         req.Title,
+		Description:   req.Description,
+		Tags:          req.Tags,
+		EstimateUnit:  req.EstimateUnit,
+		EndOffsetDays: req.EndOffsetDays,
+		SubTasks:      req.SubTasks,
+		Shared:        req.Shared,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if template.SubTasks == nil {
+		template.SubTasks = []models.TemplateSubTask{}
+	}
+
+	if _, err := h.templateCollection.InsertOne(context.Background(), template); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create template"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// ListTemplates handles listing the caller's templates and the templates
+// shared in the active workspace, by title
+func (h *TemplateHandler) ListTemplates(c *gin.Context) {
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	cursor, err := h.templateCollection.Find(context.Background(),
+		templateFilter(scope),
+		options.Find().SetSort(bson.D{{Key: "title", Value: 1}, {Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list templates"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	templates := []models.GoalTemplate{}
+	if err := cursor.All(context.Background(), &templates); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetTemplate handles getting a single template
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	template, _, ok := h.loadTemplate(c, false)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// UpdateTemplate handles replacing a template's contents. Only its creator
+// can change it.
+func (h *TemplateHandler) UpdateTemplate(c *gin.Context) {
+	var req TemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, scope, ok := h.loadTemplate(c, true)
+	if !ok {
+		return
+	}
+	if !checkTemplateSharing(c, scope, req.Shared) {
+		return
+	}
+
+	template.Title = req.Title
+	template.Description = req.Description
+	template.Tags = req.Tags
+	template.EstimateUnit = req.EstimateUnit
+	template.EndOffsetDays = req.EndOffsetDays
+	template.SubTasks = req.SubTasks
+	if template.SubTasks == nil {
+		template.SubTasks = []models.TemplateSubTask{}
+	}
+	template.Shared = req.Shared
+	template.UpdatedAt = time.Now()
+
+	if _, err := h.templateCollection.ReplaceOne(context.Background(), bson.M{"_id": template.ID}, template); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplate handles deleting a template. Only its creator can delete
+// it; goals created from it are kept.
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	template, _, ok := h.loadTemplate(c, true)
+	if !ok {
+		return
+	}
+
+	if _, err := h.templateCollection.DeleteOne(context.Background(), bson.M{"_id": template.ID}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted successfully"})
+}
+
+// InstantiateTemplate handles creating a new goal from a template, with
+// due dates counted from the anchor date
+func (h *TemplateHandler) InstantiateTemplate(c *gin.Context) {
+	var req InstantiateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, scope, ok := h.loadTemplate(c, false)
+	if !ok {
+		return
+	}
+
+	// Guests can only work on goals shared with them
+	if scope.WorkspaceID != nil && !models.WorkspaceRoleAtLeast(scope.WorkspaceRole, models.WorkspaceRoleMember) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Guests can't create goals in this workspace"})
+		return
+	}
+
+	loc, err := resolveLocation(c, h.userCollection, scope.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	anchor, err := time.ParseInLocation(dayLayout, req.AnchorDate, loc)
+	if err != nil {
+		if anchor, err = time.Parse(time.RFC3339, req.AnchorDate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid anchorDate: expected YYYY-MM-DD or RFC 3339"})
+			return
+		}
+	}
+
+	if req.ParentID != nil {
+		if err := h.goals.checkParent(context.Background(), scope, primitive.NilObjectID, *req.ParentID); err != nil {
+			respondParentError(c, err)
+			return
+		}
+	}
+
+	goal := template.Instantiate(anchor, time.Now())
+	goal.UserID = scope.UserID
+	goal.WorkspaceID = scope.WorkspaceID
+	goal.ParentID = req.ParentID
+	if req.Title != "" {
+		goal.Title = req.Title
+	}
+
+	if _, err := h.goalCollection.InsertOne(context.Background(), goal); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create goal"})
+		return
+	}
+	h.goals.goalChanged(c, models.ActivityGoalCreated, nil, nil, &goal)
+
+	c.JSON(http.StatusCreated, goal)
+}
+
+// SaveGoalAsTemplate handles capturing an existing goal's structure as a
+// new template owned by the caller. Due dates become offsets from the
+// goal's start date.
+func (h *TemplateHandler) SaveGoalAsTemplate(c *gin.Context) {
+	goalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal ID"})
+		return
+	}
+
+	var req SaveAsTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+	if !checkTemplateSharing(c, scope, req.Shared) {
+		return
+	}
+
+	goal, _, err := findGoalWithRole(context.Background(), h.goalCollection, goalID, scope, models.RoleViewer)
+	if err != nil {
+		respondGoalAccessError(c, err)
+		return
+	}
+
+	loc, err := resolveLocation(c, h.userCollection, scope.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	template := models.NewTemplateFromGoal(goal, loc)
+	template.ID = primitive.NewObjectID()
+	template.UserID = scope.UserID
+	template.WorkspaceID = scope.WorkspaceID
+	template.Shared = req.Shared
+	template.CreatedAt = now
+	template.UpdatedAt = now
+	if req.Title != "" {
+		template.Title = req.Title
+	}
+
+	if _, err := h.templateCollection.InsertOne(context.Background(), template); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create template"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// loadTemplate loads the template named by the :templateId route parameter
+// from those the caller can see. With owned set, only its creator may
+// proceed.
+func (h *TemplateHandler) loadTemplate(c *gin.Context, owned bool) (*models.GoalTemplate, goalScope, bool) {
+	templateID, err := primitive.ObjectIDFromHex(c.Param("templateId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return nil, goalScope{}, false
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return nil, goalScope{}, false
+	}
+
+	filter := templateFilter(scope)
+	filter["_id"] = templateID
+
+	var template models.GoalTemplate
+	if err := h.templateCollection.FindOne(context.Background(), filter).Decode(&template); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get template"})
+		}
+		return nil, goalScope{}, false
+	}
+
+	if owned && template.UserID != scope.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the template's creator can change it"})
+		return nil, goalScope{}, false
+	}
+
+	return &template, scope, true
+}
+
+// templateFilter matches the templates the caller can see: their own in the
+// active workspace and, in a workspace, those shared in it
+func templateFilter(scope goalScope) bson.M {
+	filter := bson.M{"workspaceId": scope.tenantValue()}
+	if scope.WorkspaceID == nil {
+		filter["userId"] = scope.UserID
+	} else {
+		filter["$or"] = bson.A{
+			bson.M{"userId": scope.UserID},
+			bson.M{"shared": true},
+		}
+	}
+	return filter
+}
+
+// checkTemplateSharing rejects sharing templates outside a workspace,
+// where there is no one to share them with
+func checkTemplateSharing(c *gin.Context, scope goalScope, shared bool) bool {
+	if shared && scope.WorkspaceID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only workspace templates can be shared"})
+		return false
+	}
+	return true
+}
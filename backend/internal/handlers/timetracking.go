@@ -0,0 +1,552 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/models"
+	"task-management/internal/timesheet"
+)
+
+// defaultReportDays is the length of a time report without a from date
+const defaultReportDays = 7
+
+// TimeHandler handles timer and time entry routes
+type TimeHandler struct {
+	entryCollection *mongo.Collection
+	timerCollection *mongo.Collection
+	goalCollection  *mongo.Collection
+	userCollection  *mongo.Collection
+	validator       *validator.Validate
+}
+
+// NewTimeHandler creates a new time tracking handler
+func NewTimeHandler(entryCollection, timerCollection, goalCollection, userCollection *mongo.Collection) *TimeHandler {
+	return &TimeHandler{
+		entryCollection: entryCollection,
+		timerCollection: timerCollection,
+		goalCollection:  goalCollection,
+		userCollection:  userCollection,
+		validator:       validator.New(),
+	}
+}
+
+// StartTimerRequest represents the start timer request
+type StartTimerRequest struct {
+	GoalID    primitive.ObjectID `json:"goalId"`
+	SubTaskID primitive.ObjectID `json:"subTaskId"`
+	Note      string             `json:"note,omitempty" validate:"max=2000"`
+}
+
+// StopTimerRequest represents the stop timer request. A note replaces the
+// one given when the timer was started.
+type StopTimerRequest struct {
+	Note string `json:"note,omitempty" validate:"max=2000"`
+}
+
+// timeReport holds the time entries of a report range together with their
+// goals. to is exclusive.
+type timeReport struct {
+	entries []models.TimeEntry
+	goals   map[primitive.ObjectID]*models.Goal
+	loc     *time.Location
+	from    time.Time
+	to      time.Time
+}
+
+// TimeEntryRequest represents the create and update time entry requests
+type TimeEntryRequest struct {
+	GoalID    primitive.ObjectID  `json:"goalId"`
+	SubTaskID *primitive.ObjectID `json:"subTaskId,omitempty"`
+	StartedAt time.Time           `json:"startedAt"`
+	EndedAt   time.Time           `json:"endedAt"`
+	Note      string              `json:"note,omitempty" validate:"max=2000"`
+}
+
+// StartTimer handles starting a timer on a subtask. Users can only run one
+// timer at a time.
+func (h *TimeHandler) StartTimer(c *gin.Context) {
+	var req StartTimerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	goal, ok := h.loadTrackedGoal(c, scope, req.GoalID, &req.SubTaskID)
+	if !ok {
+		return
+	}
+
+	timer := models.Timer{
+		UserID:      scope.UserID,
+		WorkspaceID: goal.WorkspaceID,
+		GoalID:      goal.ID,
+		SubTaskID:   req.SubTaskID,
+		Note:        req.Note,
+		StartedAt:   time.Now(),
+	}
+
+	// The timer's ID is the user's, so a second running timer is a duplicate
+	if _, err := h.timerCollection.InsertOne(context.Background(), timer); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A timer is already running; stop it first"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start timer"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, timer)
+}
+
+// StopTimer handles stopping the caller's running timer, which is saved as
+// a time entry
+func (h *TimeHandler) StopTimer(c *gin.Context) {
+	var req StopTimerRequest
+	// The body is optional
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	var timer models.Timer
+	err := h.timerCollection.FindOneAndDelete(context.Background(), bson.M{"_id": userID.(primitive.ObjectID)}).Decode(&timer)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No timer is running"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stop timer"})
+		}
+		return
+	}
+
+	now := time.Now()
+	entry := models.TimeEntry{
+		ID:          primitive.NewObjectID(),
+		UserID:      timer.UserID,
+		WorkspaceID: timer.WorkspaceID,
+		GoalID:      timer.GoalID,
+		SubTaskID:   &timer.SubTaskID,
+		Note:        timer.Note,
+		Source:      models.TimeSourceTimer,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if req.Note != "" {
+		entry.Note = req.Note
+	}
+	entry.SetSpan(timer.StartedAt, now)
+
+	if _, err := h.entryCollection.InsertOne(context.Background(), entry); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save time entry"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// GetTimer handles getting the caller's running timer, which is null when
+// none is running
+func (h *TimeHandler) GetTimer(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	var timer models.Timer
+	err := h.timerCollection.FindOne(context.Background(), bson.M{"_id": userID.(primitive.ObjectID)}).Decode(&timer)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusOK, gin.H{"timer": nil})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timer"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"timer": timer, "seconds": int64(time.Since(timer.StartedAt) / time.Second)})
+}
+
+// CreateTimeEntry handles logging time by hand
+func (h *TimeHandler) CreateTimeEntry(c *gin.Context) {
+	var req TimeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.validEntryRequest(c, req) {
+		return
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	goal, ok := h.loadTrackedGoal(c, scope, req.GoalID, req.SubTaskID)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	entry := models.TimeEntry{
+		ID:          primitive.NewObjectID(),
+		UserID:      scope.UserID,
+		WorkspaceID: goal.WorkspaceID,
+		GoalID:      goal.ID,
+		SubTaskID:   req.SubTaskID,
+		Note:        req.Note,
+		Source:      models.TimeSourceManual,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	entry.SetSpan(req.StartedAt, req.EndedAt)
+
+	if _, err := h.entryCollection.InsertOne(context.Background(), entry); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save time entry"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// UpdateTimeEntry handles changing one of the caller's time entries
+func (h *TimeHandler) UpdateTimeEntry(c *gin.Context) {
+	var req TimeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.validEntryRequest(c, req) {
+		return
+	}
+
+	entry, scope, ok := h.loadEntry(c)
+	if !ok {
+		return
+	}
+
+	goal, ok := h.loadTrackedGoal(c, scope, req.GoalID, req.SubTaskID)
+	if !ok {
+		return
+	}
+
+	entry.WorkspaceID = goal.WorkspaceID
+	entry.GoalID = goal.ID
+	entry.SubTaskID = req.SubTaskID
+	entry.Note = req.Note
+	entry.SetSpan(req.StartedAt, req.EndedAt)
+	entry.UpdatedAt = time.Now()
+
+	if _, err := h.entryCollection.ReplaceOne(context.Background(), bson.M{"_id": entry.ID}, entry); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update time entry"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteTimeEntry handles deleting one of the caller's time entries
+func (h *TimeHandler) DeleteTimeEntry(c *gin.Context) {
+	entry, _, ok := h.loadEntry(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.entryCollection.DeleteOne(context.Background(), bson.M{"_id": entry.ID}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete time entry"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Time entry deleted successfully"})
+}
+
+// ListTimeEntries handles listing the caller's time entries in the active
+// workspace, newest first. goalId narrows the list to one goal.
+func (h *TimeHandler) ListTimeEntries(c *gin.Context) {
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	filter := bson.M{"userId": scope.UserID, "workspaceId": scope.tenantValue()}
+	if value := c.Query("goalId"); value != "" {
+		goalID, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal ID"})
+			return
+		}
+		filter["goalId"] = goalID
+	}
+
+	limit, err := pageQuery(c, filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cursor, err := h.entryCollection.Find(context.Background(), filter,
+		options.Find().SetSort(bson.M{"_id": -1}).SetLimit(limit),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch time entries"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	entries := []models.TimeEntry{}
+	if err := cursor.All(context.Background(), &entries); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode time entries"})
+		return
+	}
+
+	// The next page starts before the last entry
+	var next string
+	if int64(len(entries)) == limit {
+		next = entries[len(entries)-1].ID.Hex()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": entries, "next": next})
+}
+
+// GetTimeReport handles summing the caller's time per goal, tag and day
+func (h *TimeHandler) GetTimeReport(c *gin.Context) {
+	report, ok := h.loadTimeReport(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":   report.from.Format(dayLayout),
+		"to":     report.to.AddDate(0, 0, -1).Format(dayLayout),
+		"report": timesheet.Summarize(report.entries, report.goals, report.loc),
+	})
+}
+
+// ExportTimesheet handles downloading the caller's time entries as CSV
+func (h *TimeHandler) ExportTimesheet(c *gin.Context) {
+	report, ok := h.loadTimeReport(c)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("timesheet-%s-%s.csv", report.from.Format("20060102"), report.to.AddDate(0, 0, -1).Format("20060102"))
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	// Headers are already sent, so failures past this point can only be logged
+	if err := timesheet.WriteCSV(c.Writer, report.entries, report.goals, report.loc); err != nil {
+		c.Error(err)
+	}
+}
+
+// loadTimeReport loads the caller's time entries in the active workspace
+// that started within the from and to days, inclusive, in the user's time
+// zone. The range defaults to the last seven days. It writes the error
+// response and returns false when the entries can't be loaded.
+func (h *TimeHandler) loadTimeReport(c *gin.Context) (*timeReport, bool) {
+	fail := func(status int, message string) (*timeReport, bool) {
+		c.JSON(status, gin.H{"error": message})
+		return nil, false
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return nil, false
+	}
+
+	loc, err := resolveLocation(c, h.userCollection, scope.UserID)
+	if err != nil {
+		return fail(http.StatusBadRequest, err.Error())
+	}
+
+	now := time.Now().In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc)
+	if value := c.Query("to"); value != "" {
+		day, err := time.ParseInLocation(dayLayout, value, loc)
+		if err != nil {
+			return fail(http.StatusBadRequest, "invalid to date: expected YYYY-MM-DD")
+		}
+		to = day.AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -defaultReportDays)
+	if value := c.Query("from"); value != "" {
+		day, err := time.ParseInLocation(dayLayout, value, loc)
+		if err != nil {
+			return fail(http.StatusBadRequest, "invalid from date: expected YYYY-MM-DD")
+		}
+		from = day
+	}
+	if !from.Before(to) {
+		return fail(http.StatusBadRequest, "from must not be after to")
+	}
+
+	cursor, err := h.entryCollection.Find(context.Background(),
+		bson.M{
+			"userId":      scope.UserID,
+			"workspaceId": scope.tenantValue(),
+			"startedAt":   bson.M{"$gte": from, "$lt": to},
+		},
+		options.Find().SetSort(bson.M{"startedAt": 1}),
+	)
+	if err != nil {
+		return fail(http.StatusInternalServerError, "Failed to fetch time entries")
+	}
+	defer cursor.Close(context.Background())
+
+	var entries []models.TimeEntry
+	if err := cursor.All(context.Background(), &entries); err != nil {
+		return fail(http.StatusInternalServerError, "Failed to decode time entries")
+	}
+
+	// Titles and tags come from the goals as they are now
+	goalIDs := []primitive.ObjectID{}
+	seen := make(map[primitive.ObjectID]bool)
+	for _, entry := range entries {
+		if !seen[entry.GoalID] {
+			seen[entry.GoalID] = true
+			goalIDs = append(goalIDs, entry.GoalID)
+		}
+	}
+	goals := make(map[primitive.ObjectID]*models.Goal, len(goalIDs))
+	if len(goalIDs) > 0 {
+		cursor, err := h.goalCollection.Find(context.Background(), bson.M{"_id": bson.M{"$in": goalIDs}})
+		if err != nil {
+			return fail(http.StatusInternalServerError, "Failed to fetch goals")
+		}
+		defer cursor.Close(context.Background())
+
+		var found []models.Goal
+		if err := cursor.All(context.Background(), &found); err != nil {
+			return fail(http.StatusInternalServerError, "Failed to decode goals")
+		}
+		for i := range found {
+			goals[found[i].ID] = &found[i]
+		}
+	}
+
+	return &timeReport{entries: entries, goals: goals, loc: loc, from: from, to: to}, true
+}
+
+// validEntryRequest validates a time entry request, writing the error
+// response and returning false when it is invalid
+func (h *TimeHandler) validEntryRequest(c *gin.Context, req TimeEntryRequest) bool {
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if req.StartedAt.IsZero() || req.EndedAt.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "startedAt and endedAt are required"})
+		return false
+	}
+	if !req.EndedAt.After(req.StartedAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "endedAt must be after startedAt"})
+		return false
+	}
+	if req.EndedAt.After(time.Now().Add(time.Minute)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Time can't be logged in the future"})
+		return false
+	}
+	return true
+}
+
+// loadTrackedGoal loads a goal the caller can edit and checks that the
+// subtask, if given, belongs to it, writing the error response and returning
+// false when it can't
+func (h *TimeHandler) loadTrackedGoal(c *gin.Context, scope goalScope, goalID primitive.ObjectID, subTaskID *primitive.ObjectID) (*models.Goal, bool) {
+	if goalID.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "goalId is required"})
+		return nil, false
+	}
+	if subTaskID != nil && subTaskID.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "subTaskId is required"})
+		return nil, false
+	}
+
+	goal, _, err := findGoalWithRole(context.Background(), h.goalCollection, goalID, scope, models.RoleEditor)
+	if err != nil {
+		respondGoalAccessError(c, err)
+		return nil, false
+	}
+	if subTaskID != nil && goal.FindSubTask(*subTaskID) == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subtask not found"})
+		return nil, false
+	}
+
+	return goal, true
+}
+
+// loadEntry fetches the caller's time entry named by the :entryId route
+// parameter, writing the error response and returning false when it can't
+func (h *TimeHandler) loadEntry(c *gin.Context) (*models.TimeEntry, goalScope, bool) {
+	entryID, err := primitive.ObjectIDFromHex(c.Param("entryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time entry ID"})
+		return nil, goalScope{}, false
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return nil, goalScope{}, false
+	}
+
+	var entry models.TimeEntry
+	err = h.entryCollection.FindOne(context.Background(), bson.M{
+		"_id":         entryID,
+		"userId":      scope.UserID,
+		"workspaceId": scope.tenantValue(),
+	}).Decode(&entry)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Time entry not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get time entry"})
+		}
+		return nil, goalScope{}, false
+	}
+
+	return &entry, scope, true
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/models"
+)
+
+// resolveLocation returns the time zone for a request: the tz query
+// parameter when given, otherwise the user's saved time zone
+func resolveLocation(c *gin.Context, userCollection *mongo.Collection, userID primitive.ObjectID) (*time.Location, error) {
+	if tz := c.Query("tz"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("unknown time zone %q", tz)
+		}
+		return loc, nil
+	}
+
+	var user models.User
+	err := userCollection.FindOne(context.Background(),
+		bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"timezone": 1}),
+	).Decode(&user)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	return user.Location(), nil
+}
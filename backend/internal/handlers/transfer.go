@@ -0,0 +1,479 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/importers"
+	"task-management/internal/models"
+	"task-management/internal/transfer"
+)
+
+// Duplicate handling strategies for imports
+const (
+	DuplicateSkip   = "skip"
+	DuplicateUpsert = "upsert"
+)
+
+// maxImportSize bounds the size of an uploaded import file
+const maxImportSize = 32 << 20
+
+// TransferHandler handles goal import and export routes
+type TransferHandler struct {
+	goalCollection *mongo.Collection
+	goals          *GoalHandler
+}
+
+// NewTransferHandler creates a new transfer handler. Imported goals are
+// written through goals so they get the same bookkeeping as other changes.
+func NewTransferHandler(goalCollection *mongo.Collection, goals *GoalHandler) *TransferHandler {
+	return &TransferHandler{
+		goalCollection: goalCollection,
+		goals:          goals,
+	}
+}
+
+// ImportOptions controls how import records are written
+type ImportOptions struct {
+	DryRun      bool
+	OnDuplicate string
+}
+
+// ImportItem describes what happened to a single imported goal
+type ImportItem struct {
+	Row      int    `json:"row"`
+	SourceID string `json:"sourceId,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Action   string `json:"action"`
+}
+
+// ImportResult represents the import response
+type ImportResult struct {
+	DryRun  bool                `json:"dryRun"`
+	Created int                 `json:"created"`
+	Updated int                 `json:"updated"`
+	Skipped int                 `json:"skipped"`
+	Failed  int                 `json:"failed"`
+	Items   []ImportItem        `json:"items"`
+	Errors  []transfer.RowError `json:"errors"`
+	IDMap   map[string]string   `json:"idMap"`
+}
+
+// Export streams all of the user's goals and subtasks
+func (h *TransferHandler) Export(c *gin.Context) {
+	format, err := transfer.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	cursor, err := h.goalCollection.Find(context.Background(),
+		scope.ownedFilter(),
+		options.Find().SetSort(bson.M{"createdAt": 1}),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export goals"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	filename := fmt.Sprintf("goals-%s.%s", time.Now().Format("20060102"), format.Extension())
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	// Headers are already sent, so failures past this point can only be logged
+	writer := transfer.NewWriter(format, c.Writer)
+	for cursor.Next(context.Background()) {
+		var goal models.Goal
+		if err := cursor.Decode(&goal); err != nil {
+			c.Error(err)
+			return
+		}
+		if err := writer.Write(&goal); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		c.Error(err)
+		return
+	}
+	if err := writer.Close(); err != nil {
+		c.Error(err)
+	}
+}
+
+// Import reads goals from an uploaded file
+func (h *TransferHandler) Import(c *gin.Context) {
+	format, err := transfer.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts, err := parseImportOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	// Guests can only work on goals shared with them
+	if scope.WorkspaceID != nil && !models.WorkspaceRoleAtLeast(scope.WorkspaceRole, models.WorkspaceRoleMember) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Guests can't import goals into this workspace"})
+		return
+	}
+
+	body, closeBody, err := importBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer closeBody()
+
+	records, rowErrs, err := transfer.Decode(format, body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "errors": rowErrs})
+		return
+	}
+
+	result := h.importRecords(c, scope, records, opts)
+	result.Errors = append(rowErrs, result.Errors...)
+	result.Failed += len(rowErrs)
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ImportFromSource imports an export file from another tool
+func (h *TransferHandler) ImportFromSource(c *gin.Context) {
+	importer, err := importers.Lookup(c.Param("source"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts, err := parseImportOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	// Guests can only work on goals shared with them
+	if scope.WorkspaceID != nil && !models.WorkspaceRoleAtLeast(scope.WorkspaceRole, models.WorkspaceRoleMember) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Guests can't import goals into this workspace"})
+		return
+	}
+
+	body, closeBody, err := importBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer closeBody()
+
+	records, report, err := importer(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := h.importRecords(c, scope, records, opts)
+
+	c.JSON(http.StatusOK, gin.H{
+		"report": report,
+		"result": result,
+	})
+}
+
+// parseImportOptions reads the dryRun and onDuplicate query parameters
+func parseImportOptions(c *gin.Context) (ImportOptions, error) {
+	opts := ImportOptions{OnDuplicate: DuplicateSkip}
+
+	if value := c.Query("dryRun"); value != "" {
+		dryRun, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, fmt.Errorf("dryRun must be true or false")
+		}
+		opts.DryRun = dryRun
+	}
+
+	switch value := c.Query("onDuplicate"); value {
+	case "", DuplicateSkip:
+	case DuplicateUpsert:
+		opts.OnDuplicate = DuplicateUpsert
+	default:
+		return opts, fmt.Errorf("onDuplicate must be %s or %s", DuplicateSkip, DuplicateUpsert)
+	}
+
+	return opts, nil
+}
+
+// importBody returns the uploaded file, accepting either a multipart "file"
+// field or the raw request body
+func importBody(c *gin.Context) (io.Reader, func(), error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	if c.ContentType() == "multipart/form-data" {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, nil, fmt.Errorf("file is required")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read uploaded file")
+		}
+		return file, func() { file.Close() }, nil
+	}
+
+	return c.Request.Body, func() {}, nil
+}
+
+// importRecords validates the records and writes them for the user. Goals and
+// subtasks always receive new IDs unless they update an existing goal; the
+// mapping from source ID to stored ID is returned in the result.
+func (h *TransferHandler) importRecords(c *gin.Context, scope goalScope, records []transfer.Record, opts ImportOptions) ImportResult {
+	ctx := context.Background()
+	result := ImportResult{
+		DryRun: opts.DryRun,
+		Items:  []ImportItem{},
+		Errors: []transfer.RowError{},
+		IDMap:  map[string]string{},
+	}
+
+	seen := make(map[string]bool)
+	for _, rec := range records {
+		if errs := transfer.Validate(rec); len(errs) > 0 {
+			result.Errors = append(result.Errors, errs...)
+			result.Failed++
+			continue
+		}
+
+		if rec.Goal.ID != "" {
+			if seen[rec.Goal.ID] {
+				result.Errors = append(result.Errors, transfer.RowError{Row: rec.Row, Field: "id", Message: "duplicate goal id " + rec.Goal.ID})
+				result.Failed++
+				continue
+			}
+			seen[rec.Goal.ID] = true
+		}
+
+		existing, err := h.findExisting(ctx, scope, rec.Goal.ID)
+		if err != nil {
+			result.Errors = append(result.Errors, transfer.RowError{Row: rec.Row, Message: "Failed to check for duplicates"})
+			result.Failed++
+			continue
+		}
+
+		item := ImportItem{Row: rec.Row, SourceID: rec.Goal.ID}
+		if existing != nil && opts.OnDuplicate == DuplicateSkip {
+			item.ID = existing.ID.Hex()
+			item.Action = "skipped"
+			result.Items = append(result.Items, item)
+			result.Skipped++
+			continue
+		}
+
+		if existing != nil {
+			goal := existing.Clone()
+			mergeImportedGoal(goal, rec.Goal, result.IDMap, time.Now())
+			item.ID = goal.ID.Hex()
+
+			if !opts.DryRun {
+				if err := h.goals.replaceGoal(ctx, existing, goal); err != nil {
+					message := "Failed to save goal"
+					if err == errGoalConflict {
+						message = "Goal was modified by another request, please retry"
+					}
+					result.Errors = append(result.Errors, transfer.RowError{Row: rec.Row, Message: message})
+					result.Failed++
+					continue
+				}
+				h.goals.goalChanged(c, models.ActivityGoalUpdated, nil, existing, goal)
+			}
+
+			item.Action = "updated"
+			result.Updated++
+		} else {
+			goal := buildImportedGoal(scope, rec.Goal, result.IDMap)
+			item.ID = goal.ID.Hex()
+
+			if !opts.DryRun {
+				if _, err := h.goalCollection.InsertOne(ctx, goal); err != nil {
+					result.Errors = append(result.Errors, transfer.RowError{Row: rec.Row, Message: "Failed to save goal"})
+					result.Failed++
+					continue
+				}
+				h.goals.goalChanged(c, models.ActivityGoalCreated, nil, nil, &goal)
+			}
+
+			item.Action = "created"
+			result.Created++
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	return result
+}
+
+// findExisting returns the user's goal in scope with the given source ID, if any
+func (h *TransferHandler) findExisting(ctx context.Context, scope goalScope, sourceID string) (*models.Goal, error) {
+	id, err := primitive.ObjectIDFromHex(sourceID)
+	if err != nil {
+		return nil, nil
+	}
+
+	filter := scope.ownedFilter()
+	filter["_id"] = id
+
+	var goal models.Goal
+	err = h.goalCollection.FindOne(ctx, filter).Decode(&goal)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+// buildImportedGoal converts a record into a new goal, recording remapped
+// IDs in idMap
+func buildImportedGoal(scope goalScope, rec transfer.GoalRecord, idMap map[string]string) models.Goal {
+	now := time.Now()
+
+	goal := models.Goal{
+		ID:          primitive.NewObjectID(),
+		UserID:      scope.UserID,
+		WorkspaceID: scope.WorkspaceID,
+		Title:       rec.Title,
+		Description: rec.Description,
+		SubTasks:    []models.SubTask{},
+		Tags:        rec.Tags,
+		EndDate:     rec.EndDate,
+		Completed:   rec.Completed,
+		CompletedAt: completionTime(rec.Completed, rec.CompletedAt, now),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if rec.StartDate != nil {
+		goal.StartDate = *rec.StartDate
+	}
+	if rec.CreatedAt != nil {
+		goal.CreatedAt = *rec.CreatedAt
+	}
+	if rec.ID != "" {
+		idMap[rec.ID] = goal.ID.Hex()
+	}
+
+	for _, t := range rec.SubTasks {
+		task := models.SubTask{ID: primitive.NewObjectID(), CreatedAt: now}
+		applyImportedSubTask(&task, t, now)
+		if t.CreatedAt != nil {
+			task.CreatedAt = *t.CreatedAt
+		}
+		if t.ID != "" {
+			idMap[t.ID] = task.ID.Hex()
+		}
+		goal.SubTasks = append(goal.SubTasks, task)
+	}
+
+	goal.CalculateProgress()
+	return goal
+}
+
+// mergeImportedGoal copies the fields a record carries onto an existing
+// goal. Everything the import format has no column for, such as members,
+// priority, archiving, the hierarchy, key results and habits, is kept.
+// Subtasks are matched by ID and updated in place, keeping their
+// assignments, weights, estimates and dependencies; unmatched record
+// subtasks are added and subtasks missing from the record are kept.
+func mergeImportedGoal(goal *models.Goal, rec transfer.GoalRecord, idMap map[string]string, now time.Time) {
+	goal.Title = rec.Title
+	goal.Description = rec.Description
+	goal.Tags = rec.Tags
+	goal.EndDate = rec.EndDate
+	if rec.StartDate != nil {
+		goal.StartDate = *rec.StartDate
+	}
+	if rec.Completed != goal.Completed || rec.CompletedAt != nil {
+		goal.CompletedAt = completionTime(rec.Completed, rec.CompletedAt, now)
+	}
+	goal.Completed = rec.Completed
+	goal.UpdatedAt = now
+	if rec.ID != "" {
+		idMap[rec.ID] = goal.ID.Hex()
+	}
+
+	for _, t := range rec.SubTasks {
+		var task *models.SubTask
+		if id, err := primitive.ObjectIDFromHex(t.ID); err == nil {
+			task = goal.FindSubTask(id)
+		}
+		if task == nil {
+			goal.SubTasks = append(goal.SubTasks, models.SubTask{ID: primitive.NewObjectID(), CreatedAt: now})
+			task = &goal.SubTasks[len(goal.SubTasks)-1]
+			if t.CreatedAt != nil {
+				task.CreatedAt = *t.CreatedAt
+			}
+		}
+		applyImportedSubTask(task, t, now)
+		if t.ID != "" {
+			idMap[t.ID] = task.ID.Hex()
+		}
+	}
+
+	goal.CalculateProgress()
+}
+
+// applyImportedSubTask copies the fields a subtask record carries onto task
+func applyImportedSubTask(task *models.SubTask, t transfer.SubTaskRecord, now time.Time) {
+	task.Title = t.Title
+	task.Description = t.Description
+	if t.Completed != task.Completed || t.CompletedAt != nil {
+		task.CompletedAt = completionTime(t.Completed, t.CompletedAt, now)
+	}
+	task.Completed = t.Completed
+	task.DueDate = t.DueDate
+	task.Tags = t.Tags
+	task.UpdatedAt = now
+}
+
+// completionTime keeps an imported completion date, falling back to the
+// import time for completed items that don't carry one
+func completionTime(completed bool, completedAt *time.Time, now time.Time) *time.Time {
+	if !completed {
+		return nil
+	}
+	if completedAt != nil {
+		return completedAt
+	}
+	return &now
+}
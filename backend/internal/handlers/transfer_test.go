@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"task-management/internal/models"
+	"task-management/internal/transfer"
+)
+
+func TestMergeImportedGoal(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	created := now.AddDate(0, -1, 0)
+	start := now.AddDate(0, 0, -7)
+	doneEarlier := now.AddDate(0, 0, -2)
+	memberID := primitive.NewObjectID()
+	weight := 3.0
+
+	kept := models.SubTask{ID: primitive.NewObjectID(), Title: "Not in the file", CreatedAt: created}
+	updated := models.SubTask{
+		ID:          primitive.NewObjectID(),
+		Title:       "Old title",
+		Completed:   true,
+		CompletedAt: &doneEarlier,
+		Priority:    models.PriorityHigh,
+		Weight:      &weight,
+		AssigneeID:  &memberID,
+		DependsOn:   []models.Dependency{{GoalID: primitive.NewObjectID(), SubTaskID: primitive.NewObjectID()}},
+		CreatedAt:   created,
+	}
+	goal := models.Goal{
+		ID:        primitive.NewObjectID(),
+		Title:     "Old goal",
+		Priority:  models.PriorityHigh,
+		Members:   []models.GoalMember{{UserID: memberID, Role: models.RoleEditor}},
+		Tags:      []string{"old"},
+		StartDate: start,
+		SubTasks:  []models.SubTask{kept, updated},
+		CreatedAt: created,
+	}
+
+	rec := transfer.GoalRecord{
+		ID:    "source-goal",
+		Title: "New goal",
+		Tags:  []string{"new"},
+		SubTasks: []transfer.SubTaskRecord{
+			{ID: updated.ID.Hex(), Title: "New title", Completed: true},
+			{ID: "source-task", Title: "Added", Completed: true},
+		},
+	}
+	idMap := map[string]string{}
+	mergeImportedGoal(&goal, rec, idMap, now)
+
+	if goal.Title != "New goal" || !reflect.DeepEqual(goal.Tags, []string{"new"}) {
+		t.Errorf("title, tags = %q, %v, want the record's", goal.Title, goal.Tags)
+	}
+	if !goal.StartDate.Equal(start) || goal.EndDate != nil {
+		t.Errorf("dates = %v, %v, want the start kept and no end date", goal.StartDate, goal.EndDate)
+	}
+	if goal.Priority != models.PriorityHigh || len(goal.Members) != 1 || !goal.CreatedAt.Equal(created) || !goal.UpdatedAt.Equal(now) {
+		t.Errorf("fields the format doesn't carry were changed: %+v", goal)
+	}
+
+	if len(goal.SubTasks) != 3 {
+		t.Fatalf("got %d subtasks, want 3", len(goal.SubTasks))
+	}
+	if !reflect.DeepEqual(goal.SubTasks[0], kept) {
+		t.Errorf("subtask missing from the record = %+v, want it unchanged", goal.SubTasks[0])
+	}
+
+	task := goal.SubTasks[1]
+	if task.ID != updated.ID || task.Title != "New title" {
+		t.Errorf("matched subtask = %+v, want it updated in place", task)
+	}
+	if task.CompletedAt == nil || !task.CompletedAt.Equal(doneEarlier) {
+		t.Errorf("completedAt = %v, want the earlier completion kept", task.CompletedAt)
+	}
+	if task.Priority != updated.Priority || task.Weight != updated.Weight || task.AssigneeID != updated.AssigneeID || !reflect.DeepEqual(task.DependsOn, updated.DependsOn) {
+		t.Errorf("matched subtask lost fields the format doesn't carry: %+v", task)
+	}
+
+	added := goal.SubTasks[2]
+	if added.ID.IsZero() || added.Title != "Added" || !added.CreatedAt.Equal(now) {
+		t.Errorf("added subtask = %+v", added)
+	}
+	if added.CompletedAt == nil || !added.CompletedAt.Equal(now) {
+		t.Errorf("added completedAt = %v, want the import time", added.CompletedAt)
+	}
+
+	wantIDs := map[string]string{
+		"source-goal":    goal.ID.Hex(),
+		updated.ID.Hex(): updated.ID.Hex(),
+		"source-task":    added.ID.Hex(),
+	}
+	if !reflect.DeepEqual(idMap, wantIDs) {
+		t.Errorf("idMap = %v, want %v", idMap, wantIDs)
+	}
+
+	// Weighted: 3 of 5 for the matched subtask, 1 for the added one
+	if want := 80.0; goal.Progress != want {
+		t.Errorf("progress = %v, want %v", goal.Progress, want)
+	}
+}
+
+func TestMergeImportedGoalCompletion(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	earlier := now.AddDate(0, 0, -3)
+	given := now.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name        string
+		completedAt *time.Time
+		rec         transfer.GoalRecord
+		want        *time.Time
+	}{
+		{name: "completed on import", rec: transfer.GoalRecord{Completed: true}, want: &now},
+		{name: "completion date from the file", rec: transfer.GoalRecord{Completed: true, CompletedAt: &given}, want: &given},
+		{name: "still completed", completedAt: &earlier, rec: transfer.GoalRecord{Completed: true}, want: &earlier},
+		{name: "reopened", completedAt: &earlier, rec: transfer.GoalRecord{}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goal := models.Goal{ID: primitive.NewObjectID(), Completed: tt.completedAt != nil, CompletedAt: tt.completedAt}
+			tt.rec.Title = "Goal"
+			mergeImportedGoal(&goal, tt.rec, map[string]string{}, now)
+
+			if goal.Completed != tt.rec.Completed {
+				t.Errorf("completed = %v, want %v", goal.Completed, tt.rec.Completed)
+			}
+			if (goal.CompletedAt == nil) != (tt.want == nil) || (tt.want != nil && !goal.CompletedAt.Equal(*tt.want)) {
+				t.Errorf("completedAt = %v, want %v", goal.CompletedAt, tt.want)
+			}
+		})
+	}
+}
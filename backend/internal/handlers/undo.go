@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"task-management/internal/models"
+	"task-management/internal/undo"
+)
+
+// undoKey identifies a user's undo stacks. Each workspace has its own, so
+// undo only touches goals in the active workspace.
+func undoKey(scope goalScope) string {
+	return scope.UserID.Hex() + "|" + scope.key()
+}
+
+// ListUndo handles listing the changes that can be undone and redone
+func (h *GoalHandler) ListUndo(c *gin.Context) {
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	undoable, redoable := h.undo.List(undoKey(scope))
+	c.JSON(http.StatusOK, gin.H{"undo": undoable, "redo": redoable})
+}
+
+// Undo handles reverting the caller's most recent goal change
+func (h *GoalHandler) Undo(c *gin.Context) {
+	h.reverse(c, models.ActivityGoalUndone, "Nothing to undo", h.undo.Undo)
+}
+
+// Redo handles reapplying the caller's most recently undone change
+func (h *GoalHandler) Redo(c *gin.Context) {
+	h.reverse(c, models.ActivityGoalRedone, "Nothing to redo", h.undo.Redo)
+}
+
+// reverse runs an undo or redo and writes the response
+func (h *GoalHandler) reverse(c *gin.Context, action, emptyMessage string, run func(string, undo.ApplyFunc) (undo.Change, *models.Goal, error)) {
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	change, goal, err := run(undoKey(scope), func(from, to *models.Goal) (*models.Goal, error) {
+		return h.applyChange(c, scope, action, from, to)
+	})
+	if err != nil {
+		switch err {
+		case undo.ErrEmpty:
+			c.JSON(http.StatusNotFound, gin.H{"error": emptyMessage})
+		case undo.ErrConflict:
+			c.JSON(http.StatusConflict, gin.H{"error": "The goal has changed since, so this change can't be reversed", "goalId": change.GoalID})
+		case errForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to do this"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reverse change"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"action": change.Action, "goalId": change.GoalID, "goal": goal})
+}
+
+// applyChange moves a goal from state from to state to, refusing with
+// undo.ErrConflict when the goal no longer matches from. Sharing is managed
+// separately, so the current members are kept.
+func (h *GoalHandler) applyChange(c *gin.Context, scope goalScope, action string, from, to *models.Goal) (*models.Goal, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	// The goal was deleted: it must still be gone, then it is recreated
+	if from == nil {
+		restored := to.Clone()
+		restored.UpdatedAt = now
+		if _, err := h.goalCollection.InsertOne(ctx, restored); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return nil, undo.ErrConflict
+			}
+			return nil, err
+		}
+		h.goalChanged(c, action, nil, nil, restored)
+		return restored, nil
+	}
+
+	minRole := models.RoleEditor
+	if to == nil {
+		minRole = models.RoleOwner
+	}
+	current, _, err := findGoalWithRole(ctx, h.goalCollection, from.ID, scope, minRole)
+	if err != nil {
+		if err == errGoalNotFound {
+			return nil, undo.ErrConflict
+		}
+		return nil, err
+	}
+
+	// The goal was created: it is deleted again. Like the update below, the
+	// write only matches while the goal is still in state from, and goals
+	// placed below it since block the delete as they would a direct one.
+	if to == nil {
+		children, err := h.goalCollection.CountDocuments(ctx, bson.M{"parentId": from.ID})
+		if err != nil {
+			return nil, err
+		}
+		if children > 0 {
+			return nil, undo.ErrConflict
+		}
+
+		result, err := h.goalCollection.DeleteOne(ctx, bson.M{"_id": from.ID, "updatedAt": from.UpdatedAt})
+		if err != nil {
+			return nil, err
+		}
+		if result.DeletedCount == 0 {
+			return nil, undo.ErrConflict
+		}
+		h.goalChanged(c, action, nil, current, nil)
+		return nil, nil
+	}
+
+	// Other goals may have moved since, so the old parent is checked again
+	if to.ParentID != nil && (from.ParentID == nil || *from.ParentID != *to.ParentID) {
+		if err := h.checkNoCycle(ctx, from.ID, *to.ParentID); err != nil {
+			if err == errGoalCycle {
+				return nil, undo.ErrConflict
+			}
+			return nil, err
+		}
+	}
+
+	updated := to.Clone()
+	updated.Members = current.Members
+	updated.UpdatedAt = now
+	if err := h.replaceGoal(ctx, from, updated); err != nil {
+		if err == errGoalConflict {
+			return nil, undo.ErrConflict
+		}
+		return nil, err
+	}
+	h.goalChanged(c, action, nil, current, updated)
+	return updated, nil
+}
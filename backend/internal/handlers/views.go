@@ -0,0 +1,501 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/models"
+	"task-management/internal/query"
+)
+
+// upcomingDays is how many days after today the upcoming view covers
+const upcomingDays = 7
+
+// ViewHandler handles smart views and saved filters
+type ViewHandler struct {
+	filterCollection *mongo.Collection
+	goalCollection   *mongo.Collection
+	userCollection   *mongo.Collection
+	validator        *validator.Validate
+}
+
+// NewViewHandler creates a new view handler
+func NewViewHandler(filterCollection, goalCollection, userCollection *mongo.Collection) *ViewHandler {
+	return &ViewHandler{
+		filterCollection: filterCollection,
+		goalCollection:   goalCollection,
+		userCollection:   userCollection,
+		validator:        validator.New(),
+	}
+}
+
+// SavedFilterRequest represents the create and update saved filter request
+type SavedFilterRequest struct {
+	Name   string            `json:"name" validate:"required,max=100"`
+	Filter models.ViewFilter `json:"filter"`
+	Pinned bool              `json:"pinned"`
+}
+
+// ViewItem is a goal or subtask listed in a view. Goals are listed by their
+// end date.
+type ViewItem struct {
+	Kind       string              `json:"kind"`
+	GoalID     string              `json:"goalId"`
+	GoalTitle  string              `json:"goalTitle"`
+	SubTaskID  string              `json:"subTaskId,omitempty"`
+	Title      string              `json:"title"`
+	DueDate    *time.Time          `json:"dueDate,omitempty"`
+	Priority   string              `json:"priority,omitempty"`
+	Tags       []string            `json:"tags,omitempty"`
+	Completed  bool                `json:"completed"`
+	AssigneeID *primitive.ObjectID `json:"assigneeId,omitempty"`
+	CreatedAt  time.Time           `json:"createdAt"`
+}
+
+// ViewResult is the result of running a smart view or saved filter
+type ViewResult struct {
+	View        string              `json:"view,omitempty"`
+	SavedFilter *models.SavedFilter `json:"savedFilter,omitempty"`
+	Items       []ViewItem          `json:"items"`
+}
+
+// GetView handles listing the open goals and subtasks in a smart view:
+// today, upcoming (the next 7 days), overdue or undated
+func (h *ViewHandler) GetView(c *gin.Context) {
+	view := c.Param("view")
+	switch view {
+	case models.ViewToday, models.ViewUpcoming, models.ViewOverdue, models.ViewUndated:
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown view: expected today, upcoming, overdue or undated"})
+		return
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	items, ok := h.runView(c, scope, models.ViewFilter{Due: view})
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, ViewResult{View: view, Items: items})
+}
+
+// CreateSavedFilter handles saving a named filter
+func (h *ViewHandler) CreateSavedFilter(c *gin.Context) {
+	var req SavedFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := parseGoalQuery(c, req.Filter.Query); !ok {
+		return
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	filter := models.SavedFilter{
+		ID:          primitive.NewObjectID(),
+		UserID:      scope.UserID,
+		WorkspaceID: scope.WorkspaceID,
+		Name:        req.Name,
+		Filter:      req.Filter,
+		Pinned:      req.Pinned,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if _, err := h.filterCollection.InsertOne(context.Background(), filter); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save filter"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, filter)
+}
+
+// ListSavedFilters handles listing the caller's saved filters in the active
+// workspace, pinned ones first
+func (h *ViewHandler) ListSavedFilters(c *gin.Context) {
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+
+	cursor, err := h.filterCollection.Find(context.Background(),
+		savedFilterScope(scope),
+		options.Find().SetSort(bson.D{{Key: "pinned", Value: -1}, {Key: "name", Value: 1}, {Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list saved filters"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	filters := []models.SavedFilter{}
+	if err := cursor.All(context.Background(), &filters); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode saved filters"})
+		return
+	}
+
+	c.JSON(http.StatusOK, filters)
+}
+
+// GetSavedFilter handles getting a single saved filter
+func (h *ViewHandler) GetSavedFilter(c *gin.Context) {
+	filter, _, ok := h.loadSavedFilter(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, filter)
+}
+
+// UpdateSavedFilter handles replacing a saved filter's name, definition and
+// pinned flag
+func (h *ViewHandler) UpdateSavedFilter(c *gin.Context) {
+	var req SavedFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := parseGoalQuery(c, req.Filter.Query); !ok {
+		return
+	}
+
+	filter, _, ok := h.loadSavedFilter(c)
+	if !ok {
+		return
+	}
+
+	filter.Name = req.Name
+	filter.Filter = req.Filter
+	filter.Pinned = req.Pinned
+	filter.UpdatedAt = time.Now()
+
+	if _, err := h.filterCollection.ReplaceOne(context.Background(), bson.M{"_id": filter.ID}, filter); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update saved filter"})
+		return
+	}
+
+	c.JSON(http.StatusOK, filter)
+}
+
+// DeleteSavedFilter handles deleting a saved filter
+func (h *ViewHandler) DeleteSavedFilter(c *gin.Context) {
+	filter, _, ok := h.loadSavedFilter(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.filterCollection.DeleteOne(context.Background(), bson.M{"_id": filter.ID}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete saved filter"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Saved filter deleted successfully"})
+}
+
+// RunSavedFilter handles listing the goals and subtasks a saved filter
+// matches now
+func (h *ViewHandler) RunSavedFilter(c *gin.Context) {
+	filter, scope, ok := h.loadSavedFilter(c)
+	if !ok {
+		return
+	}
+
+	items, ok := h.runView(c, scope, filter.Filter)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, ViewResult{SavedFilter: filter, Items: items})
+}
+
+// loadSavedFilter loads the caller's saved filter named by the :filterId
+// route parameter
+func (h *ViewHandler) loadSavedFilter(c *gin.Context) (*models.SavedFilter, goalScope, bool) {
+	filterID, err := primitive.ObjectIDFromHex(c.Param("filterId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved filter ID"})
+		return nil, goalScope{}, false
+	}
+
+	// Get the caller and active workspace from context
+	scope, ok := requestScope(c)
+	if !ok {
+		return nil, goalScope{}, false
+	}
+
+	lookup := savedFilterScope(scope)
+	lookup["_id"] = filterID
+
+	var filter models.SavedFilter
+	if err := h.filterCollection.FindOne(context.Background(), lookup).Decode(&filter); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Saved filter not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get saved filter"})
+		}
+		return nil, goalScope{}, false
+	}
+
+	return &filter, scope, true
+}
+
+// savedFilterScope matches the caller's saved filters in the active
+// workspace
+func savedFilterScope(scope goalScope) bson.M {
+	return bson.M{"userId": scope.UserID, "workspaceId": scope.tenantValue()}
+}
+
+// runView lists the goals and subtasks the caller can access that match
+// filter, from goals matching its query, with due dates compared in the
+// user's time zone. Archived goals and habit goals are left out; subtasks
+// of habit goals can't exist. Writes an error response and returns false
+// on failure.
+func (h *ViewHandler) runView(c *gin.Context, scope goalScope, filter models.ViewFilter) ([]ViewItem, bool) {
+	loc, err := resolveLocation(c, h.userCollection, scope.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	now := time.Now().In(loc)
+
+	goalFilter := scope.accessibleFilter()
+	goalFilter["archived"] = bson.M{"$ne": true}
+	conditions := bson.A{}
+	if dates := viewDateRange(filter.Due, now); dates != nil {
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"endDate": dates},
+			bson.M{"subTasks": bson.M{"$elemMatch": bson.M{"dueDate": dates}}},
+		}})
+	}
+	// The query is matched per item below: a subtask can match even when its
+	// goal doesn't, so it can't narrow down the goals fetched
+	var expr query.Expr
+	if filter.Query != "" {
+		var ok bool
+		if expr, ok = parseGoalQuery(c, filter.Query); !ok {
+			return nil, false
+		}
+	}
+	env := query.Env{Now: now, UserID: scope.UserID}
+	if len(conditions) > 0 {
+		goalFilter["$and"] = conditions
+	}
+
+	cursor, err := h.goalCollection.Find(context.Background(), goalFilter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch goals"})
+		return nil, false
+	}
+	defer cursor.Close(context.Background())
+
+	var goals []models.Goal
+	if err := cursor.All(context.Background(), &goals); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode goals"})
+		return nil, false
+	}
+
+	items := []ViewItem{}
+	for _, goal := range goals {
+		if goal.Type != models.GoalTypeHabit {
+			item := ViewItem{
+				Kind:      models.ViewItemGoal,
+				GoalID:    goal.ID.Hex(),
+				GoalTitle: goal.Title,
+				Title:     goal.Title,
+				DueDate:   goal.EndDate,
+				Priority:  goal.Priority,
+				Tags:      goal.Tags,
+				Completed: goal.Completed,
+				CreatedAt: goal.CreatedAt,
+			}
+			if viewMatches(filter, item, scope.UserID, now) && (expr == nil || expr.Match(&goal, env)) {
+				items = append(items, item)
+			}
+		}
+
+		for _, task := range goal.SubTasks {
+			item := ViewItem{
+				Kind:       models.ViewItemSubTask,
+				GoalID:     goal.ID.Hex(),
+				GoalTitle:  goal.Title,
+				SubTaskID:  task.ID.Hex(),
+				Title:      task.Title,
+				DueDate:    task.DueDate,
+				Priority:   task.Priority,
+				Tags:       task.Tags,
+				Completed:  task.Completed,
+				AssigneeID: task.AssigneeID,
+				CreatedAt:  task.CreatedAt,
+			}
+			if viewMatches(filter, item, scope.UserID, now) && (expr == nil || expr.Match(subTaskAsGoal(&goal, task), env)) {
+				items = append(items, item)
+			}
+		}
+	}
+
+	// Dated items first, soonest first; undated ones oldest first
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if (a.DueDate == nil) != (b.DueDate == nil) {
+			return a.DueDate != nil
+		}
+		if a.DueDate != nil && !a.DueDate.Equal(*b.DueDate) {
+			return a.DueDate.Before(*b.DueDate)
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+
+	return items, true
+}
+
+// viewMatches reports whether an item belongs in a view. Overdue items are
+// always open; with AssignedToMe only subtasks assigned to the caller match.
+func viewMatches(filter models.ViewFilter, item ViewItem, userID primitive.ObjectID, now time.Time) bool {
+	if item.Completed && (!filter.IncludeCompleted || filter.Due == models.ViewOverdue) {
+		return false
+	}
+	if filter.Kind != "" && item.Kind != filter.Kind {
+		return false
+	}
+	if filter.AssignedToMe && (item.AssigneeID == nil || *item.AssigneeID != userID) {
+		return false
+	}
+	if len(filter.Priorities) > 0 && !containsString(filter.Priorities, item.Priority) {
+		return false
+	}
+	if len(filter.Tags) > 0 && !hasAnyTag(item.Tags, filter.Tags) {
+		return false
+	}
+	if filter.Search != "" && !strings.Contains(strings.ToLower(item.Title), strings.ToLower(filter.Search)) {
+		return false
+	}
+
+	bucket := dueBucket(item.DueDate, now)
+	switch filter.Due {
+	case models.ViewToday:
+		return bucket == bucketToday
+	case models.ViewUpcoming:
+		return bucket == bucketUpcoming && item.DueDate.Before(upcomingEnd(now))
+	case models.ViewOverdue:
+		return bucket == bucketOverdue
+	case models.ViewUndated:
+		return bucket == bucketNoDate
+	}
+	return true
+}
+
+// viewDateRange returns the due date range of a view for narrowing the
+// goal query, or nil when it has none
+func viewDateRange(view string, now time.Time) bson.M {
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	startOfTomorrow := startOfToday.AddDate(0, 0, 1)
+
+	switch view {
+	case models.ViewToday:
+		return bson.M{"$gte": startOfToday, "$lt": startOfTomorrow}
+	case models.ViewUpcoming:
+		return bson.M{"$gte": startOfTomorrow, "$lt": upcomingEnd(now)}
+	case models.ViewOverdue:
+		return bson.M{"$lt": startOfToday}
+	}
+	return nil
+}
+
+// upcomingEnd is the end of the upcoming view: the start of the day
+// upcomingDays after today
+func upcomingEnd(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day()+1+upcomingDays, 0, 0, 0, 0, now.Location())
+}
+
+// hasAnyTag reports whether tags contains any of wanted
+func hasAnyTag(tags, wanted []string) bool {
+	for _, tag := range wanted {
+		if containsString(tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// subTaskAsGoal returns a goal standing in for a subtask when matching a
+// filter query: goal fields take the subtask's values and task fields only
+// see the subtask itself
+func subTaskAsGoal(goal *models.Goal, task models.SubTask) *models.Goal {
+	progress := 0.0
+	if task.Completed {
+		progress = 100
+	}
+	return &models.Goal{
+		ID:          goal.ID,
+		Type:        goal.Type,
+		Title:       task.Title,
+		SubTasks:    []models.SubTask{task},
+		Tags:        task.Tags,
+		Priority:    task.Priority,
+		Progress:    progress,
+		EndDate:     task.DueDate,
+		StartDate:   goal.StartDate,
+		Completed:   task.Completed,
+		CompletedAt: task.CompletedAt,
+		CreatedAt:   task.CreatedAt,
+	}
+}
+
+// parseGoalQuery parses a goal filter query, writing an error response with
+// the position of the problem and returning false when it is invalid
+func parseGoalQuery(c *gin.Context, text string) (query.Expr, bool) {
+	expr, err := query.Parse(text)
+	if err != nil {
+		if queryErr, ok := err.(*query.Error); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query: " + queryErr.Error(), "position": queryErr.Pos})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query: " + err.Error()})
+		}
+		return nil, false
+	}
+	return expr, true
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
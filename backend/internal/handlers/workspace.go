@@ -0,0 +1,511 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/models"
+)
+
+// WorkspaceHandler handles workspace and workspace membership routes
+type WorkspaceHandler struct {
+	workspaceCollection  *mongo.Collection
+	userCollection       *mongo.Collection
+	goalCollection       *mongo.Collection
+	invitationCollection *mongo.Collection
+	goals                *GoalHandler
+	validator            *validator.Validate
+}
+
+// NewWorkspaceHandler creates a new workspace handler. Invitations share
+// their collection with goal invitations and are accepted the same way.
+// Goal changes made when members leave are written through goals.
+func NewWorkspaceHandler(workspaceCollection, userCollection, goalCollection, invitationCollection *mongo.Collection, goals *GoalHandler) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceCollection:  workspaceCollection,
+		userCollection:       userCollection,
+		goalCollection:       goalCollection,
+		invitationCollection: invitationCollection,
+		goals:                goals,
+		validator:            validator.New(),
+	}
+}
+
+// WorkspaceRequest represents the create and rename workspace request
+type WorkspaceRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// InviteWorkspaceMemberRequest represents the invite workspace member request
+type InviteWorkspaceMemberRequest struct {
+	Email    string `json:"email,omitempty" validate:"required_without=Username,omitempty,email"`
+	Username string `json:"username,omitempty" validate:"required_without=Email"`
+	Role     string `json:"role" validate:"required,oneof=admin member guest"`
+}
+
+// UpdateWorkspaceMemberRequest represents the change workspace role request
+type UpdateWorkspaceMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin member guest"`
+}
+
+// TransferOwnershipRequest represents the transfer workspace ownership request
+type TransferOwnershipRequest struct {
+	UserID primitive.ObjectID `json:"userId" validate:"required"`
+}
+
+// CreateWorkspace handles creating a workspace owned by the caller
+func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	var req WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	now := time.Now()
+	workspace := models.Workspace{
+		ID:      primitive.NewObjectID(),
+		Name:    strings.TrimSpace(req.Name),
+		OwnerID: userID.(primitive.ObjectID),
+		Members: []models.WorkspaceMember{{
+			UserID:   userID.(primitive.ObjectID),
+			Role:     models.WorkspaceRoleOwner,
+			JoinedAt: now,
+		}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if _, err := h.workspaceCollection.InsertOne(context.Background(), workspace); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create workspace"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, workspace)
+}
+
+// ListWorkspaces handles listing the workspaces the caller belongs to
+func (h *WorkspaceHandler) ListWorkspaces(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	cursor, err := h.workspaceCollection.Find(context.Background(),
+		bson.M{"members.userId": userID.(primitive.ObjectID)},
+		options.Find().SetSort(bson.M{"name": 1}),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list workspaces"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	workspaces := []models.Workspace{}
+	if err := cursor.All(context.Background(), &workspaces); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode workspaces"})
+		return
+	}
+
+	c.JSON(http.StatusOK, workspaces)
+}
+
+// GetWorkspace handles getting the active workspace
+func (h *WorkspaceHandler) GetWorkspace(c *gin.Context) {
+	workspace, _, ok := h.loadWorkspace(c, models.WorkspaceRoleGuest)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+// UpdateWorkspace handles renaming a workspace
+func (h *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
+	var req WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspace, _, ok := h.loadWorkspace(c, models.WorkspaceRoleAdmin)
+	if !ok {
+		return
+	}
+
+	workspace.Name = strings.TrimSpace(req.Name)
+	h.saveWorkspace(c, workspace, http.StatusOK)
+}
+
+// InviteMember handles inviting a user to a workspace by email or username.
+// The user joins once they accept; emails that aren't registered yet can
+// accept after signing up.
+func (h *WorkspaceHandler) InviteMember(c *gin.Context) {
+	var req InviteWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspace, userID, ok := h.loadWorkspace(c, models.WorkspaceRoleAdmin)
+	if !ok {
+		return
+	}
+
+	invitation := models.Invitation{
+		ID:            primitive.NewObjectID(),
+		WorkspaceID:   &workspace.ID,
+		WorkspaceName: workspace.Name,
+		InviterID:     userID,
+		Role:          req.Role,
+		Status:        models.InvitationPending,
+		CreatedAt:     time.Now(),
+	}
+
+	// Resolve the invitee; unknown emails are kept so they can accept after registering
+	lookup := bson.M{"username": req.Username}
+	if req.Email != "" {
+		invitation.Email = strings.ToLower(req.Email)
+		lookup = bson.M{"email": emailFilter(invitation.Email)}
+	}
+
+	var invitee models.User
+	err := h.userCollection.FindOne(context.Background(), lookup).Decode(&invitee)
+	switch {
+	case err == nil:
+		if workspace.RoleOf(invitee.ID) != "" {
+			c.JSON(http.StatusConflict, gin.H{"error": "User is already a member of this workspace"})
+			return
+		}
+		invitation.InviteeID = &invitee.ID
+		invitation.Email = strings.ToLower(invitee.Email)
+	case err == mongo.ErrNoDocuments && req.Email == "":
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	case err != mongo.ErrNoDocuments:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up user"})
+		return
+	}
+
+	// Only one pending invitation per workspace and invitee
+	count, err := h.invitationCollection.CountDocuments(context.Background(), bson.M{
+		"workspaceId": workspace.ID,
+		"email":       invitation.Email,
+		"status":      models.InvitationPending,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "An invitation is already pending for this user"})
+		return
+	}
+
+	if _, err := h.invitationCollection.InsertOne(context.Background(), invitation); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// ListInvitations handles listing the pending invitations to a workspace
+func (h *WorkspaceHandler) ListInvitations(c *gin.Context) {
+	workspace, _, ok := h.loadWorkspace(c, models.WorkspaceRoleAdmin)
+	if !ok {
+		return
+	}
+
+	respondInvitations(c, h.invitationCollection, bson.M{"workspaceId": workspace.ID, "status": models.InvitationPending})
+}
+
+// RevokeInvitation handles cancelling a pending invitation to a workspace
+func (h *WorkspaceHandler) RevokeInvitation(c *gin.Context) {
+	invitationID, err := primitive.ObjectIDFromHex(c.Param("invitationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	workspace, _, ok := h.loadWorkspace(c, models.WorkspaceRoleAdmin)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	result, err := h.invitationCollection.UpdateOne(context.Background(),
+		bson.M{"_id": invitationID, "workspaceId": workspace.ID, "status": models.InvitationPending},
+		bson.M{"$set": bson.M{"status": models.InvitationRevoked, "respondedAt": now}},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// UpdateMember handles changing a workspace member's role. The owner's role
+// only changes by transferring ownership.
+func (h *WorkspaceHandler) UpdateMember(c *gin.Context) {
+	memberID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req UpdateWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspace, _, ok := h.loadWorkspace(c, models.WorkspaceRoleAdmin)
+	if !ok {
+		return
+	}
+
+	if memberID == workspace.OwnerID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Transfer ownership to change the owner's role"})
+		return
+	}
+
+	found := false
+	for i := range workspace.Members {
+		if workspace.Members[i].UserID == memberID {
+			workspace.Members[i].Role = req.Role
+			found = true
+		}
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	h.saveWorkspace(c, workspace, http.StatusOK)
+}
+
+// RemoveMember handles removing a member from a workspace. Admins can remove
+// anyone but the owner and members can remove themselves to leave. The
+// member also loses access to the workspace goals shared with them, which is
+// done first so that a failed request can be retried.
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	memberID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	workspace, userID, ok := h.loadWorkspace(c, models.WorkspaceRoleGuest)
+	if !ok {
+		return
+	}
+
+	if memberID == workspace.OwnerID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The owner can't be removed from a workspace"})
+		return
+	}
+	if memberID != userID && !models.WorkspaceRoleAtLeast(workspace.RoleOf(userID), models.WorkspaceRoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to do this"})
+		return
+	}
+
+	members := make([]models.WorkspaceMember, 0, len(workspace.Members))
+	for _, member := range workspace.Members {
+		if member.UserID != memberID {
+			members = append(members, member)
+		}
+	}
+	if len(members) == len(workspace.Members) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+	workspace.Members = members
+
+	if !h.removeFromGoals(c, workspace.ID, memberID, userID) {
+		return
+	}
+	h.saveWorkspace(c, workspace, http.StatusOK)
+}
+
+// removeFromGoals takes a member off the workspace goals shared with them
+// and unassigns their subtasks there. It writes the error response and
+// returns false when a goal can't be updated; goals already updated stay
+// that way.
+func (h *WorkspaceHandler) removeFromGoals(c *gin.Context, workspaceID, memberID, by primitive.ObjectID) bool {
+	ctx := context.Background()
+	cursor, err := h.goalCollection.Find(ctx, bson.M{"workspaceId": workspaceID, "members.userId": memberID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load workspace goals"})
+		return false
+	}
+	defer cursor.Close(ctx)
+
+	var goals []models.Goal
+	if err := cursor.All(ctx, &goals); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load workspace goals"})
+		return false
+	}
+
+	now := time.Now()
+	for i := range goals {
+		before := &goals[i]
+		after := before.Clone()
+		if !after.RemoveMember(memberID, by, now) {
+			continue
+		}
+		after.UpdatedAt = now
+
+		if err := h.goals.replaceGoal(ctx, before, after); err != nil {
+			if err == errGoalConflict {
+				c.JSON(http.StatusConflict, gin.H{"error": "Goal was modified by another request, please retry"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update members"})
+			}
+			return false
+		}
+		h.goals.goalChanged(c, models.ActivityMemberRemoved, nil, before, after)
+	}
+	return true
+}
+
+// TransferOwnership handles handing a workspace to another member. The
+// previous owner stays on as an admin.
+func (h *WorkspaceHandler) TransferOwnership(c *gin.Context) {
+	var req TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspace, userID, ok := h.loadWorkspace(c, models.WorkspaceRoleOwner)
+	if !ok {
+		return
+	}
+
+	if req.UserID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You already own this workspace"})
+		return
+	}
+	if workspace.RoleOf(req.UserID) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The new owner must be a member of the workspace"})
+		return
+	}
+
+	for i := range workspace.Members {
+		switch workspace.Members[i].UserID {
+		case req.UserID:
+			workspace.Members[i].Role = models.WorkspaceRoleOwner
+		case userID:
+			workspace.Members[i].Role = models.WorkspaceRoleAdmin
+		}
+	}
+	workspace.OwnerID = req.UserID
+
+	h.saveWorkspace(c, workspace, http.StatusOK)
+}
+
+// loadWorkspace fetches the workspace resolved by AuthRequired and checks
+// that the caller's workspace role grants at least minRole
+func (h *WorkspaceHandler) loadWorkspace(c *gin.Context, minRole string) (*models.Workspace, primitive.ObjectID, bool) {
+	scope, ok := requestScope(c)
+	if !ok {
+		return nil, primitive.NilObjectID, false
+	}
+	if scope.WorkspaceID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Workspace ID is required"})
+		return nil, primitive.NilObjectID, false
+	}
+
+	var workspace models.Workspace
+	if err := h.workspaceCollection.FindOne(context.Background(), bson.M{"_id": *scope.WorkspaceID}).Decode(&workspace); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+			return nil, primitive.NilObjectID, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get workspace"})
+		return nil, primitive.NilObjectID, false
+	}
+
+	if !models.WorkspaceRoleAtLeast(workspace.RoleOf(scope.UserID), minRole) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to do this"})
+		return nil, primitive.NilObjectID, false
+	}
+
+	return &workspace, scope.UserID, true
+}
+
+// saveWorkspace writes a modified workspace back, failing with a conflict if
+// it changed since it was loaded, and responds with it on success
+func (h *WorkspaceHandler) saveWorkspace(c *gin.Context, workspace *models.Workspace, status int) bool {
+	previous := workspace.UpdatedAt
+	workspace.UpdatedAt = time.Now()
+
+	result, err := h.workspaceCollection.ReplaceOne(context.Background(),
+		bson.M{"_id": workspace.ID, "updatedAt": previous},
+		workspace,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workspace"})
+		return false
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Workspace was modified by another request, please retry"})
+		return false
+	}
+
+	c.JSON(status, workspace)
+	return true
+}
@@ -0,0 +1,142 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/models"
+)
+
+// CollectionName is the collection progress history is stored in
+const CollectionName = "progress_history"
+
+// dayLayout identifies the day a snapshot belongs to
+const dayLayout = "2006-01-02"
+
+// Recorder writes progress history entries
+type Recorder struct {
+	collection *mongo.Collection
+}
+
+// NewRecorder creates a new progress recorder
+func NewRecorder(collection *mongo.Collection) *Recorder {
+	return &Recorder{
+		collection: collection,
+	}
+}
+
+// Record stores a history entry when a goal's progress or completion has
+// changed between before and after. A nil before means the goal was just
+// created or brought back; a nil after means it was deleted, in which case
+// its history is only marked so a restored goal keeps its burndown.
+func (r *Recorder) Record(ctx context.Context, before, after *models.Goal) error {
+	if r == nil {
+		return nil
+	}
+
+	if after == nil {
+		if before == nil {
+			return nil
+		}
+		_, err := r.collection.UpdateMany(ctx, bson.M{"goalId": before.ID}, bson.M{"$set": bson.M{"goalDeletedAt": time.Now()}})
+		return err
+	}
+
+	if before == nil {
+		if _, err := r.collection.UpdateMany(ctx,
+			bson.M{"goalId": after.ID, "goalDeletedAt": bson.M{"$exists": true}},
+			bson.M{"$unset": bson.M{"goalDeletedAt": ""}},
+		); err != nil {
+			return err
+		}
+	}
+
+	entry := models.NewProgressEntry(after, models.ProgressSourceChange, time.Now())
+	if before != nil && entry.SameProgress(models.NewProgressEntry(before, models.ProgressSourceChange, entry.RecordedAt)) {
+		return nil
+	}
+
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+// PruneDeleted drops the history of goals deleted before cutoff, once they
+// can no longer be restored
+func (r *Recorder) PruneDeleted(ctx context.Context, cutoff time.Time) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"goalDeletedAt": bson.M{"$lt": cutoff}})
+	return err
+}
+
+// SnapshotAll records the daily snapshot of every open goal. Snapshots are
+// keyed by UTC day, so running it more than once a day is harmless.
+func (r *Recorder) SnapshotAll(ctx context.Context, goalCollection *mongo.Collection, now time.Time) error {
+	cursor, err := goalCollection.Find(ctx, bson.M{"completed": false})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	day := now.UTC().Format(dayLayout)
+	for cursor.Next(ctx) {
+		var goal models.Goal
+		if err := cursor.Decode(&goal); err != nil {
+			return err
+		}
+
+		entry := models.NewProgressEntry(&goal, models.ProgressSourceSnapshot, now)
+		entry.Day = day
+
+		_, err := r.collection.UpdateOne(ctx,
+			bson.M{"goalId": goal.ID, "source": models.ProgressSourceSnapshot, "day": day},
+			bson.M{"$setOnInsert": entry},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// Entries returns the history needed to chart the range starting at from:
+// the latest entry per goal before from, followed by every entry after it
+func (r *Recorder) Entries(ctx context.Context, filter bson.M, from time.Time) ([]models.ProgressEntry, error) {
+	baselineFilter := bson.M{"recordedAt": bson.M{"$lt": from}}
+	rangeFilter := bson.M{"recordedAt": bson.M{"$gte": from}}
+	for key, value := range filter {
+		baselineFilter[key] = value
+		rangeFilter[key] = value
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: baselineFilter}},
+		{{Key: "$sort", Value: bson.M{"recordedAt": 1}}},
+		{{Key: "$group", Value: bson.M{"_id": "$goalId", "entry": bson.M{"$last": "$$ROOT"}}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$entry"}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []models.ProgressEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	cursor, err = r.collection.Find(ctx, rangeFilter, options.Find().SetSort(bson.M{"recordedAt": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	var recent []models.ProgressEntry
+	if err := cursor.All(ctx, &recent); err != nil {
+		return nil, err
+	}
+
+	return append(entries, recent...), nil
+}
@@ -0,0 +1,118 @@
+package history
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"task-management/internal/models"
+)
+
+// Point is one day of a burndown/burnup series
+type Point struct {
+	Date      string  `json:"date"`
+	Total     int     `json:"total"`
+	Completed int     `json:"completed"`
+	Remaining int     `json:"remaining"`
+	Progress  float64 `json:"progress"`
+}
+
+// Projection estimates when the remaining work will be finished
+type Projection struct {
+	VelocityPerDay      float64    `json:"velocityPerDay"`
+	Remaining           int        `json:"remaining"`
+	ProjectedCompletion *time.Time `json:"projectedCompletion,omitempty"`
+	EndDate             *time.Time `json:"endDate,omitempty"`
+	OnTrack             *bool      `json:"onTrack,omitempty"`
+	// Days between the projected completion and the end date; negative when late
+	SlackDays *int `json:"slackDays,omitempty"`
+}
+
+// BuildSeries turns history entries into one point per day from from to to,
+// carrying each goal's latest state forward across days without entries
+func BuildSeries(entries []models.ProgressEntry, from, to time.Time, loc *time.Location) []Point {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].RecordedAt.Before(entries[j].RecordedAt)
+	})
+
+	latest := make(map[primitive.ObjectID]models.ProgressEntry)
+	var series []Point
+
+	next := 0
+	day := startOfDay(from, loc)
+	for !day.After(to) {
+		dayEnd := day.AddDate(0, 0, 1)
+		for next < len(entries) && entries[next].RecordedAt.Before(dayEnd) {
+			latest[entries[next].GoalID] = entries[next]
+			next++
+		}
+
+		point := Point{Date: day.Format(dayLayout)}
+		var progress float64
+		for _, entry := range latest {
+			point.Total += entry.TotalTasks
+			point.Completed += entry.CompletedTasks
+			progress += entry.Progress
+		}
+		point.Remaining = point.Total - point.Completed
+		if len(latest) > 0 {
+			point.Progress = progress / float64(len(latest))
+		}
+		series = append(series, point)
+
+		day = dayEnd
+	}
+
+	return series
+}
+
+// Project estimates a completion date from the completion velocity over the
+// last window days of the series and compares it with the end date
+func Project(series []Point, window int, endDate *time.Time, now time.Time, loc *time.Location) Projection {
+	projection := Projection{EndDate: endDate}
+	if len(series) == 0 {
+		return projection
+	}
+
+	last := series[len(series)-1]
+	projection.Remaining = last.Remaining
+
+	if window > len(series)-1 {
+		window = len(series) - 1
+	}
+	if window > 0 {
+		first := series[len(series)-1-window]
+		projection.VelocityPerDay = float64(last.Completed-first.Completed) / float64(window)
+	}
+
+	today := startOfDay(now, loc)
+	switch {
+	case last.Remaining <= 0:
+		projection.ProjectedCompletion = &today
+	case projection.VelocityPerDay > 0:
+		days := int(math.Ceil(float64(last.Remaining) / projection.VelocityPerDay))
+		projected := today.AddDate(0, 0, days)
+		projection.ProjectedCompletion = &projected
+	}
+
+	if projection.ProjectedCompletion != nil && endDate != nil {
+		end := startOfDay(*endDate, loc)
+		onTrack := !projection.ProjectedCompletion.After(end)
+		slack := int(math.Round(end.Sub(*projection.ProjectedCompletion).Hours() / 24))
+		projection.OnTrack = &onTrack
+		projection.SlackDays = &slack
+	} else if endDate != nil && last.Remaining > 0 {
+		// No velocity yet, so the goal can't be expected to finish on time
+		onTrack := false
+		projection.OnTrack = &onTrack
+	}
+
+	return projection
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
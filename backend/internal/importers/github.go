@@ -0,0 +1,151 @@
+package importers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"task-management/internal/transfer"
+)
+
+// githubMilestone accepts both the REST API and `gh --json` field names
+type githubMilestone struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	State       string `json:"state"`
+	DueOn       string `json:"due_on"`
+	DueOnCamel  string `json:"dueOn"`
+	CreatedAt   string `json:"created_at"`
+	ClosedAt    string `json:"closed_at"`
+}
+
+type githubIssue struct {
+	Number       int              `json:"number"`
+	Title        string           `json:"title"`
+	Body         string           `json:"body"`
+	State        string           `json:"state"`
+	CreatedAt    string           `json:"created_at"`
+	CreatedCamel string           `json:"createdAt"`
+	ClosedAt     string           `json:"closed_at"`
+	ClosedCamel  string           `json:"closedAt"`
+	Milestone    *githubMilestone `json:"milestone"`
+	PullRequest  json.RawMessage  `json:"pull_request"`
+	Labels       []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+}
+
+// githubExport is either a bare list of issues or an object that also
+// carries the repository's milestones
+type githubExport struct {
+	Milestones []githubMilestone `json:"milestones"`
+	Issues     []githubIssue     `json:"issues"`
+}
+
+// noMilestoneKey groups issues that don't belong to a milestone
+const noMilestoneKey = "none"
+
+// ImportGitHub maps GitHub milestones to goals and their issues to subtasks.
+// Issues without a milestone are collected into a single goal.
+func ImportGitHub(r io.Reader) ([]transfer.Record, Report, error) {
+	report := Report{Source: SourceGitHub, Unmapped: []UnmappedItem{}}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, report, fmt.Errorf("failed to read GitHub export: %w", err)
+	}
+
+	var export githubExport
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &export.Issues)
+	} else {
+		err = json.Unmarshal(trimmed, &export)
+	}
+	if err != nil {
+		return nil, report, fmt.Errorf("invalid GitHub export: %w", err)
+	}
+
+	var records []transfer.Record
+	index := make(map[string]int)
+	addMilestone := func(m githubMilestone) int {
+		key := strconv.Itoa(m.Number)
+		if i, ok := index[key]; ok {
+			return i
+		}
+
+		due := m.DueOn
+		if due == "" {
+			due = m.DueOnCamel
+		}
+		goal := transfer.GoalRecord{
+			ID:          sourceID(SourceGitHub, "milestone-"+key),
+			Title:       m.Title,
+			Description: m.Description,
+			SubTasks:    []transfer.SubTaskRecord{},
+			EndDate:     parseDate(due),
+			Completed:   strings.EqualFold(m.State, "closed"),
+			CompletedAt: parseDate(m.ClosedAt),
+			CreatedAt:   parseDate(m.CreatedAt),
+		}
+		index[key] = len(records)
+		records = append(records, transfer.Record{Row: len(records) + 1, Goal: goal})
+		return index[key]
+	}
+
+	for _, m := range export.Milestones {
+		addMilestone(m)
+	}
+
+	for _, issue := range export.Issues {
+		number := strconv.Itoa(issue.Number)
+		if len(issue.PullRequest) > 0 && string(issue.PullRequest) != "null" {
+			report.skip("pull_request", number, issue.Title, "pull requests are not imported")
+			continue
+		}
+
+		var i int
+		if issue.Milestone != nil {
+			i = addMilestone(*issue.Milestone)
+		} else {
+			var ok bool
+			if i, ok = index[noMilestoneKey]; !ok {
+				i = len(records)
+				index[noMilestoneKey] = i
+				records = append(records, transfer.Record{Row: i + 1, Goal: transfer.GoalRecord{
+					ID:       sourceID(SourceGitHub, "milestone-"+noMilestoneKey),
+					Title:    "Issues without milestone",
+					SubTasks: []transfer.SubTaskRecord{},
+				}})
+			}
+		}
+
+		created, closed := issue.CreatedAt, issue.ClosedAt
+		if created == "" {
+			created = issue.CreatedCamel
+		}
+		if closed == "" {
+			closed = issue.ClosedCamel
+		}
+
+		task := transfer.SubTaskRecord{
+			ID:          sourceID(SourceGitHub, "issue-"+number),
+			Title:       issue.Title,
+			Description: issue.Body,
+			Completed:   strings.EqualFold(issue.State, "closed"),
+			CompletedAt: parseDate(closed),
+			CreatedAt:   parseDate(created),
+		}
+		for _, label := range issue.Labels {
+			task.Tags = append(task.Tags, label.Name)
+		}
+
+		records[i].Goal.SubTasks = append(records[i].Goal.SubTasks, task)
+	}
+
+	report.count(records)
+	return records, report, nil
+}
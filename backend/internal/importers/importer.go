@@ -0,0 +1,86 @@
+package importers
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"task-management/internal/transfer"
+)
+
+// Supported import sources
+const (
+	SourceTodoist = "todoist"
+	SourceTrello  = "trello"
+	SourceGitHub  = "github"
+)
+
+// ErrUnknownSource is returned when no importer exists for a source
+var ErrUnknownSource = errors.New("unknown import source, expected todoist, trello or github")
+
+// UnmappedItem describes something in an export file that was not imported
+type UnmappedItem struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Report summarises how an export file was mapped onto goals
+type Report struct {
+	Source   string         `json:"source"`
+	Goals    int            `json:"goals"`
+	SubTasks int            `json:"subTasks"`
+	Unmapped []UnmappedItem `json:"unmapped"`
+}
+
+func (r *Report) skip(kind, id, name, reason string) {
+	r.Unmapped = append(r.Unmapped, UnmappedItem{Kind: kind, ID: id, Name: name, Reason: reason})
+}
+
+func (r *Report) count(records []transfer.Record) {
+	r.Goals = len(records)
+	for _, rec := range records {
+		r.SubTasks += len(rec.Goal.SubTasks)
+	}
+}
+
+// Importer converts a third-party export file into goal records
+type Importer func(r io.Reader) ([]transfer.Record, Report, error)
+
+// Lookup returns the importer for a source name
+func Lookup(source string) (Importer, error) {
+	switch strings.ToLower(source) {
+	case SourceTodoist:
+		return ImportTodoist, nil
+	case SourceTrello:
+		return ImportTrello, nil
+	case SourceGitHub:
+		return ImportGitHub, nil
+	}
+	return nil, ErrUnknownSource
+}
+
+// sourceID namespaces an external ID so it can't be mistaken for a local one
+func sourceID(source, id string) string {
+	if id == "" {
+		return ""
+	}
+	return source + ":" + id
+}
+
+// parseDate accepts the timestamp layouts used by the supported exports.
+// Dates without a zone are treated as UTC.
+func parseDate(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package importers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"task-management/internal/transfer"
+)
+
+func TestImporters(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		fixture  string
+		goals    int
+		subTasks int
+		unmapped []string
+		check    func(t *testing.T, records []transfer.Record)
+	}{
+		{
+			name:     "todoist",
+			source:   SourceTodoist,
+			fixture:  "todoist.json",
+			goals:    2,
+			subTasks: 4,
+			unmapped: []string{"project:2203306143", "hierarchy:2", "recurrence:3", "task:5", "task:6"},
+			check: func(t *testing.T, records []transfer.Record) {
+				home := records[0].Goal
+				if home.ID != "todoist:2203306141" || home.Title != "Home" {
+					t.Errorf("first goal = %q %q, want todoist:2203306141 Home", home.ID, home.Title)
+				}
+				if !records[1].Goal.Completed {
+					t.Error("archived project should be imported as completed")
+				}
+				task := home.SubTasks[0]
+				if !reflect.DeepEqual(task.Tags, []string{"chores"}) {
+					t.Errorf("tags = %v, want [chores]", task.Tags)
+				}
+				assertDate(t, "due date", task.DueDate, "2026-02-01T00:00:00Z")
+				assertDate(t, "created at", task.CreatedAt, "2026-01-02T10:00:00Z")
+				if id := home.SubTasks[1].ID; id != "todoist:2" {
+					t.Errorf("numeric item id = %q, want todoist:2", id)
+				}
+			},
+		},
+		{
+			name:     "trello",
+			source:   SourceTrello,
+			fixture:  "trello.json",
+			goals:    2,
+			subTasks: 2,
+			unmapped: []string{"list:l3", "card:c3", "card:c4", "checklist:k1"},
+			check: func(t *testing.T, records []transfer.Record) {
+				if desc := records[0].Goal.Description; desc != "Imported from Trello board Launch" {
+					t.Errorf("description = %q", desc)
+				}
+				card := records[0].Goal.SubTasks[0]
+				if !reflect.DeepEqual(card.Tags, []string{"marketing", "red"}) {
+					t.Errorf("tags = %v, want [marketing red]", card.Tags)
+				}
+				done := records[1].Goal.SubTasks[0]
+				if !done.Completed {
+					t.Error("card with a completed due date should be completed")
+				}
+				assertDate(t, "completed at", done.CompletedAt, "2026-01-10T09:00:00Z")
+			},
+		},
+		{
+			name:     "github with milestones",
+			source:   SourceGitHub,
+			fixture:  "github.json",
+			goals:    4,
+			subTasks: 3,
+			unmapped: []string{"pull_request:13"},
+			check: func(t *testing.T, records []transfer.Record) {
+				titles := make([]string, len(records))
+				for i, rec := range records {
+					titles[i] = rec.Goal.Title
+				}
+				if want := []string{"v1.0", "v1.1", "v2.0", "Issues without milestone"}; !reflect.DeepEqual(titles, want) {
+					t.Errorf("goals = %v, want %v", titles, want)
+				}
+				if !records[0].Goal.Completed {
+					t.Error("closed milestone should be completed")
+				}
+				assertDate(t, "milestone due", records[2].Goal.EndDate, "2026-06-01T00:00:00Z")
+				if issue := records[0].Goal.SubTasks[0]; !issue.Completed || issue.ID != "github:issue-10" {
+					t.Errorf("issue = %+v, want completed github:issue-10", issue)
+				}
+			},
+		},
+		{
+			name:     "github issue list",
+			source:   SourceGitHub,
+			fixture:  "github_issues.json",
+			goals:    1,
+			subTasks: 2,
+			unmapped: []string{},
+			check: func(t *testing.T, records []transfer.Record) {
+				tasks := records[0].Goal.SubTasks
+				if tasks[0].Completed || !tasks[1].Completed {
+					t.Errorf("completion = %v %v, want false true", tasks[0].Completed, tasks[1].Completed)
+				}
+				assertDate(t, "created at", tasks[0].CreatedAt, "2026-01-01T00:00:00Z")
+				assertDate(t, "closed at", tasks[1].CompletedAt, "2026-01-02T00:00:00Z")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			importer, err := Lookup(tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			file, err := os.Open(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			records, report, err := importer(file)
+			if err != nil {
+				t.Fatalf("import failed: %v", err)
+			}
+			if len(records) != tt.goals || report.Goals != tt.goals {
+				t.Errorf("goals = %d (reported %d), want %d", len(records), report.Goals, tt.goals)
+			}
+			if report.SubTasks != tt.subTasks {
+				t.Errorf("subtasks = %d, want %d", report.SubTasks, tt.subTasks)
+			}
+			if report.Source != tt.source {
+				t.Errorf("source = %q, want %q", report.Source, tt.source)
+			}
+
+			unmapped := []string{}
+			for _, item := range report.Unmapped {
+				if item.Reason == "" {
+					t.Errorf("unmapped %s %s has no reason", item.Kind, item.ID)
+				}
+				unmapped = append(unmapped, item.Kind+":"+item.ID)
+			}
+			if !reflect.DeepEqual(unmapped, tt.unmapped) {
+				t.Errorf("unmapped = %v, want %v", unmapped, tt.unmapped)
+			}
+
+			for _, rec := range records {
+				if errs := transfer.Validate(rec); len(errs) > 0 {
+					t.Errorf("record %d is invalid: %v", rec.Row, errs)
+				}
+			}
+			tt.check(t, records)
+		})
+	}
+}
+
+func TestImportersRejectInvalidJSON(t *testing.T) {
+	for _, source := range []string{SourceTodoist, SourceTrello, SourceGitHub} {
+		t.Run(source, func(t *testing.T) {
+			importer, err := Lookup(source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := importer(strings.NewReader("{not json")); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		source  string
+		wantErr bool
+	}{
+		{"todoist", false},
+		{"Trello", false},
+		{"GITHUB", false},
+		{"asana", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		_, err := Lookup(tt.source)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Lookup(%q) error = %v, want error %v", tt.source, err, tt.wantErr)
+		}
+	}
+}
+
+func assertDate(t *testing.T, name string, got *time.Time, want string) {
+	t.Helper()
+	if got == nil {
+		t.Errorf("%s is unset, want %s", name, want)
+		return
+	}
+	if value := got.UTC().Format(time.RFC3339); value != want {
+		t.Errorf("%s = %s, want %s", name, value, want)
+	}
+}
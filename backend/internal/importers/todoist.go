@@ -0,0 +1,125 @@
+package importers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"task-management/internal/transfer"
+)
+
+// todoistExport matches the projects and items of a Todoist sync export
+type todoistExport struct {
+	Projects []struct {
+		ID         flexibleID `json:"id"`
+		Name       string     `json:"name"`
+		IsArchived bool       `json:"is_archived"`
+		IsDeleted  bool       `json:"is_deleted"`
+	} `json:"projects"`
+	Items []struct {
+		ID          flexibleID `json:"id"`
+		ProjectID   flexibleID `json:"project_id"`
+		ParentID    flexibleID `json:"parent_id"`
+		Content     string     `json:"content"`
+		Description string     `json:"description"`
+		Checked     bool       `json:"checked"`
+		IsDeleted   bool       `json:"is_deleted"`
+		Labels      []string   `json:"labels"`
+		AddedAt     string     `json:"added_at"`
+		CompletedAt string     `json:"completed_at"`
+		Due         *struct {
+			Date        string `json:"date"`
+			IsRecurring bool   `json:"is_recurring"`
+		} `json:"due"`
+	} `json:"items"`
+}
+
+// flexibleID accepts IDs encoded as either JSON strings or numbers, since
+// Todoist has used both over time
+type flexibleID string
+
+func (id *flexibleID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = flexibleID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*id = flexibleID(n.String())
+	return nil
+}
+
+func (id flexibleID) String() string {
+	return string(id)
+}
+
+// ImportTodoist maps Todoist projects to goals and their tasks to subtasks
+func ImportTodoist(r io.Reader) ([]transfer.Record, Report, error) {
+	report := Report{Source: SourceTodoist, Unmapped: []UnmappedItem{}}
+
+	var export todoistExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, report, fmt.Errorf("invalid Todoist export: %w", err)
+	}
+
+	var records []transfer.Record
+	index := make(map[string]int)
+	for i, project := range export.Projects {
+		if project.IsDeleted {
+			report.skip("project", project.ID.String(), project.Name, "project is deleted")
+			continue
+		}
+
+		goal := transfer.GoalRecord{
+			ID:        sourceID(SourceTodoist, project.ID.String()),
+			Title:     project.Name,
+			SubTasks:  []transfer.SubTaskRecord{},
+			Completed: project.IsArchived,
+		}
+		index[project.ID.String()] = len(records)
+		records = append(records, transfer.Record{Row: i + 1, Goal: goal})
+	}
+
+	for _, item := range export.Items {
+		if item.IsDeleted {
+			report.skip("task", item.ID.String(), item.Content, "task is deleted")
+			continue
+		}
+
+		i, ok := index[item.ProjectID.String()]
+		if !ok {
+			report.skip("task", item.ID.String(), item.Content, "project "+item.ProjectID.String()+" not found")
+			continue
+		}
+
+		task := transfer.SubTaskRecord{
+			ID:          sourceID(SourceTodoist, item.ID.String()),
+			Title:       item.Content,
+			Description: item.Description,
+			Completed:   item.Checked,
+			CompletedAt: parseDate(item.CompletedAt),
+			Tags:        item.Labels,
+			CreatedAt:   parseDate(item.AddedAt),
+		}
+		if item.Due != nil {
+			task.DueDate = parseDate(item.Due.Date)
+			if item.Due.IsRecurring {
+				report.skip("recurrence", item.ID.String(), item.Content, "recurring due dates are imported as a single due date")
+			}
+		}
+		if item.ParentID != "" {
+			report.skip("hierarchy", item.ID.String(), item.Content, "sub-task flattened into its project")
+		}
+
+		records[i].Goal.SubTasks = append(records[i].Goal.SubTasks, task)
+	}
+
+	report.count(records)
+	return records, report, nil
+}
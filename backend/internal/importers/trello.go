@@ -0,0 +1,112 @@
+package importers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"task-management/internal/transfer"
+)
+
+// trelloExport matches the lists, cards and checklists of a Trello board export
+type trelloExport struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		ID               string `json:"id"`
+		IDList           string `json:"idList"`
+		Name             string `json:"name"`
+		Desc             string `json:"desc"`
+		Closed           bool   `json:"closed"`
+		Due              string `json:"due"`
+		DueComplete      bool   `json:"dueComplete"`
+		DateLastActivity string `json:"dateLastActivity"`
+		Labels           []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+	} `json:"cards"`
+	Checklists []struct {
+		ID     string `json:"id"`
+		IDCard string `json:"idCard"`
+		Name   string `json:"name"`
+	} `json:"checklists"`
+}
+
+// ImportTrello maps Trello lists to goals and their cards to subtasks
+func ImportTrello(r io.Reader) ([]transfer.Record, Report, error) {
+	report := Report{Source: SourceTrello, Unmapped: []UnmappedItem{}}
+
+	var export trelloExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, report, fmt.Errorf("invalid Trello export: %w", err)
+	}
+
+	var records []transfer.Record
+	index := make(map[string]int)
+	for i, list := range export.Lists {
+		if list.Closed {
+			report.skip("list", list.ID, list.Name, "list is archived")
+			continue
+		}
+
+		goal := transfer.GoalRecord{
+			ID:       sourceID(SourceTrello, list.ID),
+			Title:    list.Name,
+			SubTasks: []transfer.SubTaskRecord{},
+		}
+		if export.Name != "" {
+			goal.Description = "Imported from Trello board " + export.Name
+		}
+		index[list.ID] = len(records)
+		records = append(records, transfer.Record{Row: i + 1, Goal: goal})
+	}
+
+	for _, card := range export.Cards {
+		if card.Closed {
+			report.skip("card", card.ID, card.Name, "card is archived")
+			continue
+		}
+
+		i, ok := index[card.IDList]
+		if !ok {
+			report.skip("card", card.ID, card.Name, "list "+card.IDList+" not imported")
+			continue
+		}
+
+		task := transfer.SubTaskRecord{
+			ID:          sourceID(SourceTrello, card.ID),
+			Title:       card.Name,
+			Description: card.Desc,
+			Completed:   card.DueComplete,
+			DueDate:     parseDate(card.Due),
+		}
+		if task.Completed {
+			// Trello doesn't record when a card was completed
+			task.CompletedAt = parseDate(card.DateLastActivity)
+		}
+		for _, label := range card.Labels {
+			name := label.Name
+			if name == "" {
+				name = label.Color
+			}
+			if name != "" {
+				task.Tags = append(task.Tags, name)
+			}
+		}
+
+		records[i].Goal.SubTasks = append(records[i].Goal.SubTasks, task)
+	}
+
+	for _, checklist := range export.Checklists {
+		report.skip("checklist", checklist.ID, checklist.Name, "checklists are not imported")
+	}
+
+	report.count(records)
+	return records, report, nil
+}
@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunDaily runs fn once immediately and then at every UTC midnight until
+// ctx is cancelled. It blocks, so callers should start it in a goroutine.
+func RunDaily(ctx context.Context, name string, fn func(ctx context.Context, now time.Time) error) {
+	run := func() {
+		start := time.Now()
+		if err := fn(ctx, start); err != nil {
+			log.Printf("Job %s failed: %v", name, err)
+			return
+		}
+		log.Printf("Job %s finished in %s", name, time.Since(start))
+	}
+
+	run()
+	for {
+		now := time.Now().UTC()
+		next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			run()
+		}
+	}
+}
@@ -0,0 +1,30 @@
+// Package mail delivers outgoing email such as verification messages
+package mail
+
+import (
+	"context"
+	"log"
+)
+
+// Message is an outgoing email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers outgoing email
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Nop is a Mailer that drops every message, for development and tests. Only
+// the recipient and subject are logged, since bodies can carry secrets such
+// as verification tokens.
+type Nop struct{}
+
+// Send logs that msg was dropped
+func (Nop) Send(ctx context.Context, msg Message) error {
+	log.Printf("Mail delivery is not configured, dropping %q to %s", msg.Subject, msg.To)
+	return nil
+}
@@ -0,0 +1,156 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		required bool
+		want     string
+		wantErr  bool
+	}{
+		{name: "unconfigured in development", want: "nop"},
+		{name: "unconfigured in production", required: true, wantErr: true},
+		{name: "smtp", cfg: Config{Host: "smtp.example.com", From: "Tasks <no-reply@example.com>"}, required: true, want: "smtp"},
+		{name: "invalid sender", cfg: Config{Host: "smtp.example.com", From: "not an address"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mailer, err := New(tt.cfg, tt.required)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, want error %v", err, tt.wantErr)
+			}
+			switch mailer.(type) {
+			case Nop:
+				if tt.want != "nop" {
+					t.Errorf("New() = Nop, want %s", tt.want)
+				}
+			case *SMTP:
+				if tt.want != "smtp" {
+					t.Errorf("New() = SMTP, want %s", tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMessageEncode(t *testing.T) {
+	from := mail.Address{Name: "Tasks", Address: "no-reply@example.com"}
+	to := mail.Address{Address: "bob@example.com"}
+	date := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	data, err := Message{Subject: "Bestätigen", Body: "Token: abc=123\nThanks"}.encode(from, to, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, want := range []string{
+		"From: \"Tasks\" <no-reply@example.com>\r\n",
+		"To: <bob@example.com>\r\n",
+		"Subject: =?utf-8?q?Best=C3=A4tigen?=\r\n",
+		"Date: Tue, 10 Mar 2026 12:00:00 +0000\r\n",
+		"Content-Transfer-Encoding: quoted-printable\r\n\r\n",
+		"Token: abc=3D123\r\nThanks",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("encoded message lacks %q:\n%s", want, text)
+		}
+	}
+
+	if _, err := (Message{Subject: "Hi\r\nBcc: eve@example.com"}).encode(from, to, date); err == nil {
+		t.Error("expected a subject with a line break to be rejected")
+	}
+}
+
+func TestSMTPSend(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("can't listen on loopback")
+	}
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go serveSMTP(listener, received)
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	mailer, err := NewSMTP(Config{Host: "127.0.0.1", Port: port, From: "no-reply@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := mailer.Send(ctx, Message{To: "Bob <bob@example.com>", Subject: "Hello", Body: "Token: abc"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	session := strings.Join(<-received, "\n")
+	for _, want := range []string{"MAIL FROM:<no-reply@example.com>", "RCPT TO:<bob@example.com>", "Subject: Hello", "Token: abc"} {
+		if !strings.Contains(session, want) {
+			t.Errorf("session lacks %q:\n%s", want, session)
+		}
+	}
+
+	if err := mailer.Send(ctx, Message{To: "not an address"}); err == nil {
+		t.Error("expected an invalid recipient to be rejected")
+	}
+}
+
+// serveSMTP accepts one connection and plays a minimal SMTP server without
+// extensions, sending the lines the client wrote to received
+func serveSMTP(listener net.Listener, received chan<- []string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	var lines []string
+	reader := bufio.NewReader(conn)
+	reply := func(code int, text string) {
+		conn.Write([]byte(strconv.Itoa(code) + " " + text + "\r\n"))
+	}
+
+	reply(220, "localhost ready")
+	inData := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+		line = strings.TrimRight(line, "\r\n")
+		lines = append(lines, line)
+
+		if inData {
+			if line == "." {
+				inData = false
+				reply(250, "queued")
+			}
+			continue
+		}
+		switch command := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); command {
+		case "EHLO", "HELO", "MAIL", "RCPT", "NOOP", "RSET":
+			reply(250, "ok")
+		case "DATA":
+			inData = true
+			reply(354, "go ahead")
+		case "QUIT":
+			reply(221, "bye")
+			received <- lines
+			return
+		default:
+			reply(502, "not implemented")
+		}
+	}
+	received <- lines
+}
@@ -0,0 +1,32 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mentionPattern matches @username where the @ doesn't follow a word
+// character, so email addresses aren't treated as mentions
+var mentionPattern = regexp.MustCompile(`(^|[^A-Za-z0-9_@.])@([A-Za-z0-9_][A-Za-z0-9_.-]*)`)
+
+// Mentions returns the distinct usernames mentioned in body, in the order
+// they first appear. Mentions inside code are ignored.
+func Mentions(body string) []string {
+	var usernames []string
+	seen := make(map[string]bool)
+
+	mapText(body, func(text string) string {
+		for _, m := range mentionPattern.FindAllStringSubmatch(text, -1) {
+			// Trailing punctuation ends a sentence rather than the name
+			username := strings.TrimRight(m[2], ".-")
+			if len(username) < 3 || seen[username] {
+				continue
+			}
+			seen[username] = true
+			usernames = append(usernames, username)
+		}
+		return text
+	})
+
+	return usernames
+}
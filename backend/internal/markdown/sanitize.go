@@ -0,0 +1,111 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// MaxLength bounds the length of a sanitized body in bytes
+const MaxLength = 10000
+
+var (
+	htmlComment   = regexp.MustCompile(`<!--[\s\S]*?(-->|$)`)
+	angleBrackets = regexp.MustCompile(`<[^<>]*>`)
+	autolink      = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]*:[^\s<>]*|[^\s<>@]+@[^\s<>@]+)>$`)
+	htmlLike      = regexp.MustCompile(`^</?[A-Za-z!?]`)
+	inlineLink    = regexp.MustCompile(`(\]\(\s*)(<[^>\n]*>|(?:[^()\s]|\([^()\s]*\))+)`)
+	referenceLink = regexp.MustCompile(`(?m)^( {0,3}\[[^\]\n]+\]:[ \t]*)(\S+)`)
+	linkScheme    = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+.-]*):`)
+)
+
+// safeSchemes lists the URL schemes allowed in links and images
+var safeSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// Sanitize makes a Markdown body safe to store and render: raw HTML is
+// removed, links with schemes other than http, https and mailto are
+// neutralised, control characters are dropped and line endings normalised.
+// Code is left as written since renderers escape it.
+func Sanitize(body string) string {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	body = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if r == '\r' {
+			return '\n'
+		}
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, body)
+
+	body = mapText(body, sanitizeText)
+	return strings.TrimSpace(body)
+}
+
+func sanitizeText(text string) string {
+	// Removing a tag can join the text around it into a new one, as in
+	// <scr<script>ipt>, so removal repeats until nothing changes
+	for {
+		stripped := htmlComment.ReplaceAllString(text, "")
+		stripped = angleBrackets.ReplaceAllStringFunc(stripped, stripTag)
+		if stripped == text {
+			break
+		}
+		text = stripped
+	}
+
+	replaceURL := func(re *regexp.Regexp) {
+		text = re.ReplaceAllStringFunc(text, func(match string) string {
+			m := re.FindStringSubmatch(match)
+			if SafeURL(strings.Trim(m[2], "<>")) {
+				return match
+			}
+			return m[1] + "#"
+		})
+	}
+	replaceURL(inlineLink)
+	replaceURL(referenceLink)
+
+	return text
+}
+
+// stripTag returns what is left of an angle-bracketed span: raw HTML and
+// unsafe autolinks are removed, anything else is kept
+func stripTag(tag string) string {
+	if m := autolink.FindStringSubmatch(tag); m != nil {
+		if strings.Contains(m[1], ":") && !SafeURL(m[1]) {
+			return ""
+		}
+		return tag
+	}
+	if htmlLike.MatchString(tag) {
+		return ""
+	}
+	return tag
+}
+
+// SafeURL reports whether a link destination is relative or uses an
+// allowed scheme. Entities and whitespace are resolved first so they can't
+// hide a scheme like javascript:.
+func SafeURL(raw string) bool {
+	decoded := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, html.UnescapeString(raw))
+
+	m := linkScheme.FindStringSubmatch(decoded)
+	if m == nil {
+		return true
+	}
+	return safeSchemes[strings.ToLower(m[1])]
+}
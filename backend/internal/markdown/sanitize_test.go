@@ -0,0 +1,117 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		// Links
+		{"javascript link", "[x](javascript:alert(1))", "[x](#)"},
+		{"mixed case scheme", "[x](JaVaScRiPt:alert(1))", "[x](#)"},
+		{"padded destination", "[x]( javascript:alert(1) )", "[x]( # )"},
+		{"entity encoded scheme", "[x](java&#115;cript:alert(1))", "[x](#)"},
+		{"bracketed destination", "[x](<javascript:alert(1)>)", "[x]()"},
+		{"data image", "![img](data:text/html;base64,PHNjcmlwdD4=)", "![img](#)"},
+		{"reference definition", "[r]: javascript:alert(1)\n\n[x][r]", "[r]: #\n\n[x][r]"},
+		{"vbscript reference", "[r]: VBScript:msgbox", "[r]: #"},
+		{"unsafe autolink", "<javascript:alert(1)>", ""},
+		{"https link", "[ok](https://example.com/a_(b))", "[ok](https://example.com/a_(b))"},
+		{"mailto link", "[mail](mailto:a@b.c)", "[mail](mailto:a@b.c)"},
+		{"relative link", "[rel](/goals/1)", "[rel](/goals/1)"},
+		{"safe autolink", "<https://example.com>", "<https://example.com>"},
+		{"email autolink", "<a@b.c>", "<a@b.c>"},
+
+		// Raw HTML
+		{"onerror attribute", "<img src=x onerror=alert(1)>", ""},
+		{"event handler on link", `<a href="x" onclick="y">hi</a>`, "hi"},
+		{"svg onload", "<svg/onload=alert(1)>", ""},
+		{"tag split over lines", "<img\nsrc=x onerror=alert(1)>", ""},
+		{"nested tags", "<div><img src=x onerror=alert(1)></div>", ""},
+		{"tag rebuilt from fragments", "<scr<script>ipt>alert(1)</script>", "alert(1)"},
+		{"doubled brackets", "<<script>script>alert(1)<</script>/script>", "alert(1)"},
+		{"comment", "<!-- <script>alert(1)</script> -->ok", "ok"},
+		{"unterminated comment", "ok<!-- <script>", "ok"},
+		{"comparison", "a < b > c", "a < b > c"},
+
+		// Code and text
+		{"inline code", "`<script>alert(1)</script>`", "`<script>alert(1)</script>`"},
+		{"fenced code", "```\n<img src=x onerror=alert(1)>\n```", "```\n<img src=x onerror=alert(1)>\n```"},
+		{"control characters", "a\x00b\x1bc\r\nd\re", "abc\nd\ne"},
+		{"surrounding space", "  hello  \n", "hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sanitize(tt.in); got != tt.want {
+				t.Errorf("Sanitize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeLeavesNoTags(t *testing.T) {
+	// However the fragments are arranged, no HTML tag may survive
+	for _, in := range []string{
+		"<<img src=x onerror=alert(1)>img src=x onerror=alert(1)>",
+		"<sc<scr<script>ipt>ript>",
+		"<<<a>a>a href=javascript:alert(1)>",
+	} {
+		out := Sanitize(in)
+		if htmlLike.MatchString(out) || strings.Contains(out, "<a") || strings.Contains(out, "<img") || strings.Contains(out, "<script") {
+			t.Errorf("Sanitize(%q) = %q still contains a tag", in, out)
+		}
+	}
+}
+
+func TestSafeURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com", true},
+		{"HTTP://example.com", true},
+		{"mailto:a@b.c", true},
+		{"/relative/path", true},
+		{"#anchor", true},
+		{"javascript:alert(1)", false},
+		{" javascript:alert(1)", false},
+		{"java\nscript:alert(1)", false},
+		{"&#106;avascript:alert(1)", false},
+		{"data:text/html,hi", false},
+		{"file:///etc/passwd", false},
+	}
+	for _, tt := range tests {
+		if got := SafeURL(tt.url); got != tt.want {
+			t.Errorf("SafeURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"single", "thanks @alice", []string{"alice"}},
+		{"distinct in order", "@bob and @alice, then @bob again", []string{"bob", "alice"}},
+		{"trailing punctuation", "ask @carol.", []string{"carol"}},
+		{"email is not a mention", "mail bob@example.com", nil},
+		{"too short", "hi @al", nil},
+		{"inside code", "`@alice` and\n```\n@bob\n```\n@dave", []string{"dave"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Mentions(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Mentions(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Package markdown sanitizes user-written Markdown and extracts mentions
+// from it. Code spans and fenced code blocks are left untouched by both.
+package markdown
+
+import "strings"
+
+// mapText applies fn to every part of body that is outside inline code
+// spans and fenced code blocks, and returns the reassembled body
+func mapText(body string, fn func(string) string) string {
+	var out strings.Builder
+	var text strings.Builder
+	flush := func() {
+		out.WriteString(fn(text.String()))
+		text.Reset()
+	}
+
+	fence := ""
+	for _, line := range strings.SplitAfter(body, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if fence != "" {
+			out.WriteString(line)
+			if strings.HasPrefix(trimmed, fence) && strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1])) == "" {
+				fence = ""
+			}
+			continue
+		}
+		if marker := fenceMarker(trimmed); marker != "" {
+			flush()
+			fence = marker
+			out.WriteString(line)
+			continue
+		}
+		mapInline(line, &text, &out, flush)
+	}
+	flush()
+
+	return out.String()
+}
+
+// fenceMarker returns the run of backticks or tildes opening a fenced code
+// block, or an empty string when the line doesn't open one
+func fenceMarker(line string) string {
+	for _, ch := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, ch))
+		if n >= 3 {
+			return strings.Repeat(ch, n)
+		}
+	}
+	return ""
+}
+
+// mapInline splits a line into text and code spans. Text accumulates in
+// text; code spans flush the pending text and are written to out as is.
+func mapInline(line string, text, out *strings.Builder, flush func()) {
+	for len(line) > 0 {
+		start := strings.IndexByte(line, '`')
+		if start < 0 {
+			text.WriteString(line)
+			return
+		}
+		n := len(line[start:]) - len(strings.TrimLeft(line[start:], "`"))
+		ticks := line[start : start+n]
+
+		end := closingTicks(line[start+n:], n)
+		if end < 0 {
+			// An unmatched run of backticks is literal text
+			text.WriteString(line[:start+n])
+			line = line[start+n:]
+			continue
+		}
+
+		text.WriteString(line[:start])
+		flush()
+		codeEnd := start + n + end + n
+		out.WriteString(ticks + line[start+n:codeEnd])
+		line = line[codeEnd:]
+	}
+}
+
+// closingTicks finds the offset of the next run of exactly n backticks
+func closingTicks(s string, n int) int {
+	for i := 0; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		run := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+		if run == n {
+			return i
+		}
+		i += run
+	}
+	return -1
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strings"
@@ -8,35 +9,50 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/models"
 )
 
+// WorkspaceHeader selects the active workspace when it isn't in the path
+const WorkspaceHeader = "X-Workspace-ID"
+
 // JwtMiddleware represents the JWT authentication middleware
 type JwtMiddleware struct {
-	jwtSecret string
+	jwtSecret           string
+	userCollection      *mongo.Collection
+	workspaceCollection *mongo.Collection
 }
 
 // TokenClaims represents the JWT token claims
 type TokenClaims struct {
-	UserID string `json:"userId"`
+	UserID       string `json:"userId"`
+	TokenVersion int    `json:"ver,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // NewJwtMiddleware creates a new JWT middleware
-func NewJwtMiddleware(jwtSecret string) *JwtMiddleware {
+func NewJwtMiddleware(jwtSecret string, userCollection, workspaceCollection *mongo.Collection) *JwtMiddleware {
 	return &JwtMiddleware{
-		jwtSecret: jwtSecret,
+		jwtSecret:           jwtSecret,
+		userCollection:      userCollection,
+		workspaceCollection: workspaceCollection,
 	}
 }
 
-// GenerateToken generates a new JWT token
-func (m *JwtMiddleware) GenerateToken(userID primitive.ObjectID, expiryHours int) (string, error) {
+// GenerateToken generates a new JWT token for the user's current token
+// version
+func (m *JwtMiddleware) GenerateToken(userID primitive.ObjectID, tokenVersion int, expiryHours int) (string, error) {
 	// Set expiration time
 	expirationTime := time.Now().Add(time.Duration(expiryHours) * time.Hour)
 
 	// Create claims
 	claims := &TokenClaims{
-		UserID: userID.Hex(),
+		UserID:       userID.Hex(),
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -97,8 +113,82 @@ func (m *JwtMiddleware) AuthRequired() gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens of deleted users and tokens revoked by a password change
+		if status, message := m.checkSession(userID, claims); status != 0 {
+			c.JSON(status, gin.H{"error": message})
+			c.Abort()
+			return
+		}
+
 		// Set user ID in context
 		c.Set("userId", userID)
+
+		// Resolve the active workspace, if any
+		if status, message := m.resolveWorkspace(c, userID); status != 0 {
+			c.JSON(status, gin.H{"error": message})
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
+
+// checkSession checks that the token's user still exists and that the
+// token wasn't revoked by a password change since it was issued. It returns a non-zero
+// status and message when the token can't be used.
+func (m *JwtMiddleware) checkSession(userID primitive.ObjectID, claims *TokenClaims) (int, string) {
+	var user models.User
+	err := m.userCollection.FindOne(context.Background(),
+		bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"tokenVersion": 1}),
+	).Decode(&user)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return http.StatusUnauthorized, "User not found"
+		}
+		return http.StatusInternalServerError, "Failed to get user"
+	}
+
+	if user.SessionRevoked(claims.TokenVersion) {
+		return http.StatusUnauthorized, "Session has been revoked"
+	}
+	return 0, ""
+}
+
+// resolveWorkspace reads the workspace ID from the :workspaceId path
+// parameter or the X-Workspace-ID header and checks that the user belongs
+// to it. Requests without either use the user's personal space. It returns
+// a non-zero status and message when the workspace can't be used.
+func (m *JwtMiddleware) resolveWorkspace(c *gin.Context, userID primitive.ObjectID) (int, string) {
+	value := c.Param("workspaceId")
+	if value == "" {
+		value = c.GetHeader(WorkspaceHeader)
+	}
+	if value == "" {
+		return 0, ""
+	}
+
+	workspaceID, err := primitive.ObjectIDFromHex(value)
+	if err != nil {
+		return http.StatusBadRequest, "Invalid workspace ID"
+	}
+
+	var workspace models.Workspace
+	err = m.workspaceCollection.FindOne(context.Background(), bson.M{
+		"_id":            workspaceID,
+		"members.userId": userID,
+	}).Decode(&workspace)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return http.StatusNotFound, "Workspace not found"
+		}
+		return http.StatusInternalServerError, "Failed to get workspace"
+	}
+
+	c.Set("workspaceId", workspaceID)
+	c.Set("workspaceRole", workspace.RoleOf(userID))
+	return 0, ""
+}
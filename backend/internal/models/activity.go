@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Activity actions
+const (
+	ActivityGoalCreated        = "goal.created"
+	ActivityGoalUpdated        = "goal.updated"
+	ActivityGoalDeleted        = "goal.deleted"
+	ActivityGoalRestored       = "goal.restored"
+	ActivityGoalMoved          = "goal.moved"
+	ActivityGoalArchived       = "goal.archived"
+	ActivityGoalDuplicated     = "goal.duplicated"
+	ActivityGoalUndone         = "goal.undone"
+	ActivityGoalRedone         = "goal.redone"
+	ActivityMemberAdded        = "goal.member_added"
+	ActivityMemberUpdated      = "goal.member_updated"
+	ActivityMemberRemoved      = "goal.member_removed"
+	ActivitySubTaskCreated     = "subtask.created"
+	ActivitySubTaskUpdated     = "subtask.updated"
+	ActivitySubTaskDeleted     = "subtask.deleted"
+	ActivityKeyResultCreated   = "keyresult.created"
+	ActivityKeyResultUpdated   = "keyresult.updated"
+	ActivityKeyResultDeleted   = "keyresult.deleted"
+	ActivityKeyResultCheckedIn = "keyresult.checked_in"
+	ActivityHabitUpdated       = "habit.updated"
+	ActivityHabitCheckedIn     = "habit.checked_in"
+	ActivityHabitUnchecked     = "habit.unchecked"
+	ActivityRegistered         = "auth.registered"
+	ActivityLogin              = "auth.login"
+	ActivityLoginFailed        = "auth.login_failed"
+	ActivityProfileUpdated     = "account.updated"
+	ActivityEmailChanged       = "account.email_changed"
+	ActivityPasswordChanged    = "account.password_changed"
+	ActivityDeletionScheduled  = "account.deletion_scheduled"
+	ActivityDeletionCancelled  = "account.deletion_cancelled"
+	ActivityAccountDeleted     = "account.deleted"
+)
+
+// FieldChange records the value of a single field before and after a change.
+// Field is a dotted path; subtasks are addressed by ID, as in
+// subTasks.<id>.title.
+type FieldChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
+
+// Activity is an append-only audit log entry describing who did what and
+// from where
+type Activity struct {
+	ID          primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	Action      string              `json:"action" bson:"action"`
+	ActorID     *primitive.ObjectID `json:"actorId,omitempty" bson:"actorId,omitempty"`
+	GoalID      *primitive.ObjectID `json:"goalId,omitempty" bson:"goalId,omitempty"`
+	SubTaskID   *primitive.ObjectID `json:"subTaskId,omitempty" bson:"subTaskId,omitempty"`
+	WorkspaceID *primitive.ObjectID `json:"workspaceId,omitempty" bson:"workspaceId,omitempty"`
+	IP          string              `json:"ip,omitempty" bson:"ip,omitempty"`
+	UserAgent   string              `json:"userAgent,omitempty" bson:"userAgent,omitempty"`
+	Changes     []FieldChange       `json:"changes,omitempty" bson:"changes,omitempty"`
+	Metadata    map[string]string   `json:"metadata,omitempty" bson:"metadata,omitempty"`
+	CreatedAt   time.Time           `json:"createdAt" bson:"createdAt"`
+}
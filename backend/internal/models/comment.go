@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CommentEdit records a previous body of an edited comment
+type CommentEdit struct {
+	Body     string    `json:"body" bson:"body"`
+	EditedAt time.Time `json:"editedAt" bson:"editedAt"`
+}
+
+// Comment represents a comment on a goal or one of its subtasks. Replies
+// point at the comment they answer through ParentID. Bodies are sanitized
+// Markdown.
+type Comment struct {
+	ID        primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	GoalID    primitive.ObjectID   `json:"goalId" bson:"goalId"`
+	SubTaskID *primitive.ObjectID  `json:"subTaskId,omitempty" bson:"subTaskId,omitempty"`
+	ParentID  *primitive.ObjectID  `json:"parentId,omitempty" bson:"parentId,omitempty"`
+	AuthorID  primitive.ObjectID   `json:"authorId" bson:"authorId"`
+	Body      string               `json:"body" bson:"body"`
+	Mentions  []primitive.ObjectID `json:"mentions,omitempty" bson:"mentions,omitempty"`
+	Edits     []CommentEdit        `json:"-" bson:"edits,omitempty"`
+	Edited    bool                 `json:"edited" bson:"-"`
+	Deleted   bool                 `json:"deleted" bson:"-"`
+	CreatedAt time.Time            `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time            `json:"updatedAt" bson:"updatedAt"`
+	DeletedAt *time.Time           `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+	DeletedBy *primitive.ObjectID  `json:"deletedBy,omitempty" bson:"deletedBy,omitempty"`
+}
+
+// Prepare fills in the computed response fields and hides the content of
+// deleted comments, which stay in place so their replies keep their thread
+func (c *Comment) Prepare() {
+	c.Edited = len(c.Edits) > 0
+	c.Deleted = c.DeletedAt != nil
+	if c.Deleted {
+		c.Body = ""
+		c.Mentions = nil
+	}
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// Units of subtask estimates. Goals without a unit use points.
+const (
+	EstimatePoints = "points"
+	EstimateHours  = "hours"
+)
+
+// WorkHoursPerDay converts hour estimates into days when scheduling. A
+// point is scheduled as one day.
+const WorkHoursPerDay = 8
+
+// EffortSummary compares a goal's estimated effort with what is left
+type EffortSummary struct {
+	Unit        string  `json:"unit"`
+	Estimated   float64 `json:"estimated"`
+	Completed   float64 `json:"completed"`
+	Remaining   float64 `json:"remaining"`
+	Unestimated int     `json:"unestimated"`
+}
+
+// ProgressWeight returns the subtask's share of its goal's progress: its
+// weight when set, otherwise its estimate, otherwise 1
+func (t *SubTask) ProgressWeight() float64 {
+	if t.Weight != nil {
+		return *t.Weight
+	}
+	if t.Estimate != nil && *t.Estimate > 0 {
+		return *t.Estimate
+	}
+	return 1
+}
+
+// Unit returns the goal's estimate unit, defaulting to points
+func (g *Goal) Unit() string {
+	if g.EstimateUnit == "" {
+		return EstimatePoints
+	}
+	return g.EstimateUnit
+}
+
+// TaskDuration returns how long a subtask of the goal is scheduled to take,
+// in calendar time. Unestimated subtasks take no time.
+func (g *Goal) TaskDuration(t *SubTask) time.Duration {
+	if t.Estimate == nil {
+		return 0
+	}
+
+	days := *t.Estimate
+	if g.Unit() == EstimateHours {
+		days /= WorkHoursPerDay
+	}
+	return time.Duration(days * float64(24*time.Hour))
+}
+
+// Effort sums the estimates of the goal's subtasks. Unestimated counts the
+// open subtasks without an estimate, whose effort is unknown.
+func (g *Goal) Effort() EffortSummary {
+	summary := EffortSummary{Unit: g.Unit()}
+	for _, task := range g.SubTasks {
+		if task.Estimate == nil {
+			if !task.Completed {
+				summary.Unestimated++
+			}
+			continue
+		}
+
+		summary.Estimated += *task.Estimate
+		if task.Completed {
+			summary.Completed += *task.Estimate
+		} else {
+			summary.Remaining += *task.Estimate
+		}
+	}
+	return summary
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Focus session states
+const (
+	FocusRunning   = "running"
+	FocusPaused    = "paused"
+	FocusCompleted = "completed"
+	FocusAbandoned = "abandoned"
+)
+
+// Focus session actions
+const (
+	FocusPause    = "pause"
+	FocusResume   = "resume"
+	FocusComplete = "complete"
+	FocusAbandon  = "abandon"
+)
+
+// ErrInvalidTransition is returned for actions the session's state doesn't
+// allow
+var ErrInvalidTransition = errors.New("invalid focus session transition")
+
+// FocusTransition records a change of a focus session's state
+type FocusTransition struct {
+	State string    `json:"state" bson:"state"`
+	At    time.Time `json:"at" bson:"at"`
+}
+
+// FocusSession is a pomodoro: a work interval, optionally on a subtask,
+// followed by a break. Time spent paused doesn't count as focus.
+type FocusSession struct {
+	ID            primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	UserID        primitive.ObjectID  `json:"userId" bson:"userId"`
+	WorkspaceID   *primitive.ObjectID `json:"workspaceId,omitempty" bson:"workspaceId,omitempty"`
+	GoalID        *primitive.ObjectID `json:"goalId,omitempty" bson:"goalId,omitempty"`
+	SubTaskID     *primitive.ObjectID `json:"subTaskId,omitempty" bson:"subTaskId,omitempty"`
+	WorkMinutes   int                 `json:"workMinutes" bson:"workMinutes"`
+	BreakMinutes  int                 `json:"breakMinutes" bson:"breakMinutes"`
+	State         string              `json:"state" bson:"state"`
+	StartedAt     time.Time           `json:"startedAt" bson:"startedAt"`
+	PausedAt      *time.Time          `json:"pausedAt,omitempty" bson:"pausedAt,omitempty"`
+	PausedSeconds int64               `json:"pausedSeconds" bson:"pausedSeconds"`
+	EndedAt       *time.Time          `json:"endedAt,omitempty" bson:"endedAt,omitempty"`
+	FocusSeconds  int64               `json:"focusSeconds" bson:"focusSeconds"`
+	BreakEndsAt   *time.Time          `json:"breakEndsAt,omitempty" bson:"breakEndsAt,omitempty"`
+	Transitions   []FocusTransition   `json:"transitions" bson:"transitions"`
+	CreatedAt     time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt     time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+// Active reports whether the session is running or paused
+func (s *FocusSession) Active() bool {
+	return s.State == FocusRunning || s.State == FocusPaused
+}
+
+// workDuration is the length of the session's work interval
+func (s *FocusSession) workDuration() time.Duration {
+	return time.Duration(s.WorkMinutes) * time.Minute
+}
+
+// focused returns the time focused up to now, excluding pauses
+func (s *FocusSession) focused(now time.Time) time.Duration {
+	end := now
+	if s.PausedAt != nil {
+		end = *s.PausedAt
+	}
+	if s.EndedAt != nil {
+		end = *s.EndedAt
+	}
+	return end.Sub(s.StartedAt) - time.Duration(s.PausedSeconds)*time.Second
+}
+
+// Refresh updates the focus time of an active session and completes it if
+// its work interval has run out, back-dated to when it did. It reports
+// whether the session was completed.
+func (s *FocusSession) Refresh(now time.Time) bool {
+	if !s.Active() {
+		return false
+	}
+
+	if s.State == FocusRunning && s.focused(now) >= s.workDuration() {
+		s.end(FocusCompleted, s.StartedAt.Add(s.workDuration()+time.Duration(s.PausedSeconds)*time.Second))
+		return true
+	}
+
+	s.FocusSeconds = int64(s.focused(now) / time.Second)
+	return false
+}
+
+// Apply moves the session to the state an action leads to, failing with
+// ErrInvalidTransition when the current state doesn't allow it
+func (s *FocusSession) Apply(action string, now time.Time) error {
+	switch {
+	case action == FocusPause && s.State == FocusRunning:
+		s.PausedAt = &now
+		s.setState(FocusPaused, now)
+	case action == FocusResume && s.State == FocusPaused:
+		s.PausedSeconds += int64(now.Sub(*s.PausedAt) / time.Second)
+		s.PausedAt = nil
+		s.setState(FocusRunning, now)
+	case action == FocusComplete && s.Active():
+		s.end(FocusCompleted, now)
+	case action == FocusAbandon && s.Active():
+		s.end(FocusAbandoned, now)
+	default:
+		return ErrInvalidTransition
+	}
+
+	s.FocusSeconds = int64(s.focused(now) / time.Second)
+	return nil
+}
+
+// end finishes the session at the given time. Completed sessions start
+// their break.
+func (s *FocusSession) end(state string, at time.Time) {
+	if s.PausedAt != nil {
+		s.PausedSeconds += int64(at.Sub(*s.PausedAt) / time.Second)
+		s.PausedAt = nil
+	}
+	s.EndedAt = &at
+	s.FocusSeconds = int64(s.focused(at) / time.Second)
+	if state == FocusCompleted && s.BreakMinutes > 0 {
+		breakEndsAt := at.Add(time.Duration(s.BreakMinutes) * time.Minute)
+		s.BreakEndsAt = &breakEndsAt
+	}
+	s.setState(state, at)
+}
+
+// setState changes the session's state and records the transition
+func (s *FocusSession) setState(state string, at time.Time) {
+	s.State = state
+	s.Transitions = append(s.Transitions, FocusTransition{State: state, At: at})
+}
@@ -6,52 +6,353 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// SubTask represents a subtask within a goal
+// Goal member roles, from most to least privileged
+const (
+	RoleOwner     = "owner"
+	RoleEditor    = "editor"
+	RoleCommenter = "commenter"
+	RoleViewer    = "viewer"
+)
+
+// Goal and subtask priorities
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+var roleRanks = map[string]int{
+	RoleViewer:    1,
+	RoleCommenter: 2,
+	RoleEditor:    3,
+	RoleOwner:     4,
+}
+
+// RoleAtLeast reports whether role grants at least the permissions of min
+func RoleAtLeast(role, min string) bool {
+	return roleRanks[role] > 0 && roleRanks[role] >= roleRanks[min]
+}
+
+// GoalMember represents a user a goal is shared with
+type GoalMember struct {
+	UserID  primitive.ObjectID `json:"userId" bson:"userId"`
+	Role    string             `json:"role" bson:"role"`
+	AddedBy primitive.ObjectID `json:"addedBy" bson:"addedBy"`
+	AddedAt time.Time          `json:"addedAt" bson:"addedAt"`
+}
+
+// Assignment records a change of a subtask's assignee. A nil AssigneeID
+// means the subtask was unassigned.
+type Assignment struct {
+	AssigneeID *primitive.ObjectID `json:"assigneeId" bson:"assigneeId"`
+	AssignedBy primitive.ObjectID  `json:"assignedBy" bson:"assignedBy"`
+	AssignedAt time.Time           `json:"assignedAt" bson:"assignedAt"`
+}
+
+// Dependency points at a subtask, possibly in another goal, that has to be
+// completed before the dependent subtask can start
+type Dependency struct {
+	GoalID    primitive.ObjectID `json:"goalId" bson:"goalId"`
+	SubTaskID primitive.ObjectID `json:"subTaskId" bson:"subTaskId"`
+}
+
+// SubTask represents a subtask within a goal. Blocked is computed for
+// responses and is true while any of its dependencies is open.
 type SubTask struct {
-	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Title       string             `json:"title" bson:"title" validate:"required"`
-	Description string             `json:"description,omitempty" bson:"description,omitempty"`
-	Completed   bool               `json:"completed" bson:"completed"`
-	DueDate     *time.Time         `json:"dueDate,omitempty" bson:"dueDate,omitempty"`
-	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
-	UpdatedAt   time.Time          `json:"updatedAt" bson:"updatedAt"`
+	ID          primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	Title       string              `json:"title" bson:"title" validate:"required"`
+	Description string              `json:"description,omitempty" bson:"description,omitempty"`
+	Completed   bool                `json:"completed" bson:"completed"`
+	CompletedAt *time.Time          `json:"completedAt,omitempty" bson:"completedAt,omitempty"`
+	DueDate     *time.Time          `json:"dueDate,omitempty" bson:"dueDate,omitempty"`
+	Tags        []string            `json:"tags,omitempty" bson:"tags,omitempty"`
+	Priority    string              `json:"priority,omitempty" bson:"priority,omitempty"`
+	Weight      *float64            `json:"weight,omitempty" bson:"weight,omitempty"`
+	Estimate    *float64            `json:"estimate,omitempty" bson:"estimate,omitempty"`
+	DependsOn   []Dependency        `json:"dependsOn,omitempty" bson:"dependsOn,omitempty"`
+	Blocked     bool                `json:"blocked" bson:"-"`
+	AssigneeID  *primitive.ObjectID `json:"assigneeId,omitempty" bson:"assigneeId,omitempty"`
+	Assignments []Assignment        `json:"assignments,omitempty" bson:"assignments,omitempty"`
+	CreatedAt   time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+// Assign sets the subtask's assignee and records the change in its
+// assignment history. It reports whether the assignee changed.
+func (t *SubTask) Assign(assigneeID *primitive.ObjectID, by primitive.ObjectID, at time.Time) bool {
+	if sameObjectID(t.AssigneeID, assigneeID) {
+		return false
+	}
+
+	t.AssigneeID = assigneeID
+	t.Assignments = append(t.Assignments, Assignment{
+		AssigneeID: assigneeID,
+		AssignedBy: by,
+		AssignedAt: at,
+	})
+	return true
+}
+
+// sameObjectID compares two optional IDs
+func sameObjectID(a, b *primitive.ObjectID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
 }
 
-// Goal represents a user's goal
+// Goal represents a user's goal. Habit goals have a Type of habit and
+// track Habit check-ins instead of subtasks. Archived goals are left out of
+// the goal list.
 type Goal struct {
-	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	UserID      primitive.ObjectID `json:"userId" bson:"userId"`
-	Title       string             `json:"title" bson:"title" validate:"required"`
-	Description string             `json:"description,omitempty" bson:"description,omitempty"`
-	SubTasks    []SubTask          `json:"subTasks" bson:"subTasks"`
-	StartDate   time.Time          `json:"startDate" bson:"startDate"`
-	EndDate     *time.Time         `json:"endDate,omitempty" bson:"endDate,omitempty"`
-	Completed   bool               `json:"completed" bson:"completed"`
-	Progress    float64            `json:"progress" bson:"progress"`
-	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
-	UpdatedAt   time.Time          `json:"updatedAt" bson:"updatedAt"`
-}
-
-// CalculateProgress calculates the progress of a goal based on completed subtasks
+	ID           primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	UserID       primitive.ObjectID  `json:"userId" bson:"userId"`
+	WorkspaceID  *primitive.ObjectID `json:"workspaceId,omitempty" bson:"workspaceId,omitempty"`
+	ParentID     *primitive.ObjectID `json:"parentId,omitempty" bson:"parentId,omitempty"`
+	Type         string              `json:"type,omitempty" bson:"type,omitempty"`
+	Title        string              `json:"title" bson:"title" validate:"required"`
+	Description  string              `json:"description,omitempty" bson:"description,omitempty"`
+	Habit        *Habit              `json:"habit,omitempty" bson:"habit,omitempty"`
+	SubTasks     []SubTask           `json:"subTasks" bson:"subTasks"`
+	KeyResults   []KeyResult         `json:"keyResults,omitempty" bson:"keyResults,omitempty"`
+	EstimateUnit string              `json:"estimateUnit,omitempty" bson:"estimateUnit,omitempty"`
+	Members      []GoalMember        `json:"members,omitempty" bson:"members,omitempty"`
+	Tags         []string            `json:"tags,omitempty" bson:"tags,omitempty"`
+	Priority     string              `json:"priority,omitempty" bson:"priority,omitempty"`
+	StartDate    time.Time           `json:"startDate" bson:"startDate"`
+	EndDate      *time.Time          `json:"endDate,omitempty" bson:"endDate,omitempty"`
+	Completed    bool                `json:"completed" bson:"completed"`
+	CompletedAt  *time.Time          `json:"completedAt,omitempty" bson:"completedAt,omitempty"`
+	Archived     bool                `json:"archived,omitempty" bson:"archived,omitempty"`
+	ArchivedAt   *time.Time          `json:"archivedAt,omitempty" bson:"archivedAt,omitempty"`
+	Progress     float64             `json:"progress" bson:"progress"`
+	CreatedAt    time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt    time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+// CalculateProgress calculates the progress of a goal as a weighted average
+// of its subtasks, each counting as done or not, and its key results
 func (g *Goal) CalculateProgress() {
-	if len(g.SubTasks) == 0 {
+	total, weight := 0.0, 0.0
+	for i := range g.SubTasks {
+		taskWeight := g.SubTasks[i].ProgressWeight()
+		if g.SubTasks[i].Completed {
+			total += 100 * taskWeight
+		}
+		weight += taskWeight
+	}
+	for i := range g.KeyResults {
+		total += g.KeyResults[i].Progress() * g.KeyResults[i].Weight
+		weight += g.KeyResults[i].Weight
+	}
+
+	if weight == 0 {
 		g.Progress = 0
 		return
 	}
+	g.Progress = total / weight
+}
+
+// hasWork reports whether the goal has subtasks or key results to measure
+// its progress by
+func (g *Goal) hasWork() bool {
+	return len(g.SubTasks) > 0 || len(g.KeyResults) > 0
+}
+
+// RollUpProgress combines the goal's own progress with the rolled-up
+// progress of its sub-goals. The goal's own subtasks and key results, if it
+// has any, count as one part alongside each sub-goal; completed goals count
+// as done.
+func (g *Goal) RollUpProgress(children []float64) float64 {
+	if g.Completed {
+		return 100
+	}
 
-	completedCount := 0
-	for _, task := range g.SubTasks {
-		if task.Completed {
-			completedCount++
+	total, parts := 0.0, len(children)
+	for _, progress := range children {
+		total += progress
+	}
+	if g.hasWork() {
+		total += g.Progress
+		parts++
+	}
+	if parts == 0 {
+		return g.Progress
+	}
+
+	return total / float64(parts)
+}
+
+// RoleOf returns the role a user has on the goal, or an empty string when
+// the goal isn't shared with them
+func (g *Goal) RoleOf(userID primitive.ObjectID) string {
+	if g.UserID == userID {
+		return RoleOwner
+	}
+	for _, member := range g.Members {
+		if member.UserID == userID {
+			return member.Role
 		}
 	}
+	return ""
+}
 
-	g.Progress = float64(completedCount) / float64(len(g.SubTasks)) * 100
+// RemoveMember removes a member from the goal and unassigns their subtasks,
+// recording the unassignment in each subtask's assignment history. It
+// reports whether the user was a member.
+func (g *Goal) RemoveMember(userID, by primitive.ObjectID, now time.Time) bool {
+	members := make([]GoalMember, 0, len(g.Members))
+	for _, member := range g.Members {
+		if member.UserID != userID {
+			members = append(members, member)
+		}
+	}
+	if len(members) == len(g.Members) {
+		return false
+	}
+	g.Members = members
+
+	for i := range g.SubTasks {
+		if task := &g.SubTasks[i]; sameObjectID(task.AssigneeID, &userID) {
+			task.Assign(nil, by, now)
+		}
+	}
+	return true
+}
+
+// Clone returns a copy of the goal that can be modified without affecting
+// the original. Every nested slice is copied, down to each subtask's tags,
+// dependencies and assignments and each key result's check-ins; pointer
+// fields are shared, so replace them rather than writing through them.
+func (g *Goal) Clone() *Goal {
+	clone := *g
+	clone.Tags = cloneStrings(g.Tags)
+	if g.SubTasks != nil {
+		clone.SubTasks = make([]SubTask, len(g.SubTasks))
+		for i, task := range g.SubTasks {
+			task.Tags = cloneStrings(task.Tags)
+			if task.DependsOn != nil {
+				task.DependsOn = append(make([]Dependency, 0, len(task.DependsOn)), task.DependsOn...)
+			}
+			if task.Assignments != nil {
+				task.Assignments = append(make([]Assignment, 0, len(task.Assignments)), task.Assignments...)
+			}
+			clone.SubTasks[i] = task
+		}
+	}
+	if g.KeyResults != nil {
+		clone.KeyResults = make([]KeyResult, len(g.KeyResults))
+		for i, keyResult := range g.KeyResults {
+			if keyResult.CheckIns != nil {
+				keyResult.CheckIns = append(make([]CheckIn, 0, len(keyResult.CheckIns)), keyResult.CheckIns...)
+			}
+			clone.KeyResults[i] = keyResult
+		}
+	}
+	if g.Members != nil {
+		clone.Members = make([]GoalMember, len(g.Members))
+		copy(clone.Members, g.Members)
+	}
+	if g.Habit != nil {
+		habit := *g.Habit
+		habit.Weekdays = append([]int(nil), g.Habit.Weekdays...)
+		habit.CheckIns = append([]HabitCheckIn(nil), g.Habit.CheckIns...)
+		clone.Habit = &habit
+	}
+	return &clone
+}
+
+// cloneStrings copies a string slice, keeping nil as nil
+func cloneStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(values)), values...)
+}
+
+// Duplicate returns a deep copy of the goal with new IDs and its completion
+// reset: subtasks are reopened and unassigned, key results go back to their
+// start values and habit check-ins are dropped. Dependencies between the
+// goal's own subtasks point at the copies. Members aren't copied.
+func (g *Goal) Duplicate(now time.Time) Goal {
+	dup := *g.Clone()
+	dup.ID = primitive.NewObjectID()
+	dup.Members = nil
+	dup.Completed = false
+	dup.CompletedAt = nil
+	dup.Archived = false
+	dup.ArchivedAt = nil
+	dup.CreatedAt = now
+	dup.UpdatedAt = now
+
+	ids := make(map[primitive.ObjectID]primitive.ObjectID, len(dup.SubTasks))
+	for _, task := range dup.SubTasks {
+		ids[task.ID] = primitive.NewObjectID()
+	}
+	for i := range dup.SubTasks {
+		task := &dup.SubTasks[i]
+		task.ID = ids[task.ID]
+		task.Completed = false
+		task.CompletedAt = nil
+		task.AssigneeID = nil
+		task.Assignments = nil
+		task.CreatedAt = now
+		task.UpdatedAt = now
+
+		if task.DependsOn != nil {
+			dependsOn := make([]Dependency, 0, len(task.DependsOn))
+			for _, dependency := range task.DependsOn {
+				if dependency.GoalID == g.ID {
+					dependency = Dependency{GoalID: dup.ID, SubTaskID: ids[dependency.SubTaskID]}
+				}
+				dependsOn = append(dependsOn, dependency)
+			}
+			task.DependsOn = dependsOn
+		}
+	}
+
+	for i := range dup.KeyResults {
+		keyResult := &dup.KeyResults[i]
+		keyResult.ID = primitive.NewObjectID()
+		keyResult.CurrentValue = keyResult.StartValue
+		keyResult.CheckIns = nil
+		keyResult.CreatedAt = now
+		keyResult.UpdatedAt = now
+	}
+
+	if dup.Habit != nil {
+		dup.Habit.CheckIns = []HabitCheckIn{}
+	}
+
+	dup.CalculateProgress()
+	return dup
+}
+
+// FindSubTask returns a pointer to the subtask with the given ID, or nil
+func (g *Goal) FindSubTask(id primitive.ObjectID) *SubTask {
+	for i := range g.SubTasks {
+		if g.SubTasks[i].ID == id {
+			return &g.SubTasks[i]
+		}
+	}
+	return nil
+}
+
+// FindKeyResult returns a pointer to the key result with the given ID, or nil
+func (g *Goal) FindKeyResult(id primitive.ObjectID) *KeyResult {
+	for i := range g.KeyResults {
+		if g.KeyResults[i].ID == id {
+			return &g.KeyResults[i]
+		}
+	}
+	return nil
 }
 
-// IsCompleted checks if all subtasks are completed and updates the goal status
+// IsCompleted checks if all subtasks are completed and all key results
+// achieved, and updates the goal status
 func (g *Goal) IsCompleted() bool {
-	if len(g.SubTasks) == 0 {
+	if !g.hasWork() {
 		return false
 	}
 
@@ -61,7 +362,31 @@ func (g *Goal) IsCompleted() bool {
 			return false
 		}
 	}
+	for i := range g.KeyResults {
+		if !g.KeyResults[i].Achieved() {
+			g.Completed = false
+			return false
+		}
+	}
 
 	g.Completed = true
 	return true
 }
+
+// UpdateCompletion recalculates progress and completion after subtasks or
+// key results change, stamping or clearing CompletedAt when the completion
+// state flips
+func (g *Goal) UpdateCompletion(now time.Time) {
+	g.CalculateProgress()
+
+	if !g.hasWork() {
+		return
+	}
+
+	wasCompleted := g.Completed
+	if g.IsCompleted() && !wasCompleted {
+		g.CompletedAt = &now
+	} else if !g.Completed {
+		g.CompletedAt = nil
+	}
+}
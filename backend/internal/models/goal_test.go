@@ -0,0 +1,77 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGoalClone(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	assignee := primitive.NewObjectID()
+
+	goal := &Goal{
+		ID:    primitive.NewObjectID(),
+		Title: "Launch",
+		Tags:  []string{"work"},
+		SubTasks: []SubTask{{
+			ID:          primitive.NewObjectID(),
+			Title:       "Build",
+			Tags:        []string{"web"},
+			DependsOn:   []Dependency{{GoalID: primitive.NewObjectID(), SubTaskID: primitive.NewObjectID()}},
+			AssigneeID:  &assignee,
+			Assignments: []Assignment{{AssigneeID: &assignee, AssignedAt: now}},
+		}},
+		KeyResults: []KeyResult{{ID: primitive.NewObjectID(), CheckIns: []CheckIn{{Value: 1, CreatedAt: now}}}},
+		Members:    []GoalMember{{UserID: assignee, Role: RoleEditor}},
+		Habit:      &Habit{Weekdays: []int{1}, CheckIns: []HabitCheckIn{{Date: "2026-03-09"}}},
+	}
+
+	clone := goal.Clone()
+	if !reflect.DeepEqual(clone, goal) {
+		t.Fatalf("Clone() = %+v, want a copy of %+v", clone, goal)
+	}
+
+	clone.Tags[0] = "changed"
+	clone.SubTasks[0].Title = "changed"
+	clone.SubTasks[0].Tags[0] = "changed"
+	clone.SubTasks[0].DependsOn[0].SubTaskID = primitive.NilObjectID
+	clone.SubTasks[0].Assignments[0].AssignedAt = time.Time{}
+	clone.KeyResults[0].CheckIns[0].Value = 99
+	clone.Members[0].Role = RoleViewer
+	clone.Habit.Weekdays[0] = 5
+	clone.Habit.CheckIns[0].Date = "changed"
+
+	task := goal.SubTasks[0]
+	switch {
+	case goal.Tags[0] != "work":
+		t.Error("changing the clone's tags changed the original")
+	case task.Title != "Build" || task.Tags[0] != "web":
+		t.Error("changing the clone's subtask changed the original")
+	case task.DependsOn[0].SubTaskID.IsZero():
+		t.Error("changing the clone's dependencies changed the original")
+	case task.Assignments[0].AssignedAt.IsZero():
+		t.Error("changing the clone's assignments changed the original")
+	case goal.KeyResults[0].CheckIns[0].Value != 1:
+		t.Error("changing the clone's key result check-ins changed the original")
+	case goal.Members[0].Role != RoleEditor:
+		t.Error("changing the clone's members changed the original")
+	case goal.Habit.Weekdays[0] != 1 || goal.Habit.CheckIns[0].Date != "2026-03-09":
+		t.Error("changing the clone's habit changed the original")
+	}
+
+	// Appending to a copied slice must not write into the original's spare capacity
+	goal.SubTasks[0].Tags = append(make([]string, 0, 4), "web")
+	clone = goal.Clone()
+	clone.SubTasks[0].Tags = append(clone.SubTasks[0].Tags, "extra")
+	if extended := goal.SubTasks[0].Tags[:2]; extended[1] == "extra" {
+		t.Error("appending to the clone's tags wrote into the original")
+	}
+
+	empty := (&Goal{}).Clone()
+	if empty.Tags != nil || empty.SubTasks != nil || empty.KeyResults != nil || empty.Members != nil || empty.Habit != nil {
+		t.Errorf("Clone() of an empty goal = %+v, want nil slices kept nil", empty)
+	}
+}
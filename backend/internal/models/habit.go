@@ -0,0 +1,270 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GoalTypeHabit marks goals that are tracked by recurring check-ins rather
+// than subtasks
+const GoalTypeHabit = "habit"
+
+// Habit frequencies
+const (
+	HabitDaily    = "daily"
+	HabitWeekly   = "weekly"
+	HabitWeekdays = "weekdays"
+)
+
+// Habit period statuses. Frozen periods were missed but excused by the
+// freeze policy.
+const (
+	HabitDone    = "done"
+	HabitMissed  = "missed"
+	HabitFrozen  = "frozen"
+	HabitPending = "pending"
+)
+
+// HabitDateLayout formats the calendar days habits are checked in on
+const HabitDateLayout = "2006-01-02"
+
+// HabitCheckIn records that a habit was done on a calendar day in the
+// user's time zone
+type HabitCheckIn struct {
+	Date      string             `json:"date" bson:"date"`
+	Note      string             `json:"note,omitempty" bson:"note,omitempty"`
+	UserID    primitive.ObjectID `json:"userId" bson:"userId"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// Habit holds a habit goal's target frequency and check-ins. Weekly habits
+// are due TimesPerWeek times in each Monday week; weekday habits on each of
+// Weekdays, where 0 is Sunday. FreezesPerMonth missed periods a month are
+// excused and don't break a streak.
+type Habit struct {
+	Frequency       string         `json:"frequency" bson:"frequency"`
+	TimesPerWeek    int            `json:"timesPerWeek,omitempty" bson:"timesPerWeek,omitempty"`
+	Weekdays        []int          `json:"weekdays,omitempty" bson:"weekdays,omitempty"`
+	FreezesPerMonth int            `json:"freezesPerMonth,omitempty" bson:"freezesPerMonth,omitempty"`
+	CheckIns        []HabitCheckIn `json:"checkIns" bson:"checkIns"`
+}
+
+// HabitPeriod is one day, or week for weekly habits, in which a habit was
+// due
+type HabitPeriod struct {
+	Start  string `json:"start"`
+	End    string `json:"end"`
+	Target int    `json:"target"`
+	Count  int    `json:"count"`
+	Status string `json:"status"`
+}
+
+// Validate checks the habit's frequency settings, clearing those its
+// frequency doesn't use and sorting its weekdays
+func (h *Habit) Validate() error {
+	if h.Frequency != HabitWeekly {
+		h.TimesPerWeek = 0
+	}
+	if h.Frequency != HabitWeekdays {
+		h.Weekdays = nil
+	}
+
+	switch h.Frequency {
+	case HabitDaily:
+	case HabitWeekly:
+		if h.TimesPerWeek < 1 || h.TimesPerWeek > 7 {
+			return errors.New("timesPerWeek must be between 1 and 7")
+		}
+	case HabitWeekdays:
+		if len(h.Weekdays) == 0 {
+			return errors.New("weekdays are required")
+		}
+		seen := make(map[int]bool, len(h.Weekdays))
+		weekdays := make([]int, 0, len(h.Weekdays))
+		for _, day := range h.Weekdays {
+			if day < 0 || day > 6 {
+				return errors.New("weekdays must be between 0 (Sunday) and 6 (Saturday)")
+			}
+			if !seen[day] {
+				seen[day] = true
+				weekdays = append(weekdays, day)
+			}
+		}
+		sort.Ints(weekdays)
+		h.Weekdays = weekdays
+	default:
+		return errors.New("frequency must be daily, weekly or weekdays")
+	}
+
+	if h.FreezesPerMonth < 0 || h.FreezesPerMonth > 31 {
+		return errors.New("freezesPerMonth must be between 0 and 31")
+	}
+	return nil
+}
+
+// FindCheckIn returns a pointer to the check-in on the given day, or nil
+func (h *Habit) FindCheckIn(date string) *HabitCheckIn {
+	for i := range h.CheckIns {
+		if h.CheckIns[i].Date == date {
+			return &h.CheckIns[i]
+		}
+	}
+	return nil
+}
+
+// StreakUnit names what a streak of the habit counts
+func (h *Habit) StreakUnit() string {
+	if h.Frequency == HabitWeekly {
+		return "weeks"
+	}
+	return "days"
+}
+
+// Periods lists the periods in which the habit was due from start up to
+// and including end. All three days are midnights in the user's time zone;
+// periods that end on or after today are pending until they are done.
+func (h *Habit) Periods(start, end, today time.Time) []HabitPeriod {
+	checkedIn := make(map[string]bool, len(h.CheckIns))
+	for _, checkIn := range h.CheckIns {
+		checkedIn[checkIn.Date] = true
+	}
+
+	var periods []HabitPeriod
+	switch h.Frequency {
+	case HabitWeekly:
+		week := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		for ; !week.After(end); week = week.AddDate(0, 0, 7) {
+			last := week.AddDate(0, 0, 6)
+			period := HabitPeriod{Start: week.Format(HabitDateLayout), End: last.Format(HabitDateLayout), Target: h.TimesPerWeek}
+			days := 0
+			for day := week; !day.After(last); day = day.AddDate(0, 0, 1) {
+				if day.Before(start) {
+					continue
+				}
+				days++
+				if checkedIn[day.Format(HabitDateLayout)] {
+					period.Count++
+				}
+			}
+			// A habit starting mid-week can't be due more often than it has days
+			if days < period.Target {
+				period.Target = days
+			}
+			period.Status = periodStatus(period, !last.Before(today))
+			periods = append(periods, period)
+		}
+	default:
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			if !h.dueOn(day.Weekday()) {
+				continue
+			}
+			date := day.Format(HabitDateLayout)
+			period := HabitPeriod{Start: date, End: date, Target: 1}
+			if checkedIn[date] {
+				period.Count = 1
+			}
+			period.Status = periodStatus(period, !day.Before(today))
+			periods = append(periods, period)
+		}
+	}
+
+	h.applyFreezes(periods)
+	return periods
+}
+
+// dueOn reports whether a daily or weekday habit is due on a weekday
+func (h *Habit) dueOn(weekday time.Weekday) bool {
+	if h.Frequency != HabitWeekdays {
+		return true
+	}
+	for _, day := range h.Weekdays {
+		if time.Weekday(day) == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// applyFreezes excuses the first FreezesPerMonth missed periods starting in
+// each calendar month
+func (h *Habit) applyFreezes(periods []HabitPeriod) {
+	if h.FreezesPerMonth == 0 {
+		return
+	}
+
+	used := make(map[string]int)
+	for i := range periods {
+		if periods[i].Status != HabitMissed {
+			continue
+		}
+		month := periods[i].Start[:7]
+		if used[month] < h.FreezesPerMonth {
+			used[month]++
+			periods[i].Status = HabitFrozen
+		}
+	}
+}
+
+// periodStatus decides whether a period was done, missed or is still open
+func periodStatus(period HabitPeriod, open bool) string {
+	switch {
+	case period.Count >= period.Target:
+		return HabitDone
+	case open:
+		return HabitPending
+	default:
+		return HabitMissed
+	}
+}
+
+// HabitStreaks returns the current and longest runs of done periods. Frozen
+// periods keep a streak alive without adding to it, and a pending period
+// doesn't break the current streak.
+func HabitStreaks(periods []HabitPeriod) (current, longest int) {
+	run := 0
+	for _, period := range periods {
+		switch period.Status {
+		case HabitDone:
+			run++
+		case HabitMissed:
+			run = 0
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+
+	for i := len(periods) - 1; i >= 0; i-- {
+		status := periods[i].Status
+		if status == HabitMissed {
+			break
+		}
+		if status == HabitDone {
+			current++
+		}
+	}
+	return current, longest
+}
+
+// HabitCompletion returns the percentage of periods that were done, leaving
+// out frozen periods and those still pending
+func HabitCompletion(periods []HabitPeriod) float64 {
+	done, due := 0, 0
+	for _, period := range periods {
+		switch period.Status {
+		case HabitDone:
+			done++
+			due++
+		case HabitMissed:
+			due++
+		}
+	}
+
+	if due == 0 {
+		return 0
+	}
+	return float64(done) * 100 / float64(due)
+}
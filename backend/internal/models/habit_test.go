@@ -0,0 +1,241 @@
+package models
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// statusLetters abbreviates period statuses so a run of periods reads as a
+// string such as "ddmf"
+var statusLetters = map[string]string{
+	HabitDone:    "d",
+	HabitMissed:  "m",
+	HabitFrozen:  "f",
+	HabitPending: "p",
+}
+
+func statuses(periods []HabitPeriod) string {
+	var b strings.Builder
+	for _, period := range periods {
+		b.WriteString(statusLetters[period.Status])
+	}
+	return b.String()
+}
+
+func periodsFor(letters string) []HabitPeriod {
+	periods := make([]HabitPeriod, 0, len(letters))
+	for _, letter := range letters {
+		for status, l := range statusLetters {
+			if l == string(letter) {
+				periods = append(periods, HabitPeriod{Status: status})
+			}
+		}
+	}
+	return periods
+}
+
+func day(date string) time.Time {
+	t, err := time.Parse(HabitDateLayout, date)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestHabitPeriods(t *testing.T) {
+	tests := []struct {
+		name             string
+		habit            Habit
+		start, end, now  string
+		checkIns         []string
+		want             string
+		starts           []string
+		targets          []int
+		current, longest int
+		completion       float64
+	}{
+		{
+			name:  "daily",
+			habit: Habit{Frequency: HabitDaily},
+			start: "2026-03-01", end: "2026-03-05", now: "2026-03-05",
+			checkIns: []string{"2026-03-01", "2026-03-02", "2026-03-04", "2026-03-05"},
+			want:     "ddmdd",
+			current:  2, longest: 2, completion: 80,
+		},
+		{
+			name:  "today is pending",
+			habit: Habit{Frequency: HabitDaily},
+			start: "2026-03-01", end: "2026-03-03", now: "2026-03-03",
+			checkIns: []string{"2026-03-01", "2026-03-02"},
+			want:     "ddp",
+			current:  2, longest: 2, completion: 100,
+		},
+		{
+			name:  "weekdays",
+			habit: Habit{Frequency: HabitWeekdays, Weekdays: []int{1, 3, 5}},
+			start: "2026-03-02", end: "2026-03-08", now: "2026-03-09",
+			checkIns: []string{"2026-03-02", "2026-03-03", "2026-03-06"},
+			want:     "dmd",
+			starts:   []string{"2026-03-02", "2026-03-04", "2026-03-06"},
+			current:  1, longest: 1, completion: 200.0 / 3,
+		},
+		{
+			name:  "weekly starting mid-week",
+			habit: Habit{Frequency: HabitWeekly, TimesPerWeek: 3},
+			start: "2026-03-07", end: "2026-03-15", now: "2026-03-16",
+			checkIns: []string{"2026-03-06", "2026-03-07", "2026-03-08", "2026-03-09", "2026-03-11"},
+			want:     "dm",
+			starts:   []string{"2026-03-02", "2026-03-09"},
+			targets:  []int{2, 3},
+			current:  0, longest: 1, completion: 50,
+		},
+		{
+			name:  "current week is pending",
+			habit: Habit{Frequency: HabitWeekly, TimesPerWeek: 2},
+			start: "2026-03-02", end: "2026-03-15", now: "2026-03-12",
+			checkIns: []string{"2026-03-02", "2026-03-05", "2026-03-10"},
+			want:     "dp",
+			current:  1, longest: 1, completion: 100,
+		},
+		{
+			name:  "freezes reset each month",
+			habit: Habit{Frequency: HabitDaily, FreezesPerMonth: 1},
+			start: "2026-01-30", end: "2026-02-03", now: "2026-02-04",
+			checkIns: []string{"2026-01-31", "2026-02-03"},
+			want:     "fdfmd",
+			current:  1, longest: 1, completion: 200.0 / 3,
+		},
+		{
+			name:  "frozen days keep a streak",
+			habit: Habit{Frequency: HabitDaily, FreezesPerMonth: 2},
+			start: "2026-01-30", end: "2026-02-02", now: "2026-02-03",
+			checkIns: []string{"2026-01-31", "2026-02-02"},
+			want:     "fdfd",
+			current:  2, longest: 2, completion: 100,
+		},
+		{
+			name:  "weeks count against the month they start in",
+			habit: Habit{Frequency: HabitWeekly, TimesPerWeek: 1, FreezesPerMonth: 1},
+			start: "2026-03-23", end: "2026-04-12", now: "2026-04-13",
+			want:   "fmf",
+			starts: []string{"2026-03-23", "2026-03-30", "2026-04-06"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			habit := tt.habit
+			for _, date := range tt.checkIns {
+				habit.CheckIns = append(habit.CheckIns, HabitCheckIn{Date: date})
+			}
+
+			periods := habit.Periods(day(tt.start), day(tt.end), day(tt.now))
+			if got := statuses(periods); got != tt.want {
+				t.Fatalf("statuses = %q, want %q", got, tt.want)
+			}
+			if tt.starts != nil {
+				starts := make([]string, len(periods))
+				for i, period := range periods {
+					starts[i] = period.Start
+				}
+				if !reflect.DeepEqual(starts, tt.starts) {
+					t.Errorf("period starts = %v, want %v", starts, tt.starts)
+				}
+			}
+			if tt.targets != nil {
+				targets := make([]int, len(periods))
+				for i, period := range periods {
+					targets[i] = period.Target
+				}
+				if !reflect.DeepEqual(targets, tt.targets) {
+					t.Errorf("targets = %v, want %v", targets, tt.targets)
+				}
+			}
+
+			current, longest := HabitStreaks(periods)
+			if current != tt.current || longest != tt.longest {
+				t.Errorf("streaks = %d, %d, want %d, %d", current, longest, tt.current, tt.longest)
+			}
+			if got := HabitCompletion(periods); math.Abs(got-tt.completion) > 1e-9 {
+				t.Errorf("completion = %v, want %v", got, tt.completion)
+			}
+		})
+	}
+}
+
+func TestHabitStreaks(t *testing.T) {
+	tests := []struct {
+		periods          string
+		current, longest int
+	}{
+		{"", 0, 0},
+		{"ppp", 0, 0},
+		{"dddp", 3, 3},
+		{"ddmd", 1, 2},
+		{"dddm", 0, 3},
+		{"dfdfd", 3, 3},
+		{"ffmdd", 2, 2},
+	}
+	for _, tt := range tests {
+		current, longest := HabitStreaks(periodsFor(tt.periods))
+		if current != tt.current || longest != tt.longest {
+			t.Errorf("HabitStreaks(%q) = %d, %d, want %d, %d", tt.periods, current, longest, tt.current, tt.longest)
+		}
+	}
+}
+
+func TestHabitCompletion(t *testing.T) {
+	tests := []struct {
+		periods string
+		want    float64
+	}{
+		{"", 0},
+		{"fp", 0},
+		{"ddmm", 50},
+		{"dfdp", 100},
+		{"mmmd", 25},
+	}
+	for _, tt := range tests {
+		if got := HabitCompletion(periodsFor(tt.periods)); got != tt.want {
+			t.Errorf("HabitCompletion(%q) = %v, want %v", tt.periods, got, tt.want)
+		}
+	}
+}
+
+func TestHabitValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		habit    Habit
+		wantErr  bool
+		weekdays []int
+		times    int
+	}{
+		{name: "daily", habit: Habit{Frequency: HabitDaily, TimesPerWeek: 3, Weekdays: []int{1}}},
+		{name: "weekly", habit: Habit{Frequency: HabitWeekly, TimesPerWeek: 7}, times: 7},
+		{name: "weekly without times", habit: Habit{Frequency: HabitWeekly}, wantErr: true},
+		{name: "weekly too often", habit: Habit{Frequency: HabitWeekly, TimesPerWeek: 8}, wantErr: true},
+		{name: "weekdays sorted and deduplicated", habit: Habit{Frequency: HabitWeekdays, Weekdays: []int{5, 1, 5, 0}}, weekdays: []int{0, 1, 5}},
+		{name: "no weekdays", habit: Habit{Frequency: HabitWeekdays}, wantErr: true},
+		{name: "weekday out of range", habit: Habit{Frequency: HabitWeekdays, Weekdays: []int{7}}, wantErr: true},
+		{name: "unknown frequency", habit: Habit{Frequency: "hourly"}, wantErr: true},
+		{name: "negative freezes", habit: Habit{Frequency: HabitDaily, FreezesPerMonth: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			habit := tt.habit
+			err := habit.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(habit.Weekdays, tt.weekdays) || habit.TimesPerWeek != tt.times {
+				t.Errorf("Validate() left weekdays %v and timesPerWeek %d, want %v and %d", habit.Weekdays, habit.TimesPerWeek, tt.weekdays, tt.times)
+			}
+		})
+	}
+}
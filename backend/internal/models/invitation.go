@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Invitation statuses
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+	InvitationRevoked  = "revoked"
+)
+
+// Invitation represents an invitation to collaborate on a goal or to join a
+// workspace; exactly one of GoalID and WorkspaceID is set. Invitations to an
+// email address that isn't registered yet are matched on acceptance.
+type Invitation struct {
+	ID            primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	GoalID        *primitive.ObjectID `json:"goalId,omitempty" bson:"goalId,omitempty"`
+	GoalTitle     string              `json:"goalTitle,omitempty" bson:"goalTitle,omitempty"`
+	WorkspaceID   *primitive.ObjectID `json:"workspaceId,omitempty" bson:"workspaceId,omitempty"`
+	WorkspaceName string              `json:"workspaceName,omitempty" bson:"workspaceName,omitempty"`
+	InviterID     primitive.ObjectID  `json:"inviterId" bson:"inviterId"`
+	InviteeID     *primitive.ObjectID `json:"inviteeId,omitempty" bson:"inviteeId,omitempty"`
+	Email         string              `json:"email,omitempty" bson:"email,omitempty"`
+	Role          string              `json:"role" bson:"role"`
+	Status        string              `json:"status" bson:"status"`
+	CreatedAt     time.Time           `json:"createdAt" bson:"createdAt"`
+	RespondedAt   *time.Time          `json:"respondedAt,omitempty" bson:"respondedAt,omitempty"`
+}
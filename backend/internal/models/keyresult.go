@@ -0,0 +1,85 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Key result directions
+const (
+	DirectionIncrease = "increase"
+	DirectionDecrease = "decrease"
+)
+
+// CheckIn records a measurement of a key result
+type CheckIn struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Value     float64            `json:"value" bson:"value"`
+	Note      string             `json:"note,omitempty" bson:"note,omitempty"`
+	UserID    primitive.ObjectID `json:"userId" bson:"userId"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// KeyResult is a measurable outcome of a goal, such as "run 500 km". Its
+// progress is how far CurrentValue has moved from StartValue towards
+// TargetValue. Weight sets its share of the goal's progress.
+type KeyResult struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Title        string             `json:"title" bson:"title"`
+	StartValue   float64            `json:"startValue" bson:"startValue"`
+	TargetValue  float64            `json:"targetValue" bson:"targetValue"`
+	CurrentValue float64            `json:"currentValue" bson:"currentValue"`
+	Unit         string             `json:"unit,omitempty" bson:"unit,omitempty"`
+	Direction    string             `json:"direction" bson:"direction"`
+	Weight       float64            `json:"weight" bson:"weight"`
+	CheckIns     []CheckIn          `json:"checkIns,omitempty" bson:"checkIns,omitempty"`
+	CreatedAt    time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt    time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// Validate checks that the key result's target lies in its direction from
+// its start and that its weight is positive
+func (k *KeyResult) Validate() error {
+	switch k.Direction {
+	case DirectionIncrease:
+		if k.TargetValue <= k.StartValue {
+			return errors.New("targetValue must be greater than startValue for an increasing key result")
+		}
+	case DirectionDecrease:
+		if k.TargetValue >= k.StartValue {
+			return errors.New("targetValue must be less than startValue for a decreasing key result")
+		}
+	default:
+		return errors.New("direction must be increase or decrease")
+	}
+	if k.Weight <= 0 {
+		return errors.New("weight must be positive")
+	}
+	return nil
+}
+
+// Progress returns how far the key result is towards its target, from 0 to
+// 100. Values past the target count as done and values behind the start as
+// not started.
+func (k *KeyResult) Progress() float64 {
+	span := k.TargetValue - k.StartValue
+	if span == 0 {
+		return 0
+	}
+
+	progress := (k.CurrentValue - k.StartValue) / span * 100
+	if progress < 0 {
+		return 0
+	}
+	if progress > 100 {
+		return 100
+	}
+	return progress
+}
+
+// Achieved reports whether the key result has reached its target
+func (k *KeyResult) Achieved() bool {
+	return k.Progress() >= 100
+}
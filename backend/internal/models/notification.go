@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Notification types
+const (
+	NotificationMention = "mention"
+)
+
+// Notification represents something a user should be told about, such as
+// being mentioned in a comment
+type Notification struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID  `json:"userId" bson:"userId"`
+	Type      string              `json:"type" bson:"type"`
+	ActorID   primitive.ObjectID  `json:"actorId" bson:"actorId"`
+	GoalID    primitive.ObjectID  `json:"goalId" bson:"goalId"`
+	SubTaskID *primitive.ObjectID `json:"subTaskId,omitempty" bson:"subTaskId,omitempty"`
+	CommentID *primitive.ObjectID `json:"commentId,omitempty" bson:"commentId,omitempty"`
+	Message   string              `json:"message" bson:"message"`
+	Read      bool                `json:"read" bson:"read"`
+	ReadAt    *time.Time          `json:"readAt,omitempty" bson:"readAt,omitempty"`
+	CreatedAt time.Time           `json:"createdAt" bson:"createdAt"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Sources of progress history entries
+const (
+	ProgressSourceChange   = "change"
+	ProgressSourceSnapshot = "snapshot"
+)
+
+// ProgressEntry records a goal's progress at a point in time
+type ProgressEntry struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	GoalID         primitive.ObjectID `json:"goalId" bson:"goalId"`
+	UserID         primitive.ObjectID `json:"userId" bson:"userId"`
+	Progress       float64            `json:"progress" bson:"progress"`
+	Completed      bool               `json:"completed" bson:"completed"`
+	TotalTasks     int                `json:"totalTasks" bson:"totalTasks"`
+	CompletedTasks int                `json:"completedTasks" bson:"completedTasks"`
+	Source         string             `json:"source" bson:"source"`
+	Day            string             `json:"day,omitempty" bson:"day,omitempty"`
+	RecordedAt     time.Time          `json:"recordedAt" bson:"recordedAt"`
+	GoalDeletedAt  *time.Time         `json:"-" bson:"goalDeletedAt,omitempty"`
+}
+
+// NewProgressEntry captures the current progress of a goal
+func NewProgressEntry(goal *Goal, source string, at time.Time) ProgressEntry {
+	entry := ProgressEntry{
+		ID:         primitive.NewObjectID(),
+		GoalID:     goal.ID,
+		UserID:     goal.UserID,
+		Progress:   goal.Progress,
+		Completed:  goal.Completed,
+		TotalTasks: len(goal.SubTasks),
+		Source:     source,
+		RecordedAt: at,
+	}
+
+	for _, task := range goal.SubTasks {
+		if task.Completed {
+			entry.CompletedTasks++
+		}
+	}
+
+	return entry
+}
+
+// SameProgress reports whether two entries describe the same state, so
+// unchanged edits don't add noise to the history
+func (e ProgressEntry) SameProgress(other ProgressEntry) bool {
+	return e.Progress == other.Progress &&
+		e.Completed == other.Completed &&
+		e.TotalTasks == other.TotalTasks &&
+		e.CompletedTasks == other.CompletedTasks
+}
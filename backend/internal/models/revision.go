@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Revision is a full snapshot of a goal, including its subtasks, taken after
+// a change. Numbers increase by one per goal.
+type Revision struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	GoalID    primitive.ObjectID  `json:"goalId" bson:"goalId"`
+	Number    int                 `json:"number" bson:"number"`
+	Action    string              `json:"action" bson:"action"`
+	ActorID   *primitive.ObjectID `json:"actorId,omitempty" bson:"actorId,omitempty"`
+	Goal      *Goal               `json:"goal,omitempty" bson:"goal,omitempty"`
+	CreatedAt time.Time           `json:"createdAt" bson:"createdAt"`
+
+	GoalDeletedAt *time.Time `json:"-" bson:"goalDeletedAt,omitempty"`
+}
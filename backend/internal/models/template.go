@@ -0,0 +1,122 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TemplateSubTask is a subtask in a goal template. Its due date is given
+// in days after the date the template is instantiated for.
+type TemplateSubTask struct {
+	Title         string   `json:"title" bson:"title" validate:"required,max=200"`
+	Description   string   `json:"description,omitempty" bson:"description,omitempty"`
+	Tags          []string `json:"tags,omitempty" bson:"tags,omitempty"`
+	DueOffsetDays *int     `json:"dueOffsetDays,omitempty" bson:"dueOffsetDays,omitempty"`
+	Weight        *float64 `json:"weight,omitempty" bson:"weight,omitempty" validate:"omitempty,gt=0"`
+	Estimate      *float64 `json:"estimate,omitempty" bson:"estimate,omitempty" validate:"omitempty,gte=0"`
+}
+
+// GoalTemplate is a reusable goal structure. Templates belong to their
+// creator; shared templates can be used by everyone in their workspace.
+type GoalTemplate struct {
+	ID            primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	UserID        primitive.ObjectID  `json:"userId" bson:"userId"`
+	WorkspaceID   *primitive.ObjectID `json:"workspaceId,omitempty" bson:"workspaceId,omitempty"`
+	Title         string              `json:"title" bson:"title"`
+	Description   string              `json:"description,omitempty" bson:"description,omitempty"`
+	Tags          []string            `json:"tags,omitempty" bson:"tags,omitempty"`
+	EstimateUnit  string              `json:"estimateUnit,omitempty" bson:"estimateUnit,omitempty"`
+	EndOffsetDays *int                `json:"endOffsetDays,omitempty" bson:"endOffsetDays,omitempty"`
+	SubTasks      []TemplateSubTask   `json:"subTasks" bson:"subTasks"`
+	Shared        bool                `json:"shared" bson:"shared"`
+	CreatedAt     time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt     time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+// Instantiate builds a new goal from the template that starts at anchor.
+// Due dates are the anchor moved by their offsets in calendar days, in the
+// anchor's time zone.
+func (t *GoalTemplate) Instantiate(anchor time.Time, now time.Time) Goal {
+	goal := Goal{
+		ID:           primitive.NewObjectID(),
+		Title:        t.Title,
+		Description:  t.Description,
+		Tags:         append([]string(nil), t.Tags...),
+		EstimateUnit: t.EstimateUnit,
+		SubTasks:     make([]SubTask, 0, len(t.SubTasks)),
+		StartDate:    anchor,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if t.EndOffsetDays != nil {
+		end := anchor.AddDate(0, 0, *t.EndOffsetDays)
+		goal.EndDate = &end
+	}
+
+	for _, item := range t.SubTasks {
+		task := SubTask{
+			ID:          primitive.NewObjectID(),
+			Title:       item.Title,
+			Description: item.Description,
+			Tags:        append([]string(nil), item.Tags...),
+			Weight:      item.Weight,
+			Estimate:    item.Estimate,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if item.DueOffsetDays != nil {
+			due := anchor.AddDate(0, 0, *item.DueOffsetDays)
+			task.DueDate = &due
+		}
+		goal.SubTasks = append(goal.SubTasks, task)
+	}
+	return goal
+}
+
+// NewTemplateFromGoal captures a goal's structure as a template. Due dates
+// become offsets in calendar days in loc from the goal's start date, or
+// from its creation when it has none.
+func NewTemplateFromGoal(goal *Goal, loc *time.Location) GoalTemplate {
+	anchor := goal.StartDate
+	if anchor.IsZero() {
+		anchor = goal.CreatedAt
+	}
+
+	template := GoalTemplate{
+		Title:        goal.Title,
+		Description:  goal.Description,
+		Tags:         append([]string(nil), goal.Tags...),
+		EstimateUnit: goal.EstimateUnit,
+		SubTasks:     make([]TemplateSubTask, 0, len(goal.SubTasks)),
+	}
+	if goal.EndDate != nil {
+		offset := DayOffset(anchor, *goal.EndDate, loc)
+		template.EndOffsetDays = &offset
+	}
+
+	for _, task := range goal.SubTasks {
+		item := TemplateSubTask{
+			Title:       task.Title,
+			Description: task.Description,
+			Tags:        append([]string(nil), task.Tags...),
+			Weight:      task.Weight,
+			Estimate:    task.Estimate,
+		}
+		if task.DueDate != nil {
+			offset := DayOffset(anchor, *task.DueDate, loc)
+			item.DueOffsetDays = &offset
+		}
+		template.SubTasks = append(template.SubTasks, item)
+	}
+	return template
+}
+
+// DayOffset returns how many calendar days in loc lie between from and to
+func DayOffset(from, to time.Time, loc *time.Location) int {
+	from, to = from.In(loc), to.In(loc)
+	// Counting between UTC midnights keeps daylight saving changes out
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(end.Sub(start) / (24 * time.Hour))
+}
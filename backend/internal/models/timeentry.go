@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Time entry sources
+const (
+	TimeSourceTimer  = "timer"
+	TimeSourceManual = "manual"
+)
+
+// Timer is a user's running timer on a subtask. It is keyed by the user's
+// ID, so each user can only have one.
+type Timer struct {
+	UserID      primitive.ObjectID  `json:"userId" bson:"_id"`
+	WorkspaceID *primitive.ObjectID `json:"workspaceId,omitempty" bson:"workspaceId,omitempty"`
+	GoalID      primitive.ObjectID  `json:"goalId" bson:"goalId"`
+	SubTaskID   primitive.ObjectID  `json:"subTaskId" bson:"subTaskId"`
+	Note        string              `json:"note,omitempty" bson:"note,omitempty"`
+	StartedAt   time.Time           `json:"startedAt" bson:"startedAt"`
+}
+
+// TimeEntry records time a user spent on a goal or one of its subtasks,
+// either from a stopped timer or entered by hand
+type TimeEntry struct {
+	ID          primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID  `json:"userId" bson:"userId"`
+	WorkspaceID *primitive.ObjectID `json:"workspaceId,omitempty" bson:"workspaceId,omitempty"`
+	GoalID      primitive.ObjectID  `json:"goalId" bson:"goalId"`
+	SubTaskID   *primitive.ObjectID `json:"subTaskId,omitempty" bson:"subTaskId,omitempty"`
+	Note        string              `json:"note,omitempty" bson:"note,omitempty"`
+	Source      string              `json:"source" bson:"source"`
+	StartedAt   time.Time           `json:"startedAt" bson:"startedAt"`
+	EndedAt     time.Time           `json:"endedAt" bson:"endedAt"`
+	Seconds     int64               `json:"seconds" bson:"seconds"`
+	CreatedAt   time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+// SetSpan sets the entry's start and end and the seconds between them
+func (e *TimeEntry) SetSpan(start, end time.Time) {
+	e.StartedAt = start
+	e.EndedAt = end
+	e.Seconds = int64(end.Sub(start) / time.Second)
+}
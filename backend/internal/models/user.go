@@ -7,7 +7,12 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-// User represents a user in our system
+// User represents a user in our system. Admin is only set directly in the
+// database and grants access to the admin routes.
+//
+// A changed email stays pending until it is verified. Tokens issued before
+// the last password change are revoked, and accounts with DeleteAfter set
+// are purged once it passes unless the deletion is cancelled.
 type User struct {
 	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	Username  string             `json:"username" bson:"username" validate:"required,min=3,max=30"`
@@ -15,8 +20,18 @@ type User struct {
 	Password  string             `json:"-" bson:"password" validate:"required,min=6"`
 	FirstName string             `json:"firstName,omitempty" bson:"firstName,omitempty"`
 	LastName  string             `json:"lastName,omitempty" bson:"lastName,omitempty"`
+	Timezone  string             `json:"timezone,omitempty" bson:"timezone,omitempty"`
+	Admin     bool               `json:"-" bson:"admin,omitempty"`
 	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
 	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+
+	PendingEmail        string     `json:"-" bson:"pendingEmail,omitempty"`
+	EmailTokenHash      string     `json:"-" bson:"emailTokenHash,omitempty"`
+	EmailTokenExpiresAt *time.Time `json:"-" bson:"emailTokenExpiresAt,omitempty"`
+	PasswordChangedAt   *time.Time `json:"-" bson:"passwordChangedAt,omitempty"`
+	TokenVersion        int        `json:"-" bson:"tokenVersion,omitempty"`
+	DeletionRequestedAt *time.Time `json:"-" bson:"deletionRequestedAt,omitempty"`
+	DeleteAfter         *time.Time `json:"-" bson:"deleteAfter,omitempty"`
 }
 
 // UserResponse is the response structure for a user without sensitive data
@@ -26,7 +41,11 @@ type UserResponse struct {
 	Email     string             `json:"email"`
 	FirstName string             `json:"firstName,omitempty"`
 	LastName  string             `json:"lastName,omitempty"`
+	Timezone  string             `json:"timezone,omitempty"`
 	CreatedAt time.Time          `json:"createdAt"`
+
+	PendingEmail string     `json:"pendingEmail,omitempty"`
+	DeleteAfter  *time.Time `json:"deleteAfter,omitempty"`
 }
 
 // HashPassword hashes the password using bcrypt
@@ -52,6 +71,29 @@ func (u *User) ToResponse() UserResponse {
 		Email:     u.Email,
 		FirstName: u.FirstName,
 		LastName:  u.LastName,
+		Timezone:  u.Timezone,
 		CreatedAt: u.CreatedAt,
+
+		PendingEmail: u.PendingEmail,
+		DeleteAfter:  u.DeleteAfter,
+	}
+}
+
+// SessionRevoked reports whether a token carrying tokenVersion was revoked
+// by a later password change, which bumps the user's token version
+func (u *User) SessionRevoked(tokenVersion int) bool {
+	return tokenVersion != u.TokenVersion
+}
+
+// Location returns the user's time zone, falling back to UTC when it is
+// unset or unknown
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
 	}
+	return loc
 }
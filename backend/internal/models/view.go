@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Smart views, by due date relative to the current day in the user's time
+// zone
+const (
+	ViewToday    = "today"
+	ViewUpcoming = "upcoming"
+	ViewOverdue  = "overdue"
+	ViewUndated  = "undated"
+)
+
+// View item kinds
+const (
+	ViewItemGoal    = "goal"
+	ViewItemSubTask = "subtask"
+)
+
+// ViewFilter describes which goals and subtasks a view lists. Empty fields
+// match everything; completed items are left out unless IncludeCompleted
+// is set. Query is a filter query the goals have to match.
+type ViewFilter struct {
+	Due              string   `json:"due,omitempty" bson:"due,omitempty" validate:"omitempty,oneof=today upcoming overdue undated"`
+	Kind             string   `json:"kind,omitempty" bson:"kind,omitempty" validate:"omitempty,oneof=goal subtask"`
+	Tags             []string `json:"tags,omitempty" bson:"tags,omitempty"`
+	Priorities       []string `json:"priorities,omitempty" bson:"priorities,omitempty" validate:"dive,oneof=low medium high urgent"`
+	Search           string   `json:"search,omitempty" bson:"search,omitempty" validate:"max=200"`
+	Query            string   `json:"query,omitempty" bson:"query,omitempty" validate:"max=1000"`
+	AssignedToMe     bool     `json:"assignedToMe,omitempty" bson:"assignedToMe,omitempty"`
+	IncludeCompleted bool     `json:"includeCompleted,omitempty" bson:"includeCompleted,omitempty"`
+}
+
+// SavedFilter is a named view filter. Saved filters are private to their
+// creator and belong to the workspace they were saved in.
+type SavedFilter struct {
+	ID          primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID  `json:"userId" bson:"userId"`
+	WorkspaceID *primitive.ObjectID `json:"workspaceId,omitempty" bson:"workspaceId,omitempty"`
+	Name        string              `json:"name" bson:"name"`
+	Filter      ViewFilter          `json:"filter" bson:"filter"`
+	Pinned      bool                `json:"pinned" bson:"pinned"`
+	CreatedAt   time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
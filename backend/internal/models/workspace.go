@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Workspace roles, from most to least privileged
+const (
+	WorkspaceRoleOwner  = "owner"
+	WorkspaceRoleAdmin  = "admin"
+	WorkspaceRoleMember = "member"
+	WorkspaceRoleGuest  = "guest"
+)
+
+var workspaceRoleRanks = map[string]int{
+	WorkspaceRoleGuest:  1,
+	WorkspaceRoleMember: 2,
+	WorkspaceRoleAdmin:  3,
+	WorkspaceRoleOwner:  4,
+}
+
+// WorkspaceRoleAtLeast reports whether role grants at least the permissions of min
+func WorkspaceRoleAtLeast(role, min string) bool {
+	return workspaceRoleRanks[role] > 0 && workspaceRoleRanks[role] >= workspaceRoleRanks[min]
+}
+
+// WorkspaceMember represents a user's membership in a workspace
+type WorkspaceMember struct {
+	UserID   primitive.ObjectID `json:"userId" bson:"userId"`
+	Role     string             `json:"role" bson:"role"`
+	JoinedAt time.Time          `json:"joinedAt" bson:"joinedAt"`
+}
+
+// Workspace represents a tenant that groups goals and members, such as a
+// department. The owner is also listed in Members.
+type Workspace struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name      string             `json:"name" bson:"name" validate:"required"`
+	OwnerID   primitive.ObjectID `json:"ownerId" bson:"ownerId"`
+	Members   []WorkspaceMember  `json:"members" bson:"members"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// RoleOf returns a user's role in the workspace, or an empty string when
+// they aren't a member
+func (w *Workspace) RoleOf(userID primitive.ObjectID) string {
+	for _, member := range w.Members {
+		if member.UserID == userID {
+			return member.Role
+		}
+	}
+	return ""
+}
+
+// GoalRole maps a workspace role to the role it implies on goals in the
+// workspace that aren't explicitly shared with the user. Admins manage every
+// goal, members can read their colleagues' goals and guests only see goals
+// shared with them.
+func GoalRole(workspaceRole string) string {
+	switch workspaceRole {
+	case WorkspaceRoleOwner, WorkspaceRoleAdmin:
+		return RoleOwner
+	case WorkspaceRoleMember:
+		return RoleViewer
+	}
+	return ""
+}
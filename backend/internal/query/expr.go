@@ -0,0 +1,501 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"task-management/internal/models"
+)
+
+// dayLayout is the layout of dates in queries
+const dayLayout = "2006-01-02"
+
+// Env is what relative values are resolved against: "me" is UserID and
+// days such as "today" or "2026-12-01" are calendar days in Now's location
+type Env struct {
+	Now    time.Time
+	UserID primitive.ObjectID
+}
+
+// Expr is a parsed query. Mongo and Match agree: a goal matches the
+// compiled filter exactly when Match returns true for it.
+type Expr interface {
+	// Mongo compiles the query to a filter on the goals collection
+	Mongo(env Env) bson.M
+	// Match evaluates the query against a goal in memory
+	Match(goal *models.Goal, env Env) bool
+}
+
+type all struct{}
+
+func (all) Mongo(Env) bson.M             { return bson.M{} }
+func (all) Match(*models.Goal, Env) bool { return true }
+
+type and []Expr
+
+func (a and) Mongo(env Env) bson.M {
+	filters := make(bson.A, len(a))
+	for i, expr := range a {
+		filters[i] = expr.Mongo(env)
+	}
+	return bson.M{"$and": filters}
+}
+
+func (a and) Match(goal *models.Goal, env Env) bool {
+	for _, expr := range a {
+		if !expr.Match(goal, env) {
+			return false
+		}
+	}
+	return true
+}
+
+type or []Expr
+
+func (o or) Mongo(env Env) bson.M {
+	filters := make(bson.A, len(o))
+	for i, expr := range o {
+		filters[i] = expr.Mongo(env)
+	}
+	return bson.M{"$or": filters}
+}
+
+func (o or) Match(goal *models.Goal, env Env) bool {
+	for _, expr := range o {
+		if expr.Match(goal, env) {
+			return true
+		}
+	}
+	return false
+}
+
+type not struct {
+	expr Expr
+}
+
+func (n not) Mongo(env Env) bson.M {
+	return bson.M{"$nor": bson.A{n.expr.Mongo(env)}}
+}
+
+func (n not) Match(goal *models.Goal, env Env) bool {
+	return !n.expr.Match(goal, env)
+}
+
+// goalCond tests a field of the goal itself
+type goalCond struct {
+	cond
+}
+
+func (g goalCond) Mongo(env Env) bson.M {
+	return g.mongo(env)
+}
+
+func (g goalCond) Match(goal *models.Goal, env Env) bool {
+	return g.match(goalValue(goal, g.path), env)
+}
+
+// taskCond tests the goal's subtasks and matches when any of them does
+type taskCond struct {
+	cond
+}
+
+func (t taskCond) Mongo(env Env) bson.M {
+	return bson.M{"subTasks": bson.M{"$elemMatch": t.mongo(env)}}
+}
+
+func (t taskCond) Match(goal *models.Goal, env Env) bool {
+	for i := range goal.SubTasks {
+		if t.match(taskValue(&goal.SubTasks[i], t.path), env) {
+			return true
+		}
+	}
+	return false
+}
+
+type valueKind int
+
+const (
+	kindStatus valueKind = iota
+	kindText
+	kindTag
+	kindPriority
+	kindNumber
+	kindDate
+	kindAssignee
+	kindType
+)
+
+// field is a queryable field. Ordered fields can be compared with < and >;
+// optional ones can be tested for being unset with the value none. Dates
+// that are zeroUnset are stored as the zero time when unset.
+type field struct {
+	kind      valueKind
+	path      string
+	ordered   bool
+	optional  bool
+	zeroUnset bool
+}
+
+var goalFields = map[string]field{
+	"status":   {kind: kindStatus, path: "completed"},
+	"title":    {kind: kindText, path: "title"},
+	"tag":      {kind: kindTag, path: "tags"},
+	"priority": {kind: kindPriority, path: "priority", ordered: true, optional: true},
+	"progress": {kind: kindNumber, path: "progress", ordered: true},
+	"due":      {kind: kindDate, path: "endDate", ordered: true, optional: true},
+	"start":    {kind: kindDate, path: "startDate", ordered: true, zeroUnset: true},
+	"created":  {kind: kindDate, path: "createdAt", ordered: true},
+	"type":     {kind: kindType, path: "type"},
+}
+
+var taskFields = map[string]field{
+	"status":   {kind: kindStatus, path: "completed"},
+	"title":    {kind: kindText, path: "title"},
+	"tag":      {kind: kindTag, path: "tags"},
+	"priority": {kind: kindPriority, path: "priority", ordered: true, optional: true},
+	"due":      {kind: kindDate, path: "dueDate", ordered: true, optional: true},
+	"created":  {kind: kindDate, path: "createdAt", ordered: true},
+	"assignee": {kind: kindAssignee, path: "assigneeId", optional: true},
+}
+
+// priorities in increasing order
+var priorities = []string{models.PriorityLow, models.PriorityMedium, models.PriorityHigh, models.PriorityUrgent}
+
+// cond is a single field test. Operators are normalized to =, <, <=, > and
+// >=; != is parsed as a negated =.
+type cond struct {
+	kind       valueKind
+	path       string
+	op         string
+	none       bool
+	text       string
+	number     float64
+	priorities []string
+	assignee   *primitive.ObjectID
+	zeroUnset  bool
+}
+
+// parseTerm builds the condition for a field:value term
+func (p *parser) parseTerm(tok token) (Expr, error) {
+	name, task := tok.field, false
+	for _, prefix := range []string{"task.", "subtask."} {
+		if strings.HasPrefix(name, prefix) {
+			name, task = strings.TrimPrefix(name, prefix), true
+		}
+	}
+
+	fields := goalFields
+	if task {
+		fields = taskFields
+	}
+	f, ok := fields[name]
+	if !ok {
+		return nil, errorAt(p.input, tok.pos, "unknown field %q", tok.field)
+	}
+
+	op, negate := tok.op, false
+	switch op {
+	case ":":
+		op = "="
+	case "!=":
+		op, negate = "=", true
+	}
+	if op != "=" && !f.ordered {
+		return nil, errorAt(p.input, tok.pos+len(tok.field), "%s can't be compared with %s", tok.field, tok.op)
+	}
+
+	c := cond{kind: f.kind, path: f.path, op: op, zeroUnset: f.zeroUnset}
+	if err := c.setValue(f, tok.value); err != nil {
+		return nil, errorAt(p.input, tok.valuePos, "%s", err)
+	}
+
+	var expr Expr = goalCond{c}
+	if task {
+		expr = taskCond{c}
+	}
+	if negate {
+		expr = not{expr}
+	}
+	return expr, nil
+}
+
+// setValue checks and stores the value of a condition
+func (c *cond) setValue(f field, value string) error {
+	lower := strings.ToLower(value)
+	if lower == "none" && f.optional {
+		if c.op != "=" {
+			return fmt.Errorf("none can't be compared")
+		}
+		c.none = true
+		return nil
+	}
+
+	switch c.kind {
+	case kindStatus:
+		switch lower {
+		case "open", "completed":
+		case "done":
+			lower = "completed"
+		default:
+			return fmt.Errorf("invalid status %q: expected open or completed", value)
+		}
+		c.text = lower
+	case kindText, kindTag:
+		c.text = value
+	case kindType:
+		if lower != "goal" && lower != models.GoalTypeHabit {
+			return fmt.Errorf("invalid type %q: expected goal or habit", value)
+		}
+		c.text = lower
+	case kindPriority:
+		if lower == "med" {
+			lower = models.PriorityMedium
+		}
+		rank := indexOf(priorities, lower)
+		if rank < 0 {
+			return fmt.Errorf("invalid priority %q: expected low, medium, high or urgent", value)
+		}
+		// Never nil, as $in needs an array even when nothing can match
+		c.priorities = []string{}
+		for i, priority := range priorities {
+			if compare(float64(i), c.op, float64(rank)) {
+				c.priorities = append(c.priorities, priority)
+			}
+		}
+	case kindNumber:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		c.number = n
+	case kindDate:
+		switch lower {
+		case "today", "tomorrow", "yesterday":
+		default:
+			if _, err := time.Parse(dayLayout, value); err != nil {
+				return fmt.Errorf("invalid date %q: expected YYYY-MM-DD, today, tomorrow or yesterday", value)
+			}
+		}
+		c.text = lower
+	case kindAssignee:
+		if lower == "me" {
+			break
+		}
+		id, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			return fmt.Errorf("invalid assignee %q: expected me, none or a user ID", value)
+		}
+		c.assignee = &id
+	}
+	return nil
+}
+
+// mongo compiles the condition to a filter on the goal or subtask
+func (c cond) mongo(env Env) bson.M {
+	if c.none {
+		return bson.M{c.path: nil}
+	}
+
+	switch c.kind {
+	case kindStatus:
+		if c.text == "completed" {
+			return bson.M{c.path: true}
+		}
+		return bson.M{c.path: bson.M{"$ne": true}}
+	case kindText:
+		return bson.M{c.path: bson.M{"$regex": regexp.QuoteMeta(c.text), "$options": "i"}}
+	case kindTag:
+		return bson.M{c.path: c.text}
+	case kindType:
+		if c.text == models.GoalTypeHabit {
+			return bson.M{c.path: models.GoalTypeHabit}
+		}
+		return bson.M{c.path: bson.M{"$ne": models.GoalTypeHabit}}
+	case kindPriority:
+		return bson.M{c.path: bson.M{"$in": c.priorities}}
+	case kindNumber:
+		if c.op == "=" {
+			return bson.M{c.path: c.number}
+		}
+		return bson.M{c.path: bson.M{mongoOperators[c.op]: c.number}}
+	case kindDate:
+		start, end := c.day(env)
+		switch c.op {
+		case "<", "<=":
+			bound := start
+			if c.op == "<=" {
+				bound = end
+			}
+			// Leave out unset dates, which are stored as the earliest time
+			if c.zeroUnset {
+				return bson.M{c.path: bson.M{"$lt": bound, "$gt": time.Time{}}}
+			}
+			return bson.M{c.path: bson.M{"$lt": bound}}
+		case ">":
+			return bson.M{c.path: bson.M{"$gte": end}}
+		case ">=":
+			return bson.M{c.path: bson.M{"$gte": start}}
+		default:
+			return bson.M{c.path: bson.M{"$gte": start, "$lt": end}}
+		}
+	default:
+		return bson.M{c.path: c.assigneeID(env)}
+	}
+}
+
+// match evaluates the condition against a goal or subtask field value
+func (c cond) match(value interface{}, env Env) bool {
+	switch v := value.(type) {
+	case bool:
+		return v == (c.text == "completed")
+	case string:
+		switch c.kind {
+		case kindText:
+			return !c.none && strings.Contains(strings.ToLower(v), strings.ToLower(c.text))
+		case kindType:
+			return (v == models.GoalTypeHabit) == (c.text == models.GoalTypeHabit)
+		default:
+			if c.none {
+				return v == ""
+			}
+			return indexOf(c.priorities, v) >= 0
+		}
+	case []string:
+		return indexOf(v, c.text) >= 0
+	case float64:
+		return compare(v, c.op, c.number)
+	case *time.Time:
+		if v == nil || c.none {
+			return v == nil && c.none
+		}
+		start, end := c.day(env)
+		switch c.op {
+		case "<":
+			return v.Before(start)
+		case "<=":
+			return v.Before(end)
+		case ">":
+			return !v.Before(end)
+		case ">=":
+			return !v.Before(start)
+		default:
+			return !v.Before(start) && v.Before(end)
+		}
+	case *primitive.ObjectID:
+		if v == nil || c.none {
+			return v == nil && c.none
+		}
+		return *v == c.assigneeID(env)
+	}
+	return false
+}
+
+// day returns the start and end of the condition's calendar day
+func (c cond) day(env Env) (time.Time, time.Time) {
+	loc := env.Now.Location()
+	today := time.Date(env.Now.Year(), env.Now.Month(), env.Now.Day(), 0, 0, 0, 0, loc)
+
+	var start time.Time
+	switch c.text {
+	case "today":
+		start = today
+	case "tomorrow":
+		start = today.AddDate(0, 0, 1)
+	case "yesterday":
+		start = today.AddDate(0, 0, -1)
+	default:
+		start, _ = time.ParseInLocation(dayLayout, c.text, loc)
+	}
+	return start, start.AddDate(0, 0, 1)
+}
+
+// assigneeID resolves the assignee, where nil stands for the caller
+func (c cond) assigneeID(env Env) primitive.ObjectID {
+	if c.assignee == nil {
+		return env.UserID
+	}
+	return *c.assignee
+}
+
+var mongoOperators = map[string]string{"<": "$lt", "<=": "$lte", ">": "$gt", ">=": "$gte"}
+
+// compare applies a normalized operator to two numbers
+func compare(a float64, op string, b float64) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	default:
+		return a == b
+	}
+}
+
+// goalValue returns the value of a goal field by its BSON path. Dates that
+// are always set are returned as pointers; a zero start date is unset.
+func goalValue(goal *models.Goal, path string) interface{} {
+	switch path {
+	case "completed":
+		return goal.Completed
+	case "title":
+		return goal.Title
+	case "tags":
+		return goal.Tags
+	case "priority":
+		return goal.Priority
+	case "progress":
+		return goal.Progress
+	case "endDate":
+		return goal.EndDate
+	case "startDate":
+		if goal.StartDate.IsZero() {
+			return (*time.Time)(nil)
+		}
+		return &goal.StartDate
+	case "createdAt":
+		return &goal.CreatedAt
+	case "type":
+		return goal.Type
+	}
+	return nil
+}
+
+// taskValue returns the value of a subtask field by its BSON path
+func taskValue(task *models.SubTask, path string) interface{} {
+	switch path {
+	case "completed":
+		return task.Completed
+	case "title":
+		return task.Title
+	case "tags":
+		return task.Tags
+	case "priority":
+		return task.Priority
+	case "dueDate":
+		return task.DueDate
+	case "createdAt":
+		return &task.CreatedAt
+	case "assigneeId":
+		return task.AssigneeID
+	}
+	return nil
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,180 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenLParen
+	tokenRParen
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenTerm
+	tokenWord
+)
+
+// token is a lexed piece of a query. Terms carry their field, operator and
+// value; words carry free text in value.
+type token struct {
+	kind     tokenKind
+	pos      int
+	field    string
+	op       string
+	value    string
+	valuePos int
+}
+
+// Error is a query that can't be parsed. Pos is the 1-based position of
+// the character the problem was found at.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+// errorAt builds an Error at a byte offset in input
+func errorAt(input string, offset int, format string, args ...interface{}) *Error {
+	return &Error{Pos: utf8.RuneCountInString(input[:offset]) + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// operators in the order they are tried, longest first
+var operators = []string{"<=", ">=", "!=", ":", "=", "<", ">"}
+
+// lex splits a query into tokens, ending with tokenEOF
+func lex(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		ch := input[i]
+		switch {
+		case isSpace(ch):
+			i++
+		case ch == '(':
+			tokens = append(tokens, token{kind: tokenLParen, pos: i})
+			i++
+		case ch == ')':
+			tokens = append(tokens, token{kind: tokenRParen, pos: i})
+			i++
+		case ch == '-' && i+1 < len(input) && !isSpace(input[i+1]) && input[i+1] != ')':
+			tokens = append(tokens, token{kind: tokenNot, pos: i})
+			i++
+		case ch == '"':
+			value, next, err := readQuoted(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenWord, pos: i, value: value, valuePos: i})
+			i = next
+		default:
+			start := i
+			for i < len(input) && isFieldChar(input[i]) {
+				i++
+			}
+			if i == start && isOperatorChar(ch) {
+				return nil, errorAt(input, i, "expected a field before %q", string(ch))
+			}
+
+			if i > start && i < len(input) && isOperatorChar(input[i]) {
+				tok, next, err := readTerm(input, start, i)
+				if err != nil {
+					return nil, err
+				}
+				tokens = append(tokens, tok)
+				i = next
+				continue
+			}
+
+			for i < len(input) && !isSpace(input[i]) && input[i] != '(' && input[i] != ')' {
+				i++
+			}
+			word := input[start:i]
+			switch word {
+			case "AND":
+				tokens = append(tokens, token{kind: tokenAnd, pos: start})
+			case "OR":
+				tokens = append(tokens, token{kind: tokenOr, pos: start})
+			case "NOT":
+				tokens = append(tokens, token{kind: tokenNot, pos: start})
+			default:
+				tokens = append(tokens, token{kind: tokenWord, pos: start, value: word, valuePos: start})
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+// readTerm reads the operator and value of a term whose field spans
+// input[start:end]
+func readTerm(input string, start, end int) (token, int, error) {
+	tok := token{kind: tokenTerm, pos: start, field: strings.ToLower(input[start:end])}
+	for _, op := range operators {
+		if strings.HasPrefix(input[end:], op) {
+			tok.op = op
+			break
+		}
+	}
+	if tok.op == "" {
+		return token{}, 0, errorAt(input, end, "unknown operator %q", string(input[end]))
+	}
+
+	i := end + len(tok.op)
+	tok.valuePos = i
+	if i < len(input) && input[i] == '"' {
+		value, next, err := readQuoted(input, i)
+		if err != nil {
+			return token{}, 0, err
+		}
+		tok.value = value
+		return tok, next, nil
+	}
+
+	for i < len(input) && !isSpace(input[i]) && input[i] != '(' && input[i] != ')' {
+		i++
+	}
+	tok.value = input[tok.valuePos:i]
+	if tok.value == "" {
+		return token{}, 0, errorAt(input, tok.valuePos, "missing value after %s%s", tok.field, tok.op)
+	}
+	return tok, i, nil
+}
+
+// readQuoted reads the double-quoted string starting at input[start],
+// where \" and \\ stand for a quote and a backslash
+func readQuoted(input string, start int) (string, int, error) {
+	var value strings.Builder
+	for i := start + 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			if i+1 < len(input) && (input[i+1] == '"' || input[i+1] == '\\') {
+				i++
+			}
+			value.WriteByte(input[i])
+		case '"':
+			return value.String(), i + 1, nil
+		default:
+			value.WriteByte(input[i])
+		}
+	}
+	return "", 0, errorAt(input, start, "unterminated string")
+}
+
+func isSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
+
+func isFieldChar(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '_' || ch == '.'
+}
+
+func isOperatorChar(ch byte) bool {
+	return ch == ':' || ch == '=' || ch == '!' || ch == '<' || ch == '>'
+}
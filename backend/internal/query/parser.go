@@ -0,0 +1,152 @@
+package query
+
+// Parse parses a filter query such as
+//
+//	status:open tag:work due<2026-12-01 (progress>50 OR priority:high)
+//
+// Terms are field, operator and value; terms next to each other must all
+// match, OR between them needs either to match and NOT or a leading -
+// negates one. Parentheses group terms and bare words search titles.
+// Fields prefixed with task. test the goal's subtasks: the term matches when
+// any subtask does. An empty query matches every goal.
+func Parse(input string) (Expr, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{input: input, tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return all{}, nil
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.unexpected(tok)
+	}
+	return expr, nil
+}
+
+type parser struct {
+	input  string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseOr parses terms joined by OR
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	exprs := or{left}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, right)
+	}
+
+	if len(exprs) == 1 {
+		return left, nil
+	}
+	return exprs, nil
+}
+
+// parseAnd parses terms joined by AND or placed next to each other
+func (p *parser) parseAnd() (Expr, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	exprs := and{first}
+	for {
+		switch p.peek().kind {
+		case tokenAnd:
+			p.next()
+		case tokenTerm, tokenWord, tokenNot, tokenLParen:
+		default:
+			if len(exprs) == 1 {
+				return first, nil
+			}
+			return exprs, nil
+		}
+
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+}
+
+// parseUnary parses a term that may be negated
+func (p *parser) parseUnary() (Expr, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return not{expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a term, a bare word or a parenthesized group
+func (p *parser) parsePrimary() (Expr, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokenRParen {
+			return nil, errorAt(p.input, tok.pos, "missing closing parenthesis")
+		}
+		p.next()
+		return expr, nil
+	case tokenTerm:
+		return p.parseTerm(tok)
+	case tokenWord:
+		return goalCond{cond{kind: kindText, path: "title", op: "=", text: tok.value}}, nil
+	default:
+		return nil, p.unexpected(tok)
+	}
+}
+
+// unexpected reports a token where a term should be
+func (p *parser) unexpected(tok token) error {
+	switch tok.kind {
+	case tokenEOF:
+		return errorAt(p.input, tok.pos, "expected a term at the end of the query")
+	case tokenRParen:
+		return errorAt(p.input, tok.pos, "unexpected \")\"")
+	case tokenAnd:
+		return errorAt(p.input, tok.pos, "expected a term before AND")
+	case tokenOr:
+		return errorAt(p.input, tok.pos, "expected a term before OR")
+	default:
+		return errorAt(p.input, tok.pos, "unexpected term")
+	}
+}
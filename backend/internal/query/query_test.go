@@ -0,0 +1,421 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"task-management/internal/models"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+		msg   string
+	}{
+		{"status:foo", 8, `invalid status "foo"`},
+		{"é due:none-ish", 7, `invalid date "none-ish"`},
+		{"bogus:1", 1, `unknown field "bogus"`},
+		{"task.bogus:1", 1, `unknown field "task.bogus"`},
+		{"tag<x", 4, "tag can't be compared with <"},
+		{"due<none", 5, "none can't be compared"},
+		{"progress:abc", 10, `invalid number "abc"`},
+		{"priority:top", 10, `invalid priority "top"`},
+		{"type:project", 6, `invalid type "project"`},
+		{"task.assignee:bob", 15, `invalid assignee "bob"`},
+		{"tag:", 5, "missing value after tag:"},
+		{"tag!x", 4, `unknown operator "!"`},
+		{":x", 1, `expected a field before ":"`},
+		{`title:"open`, 7, "unterminated string"},
+		{"(status:open", 1, "missing closing parenthesis"},
+		{"status:open)", 12, `unexpected ")"`},
+		{"()", 2, `unexpected ")"`},
+		{"AND tag:x", 1, "expected a term before AND"},
+		{"tag:x OR OR", 10, "expected a term before OR"},
+		{"tag:x OR", 9, "expected a term at the end of the query"},
+		{"NOT", 4, "expected a term at the end of the query"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var qerr *Error
+			if !errors.As(err, &qerr) {
+				t.Fatalf("Parse() error = %v, want a query error", err)
+			}
+			if qerr.Pos != tt.pos || !strings.Contains(qerr.Msg, tt.msg) {
+				t.Errorf("Parse() error = %q at %d, want %q at %d", qerr.Msg, qerr.Pos, tt.msg, tt.pos)
+			}
+		})
+	}
+}
+
+// queryFixtures returns goals covering each queryable field, keyed by
+// name, and the environment the queries run in
+func queryFixtures() (map[string]*models.Goal, Env, primitive.ObjectID) {
+	zone := time.FixedZone("UTC-5", -5*60*60)
+	env := Env{Now: time.Date(2026, 3, 10, 12, 0, 0, 0, zone), UserID: primitive.NewObjectID()}
+	other := primitive.NewObjectID()
+	local := func(day, hour int) *time.Time {
+		t := time.Date(2026, 3, day, hour, 0, 0, 0, zone)
+		return &t
+	}
+
+	goals := map[string]*models.Goal{
+		"launch": {
+			Title:     "Launch website",
+			Tags:      []string{"work", "web"},
+			Priority:  models.PriorityHigh,
+			Progress:  40,
+			StartDate: *local(1, 9),
+			EndDate:   local(10, 22),
+			CreatedAt: *local(9, 18),
+			SubTasks: []models.SubTask{
+				{Title: "Write copy", Completed: true, Tags: []string{"writing"}, Priority: models.PriorityLow, DueDate: local(9, 23), AssigneeID: &env.UserID, CreatedAt: *local(2, 9)},
+				{Title: "Deploy", Priority: models.PriorityUrgent, DueDate: local(12, 9), AssigneeID: &other, CreatedAt: *local(2, 9)},
+			},
+		},
+		"habit": {
+			Title:     "Morning run",
+			Type:      models.GoalTypeHabit,
+			Tags:      []string{"health"},
+			Completed: true,
+			Progress:  100,
+			StartDate: time.Date(2026, 1, 1, 0, 0, 0, 0, zone),
+			CreatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, zone),
+			SubTasks:  []models.SubTask{},
+		},
+		"inbox": {
+			Title:     "Inbox",
+			CreatedAt: *local(10, 0),
+			SubTasks: []models.SubTask{
+				{Title: "Call plumber", Priority: models.PriorityMedium, CreatedAt: *local(10, 0)},
+			},
+		},
+	}
+	return goals, env, other
+}
+
+func TestMatch(t *testing.T) {
+	goals, env, other := queryFixtures()
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"habit", "inbox", "launch"}},
+		{"LAUNCH", []string{"launch"}},
+		{`title:"morning run"`, []string{"habit"}},
+		{"status:open", []string{"inbox", "launch"}},
+		{"status:done", []string{"habit"}},
+		{"tag:work", []string{"launch"}},
+		{"-tag:work", []string{"habit", "inbox"}},
+		{"priority:high", []string{"launch"}},
+		{"priority>=med", []string{"launch"}},
+		{"priority<high", nil},
+		{"priority>urgent", nil},
+		{"priority:none", []string{"habit", "inbox"}},
+		{"priority!=none", []string{"launch"}},
+		{"progress>50", []string{"habit"}},
+		{"progress<=40", []string{"inbox", "launch"}},
+		{"progress:0", []string{"inbox"}},
+		{"due:today", []string{"launch"}},
+		{"due<tomorrow", []string{"launch"}},
+		{"due>=2026-03-11", nil},
+		{"due:none", []string{"habit", "inbox"}},
+		{"start<2026-02-01", []string{"habit"}},
+		{"start>=2026-03-01", []string{"launch"}},
+		{"-start<2026-02-01", []string{"inbox", "launch"}},
+		{"created:today", []string{"inbox"}},
+		{"created>=yesterday", []string{"inbox", "launch"}},
+		{"type:habit", []string{"habit"}},
+		{"type:goal", []string{"inbox", "launch"}},
+		{"task.status:completed", []string{"launch"}},
+		{"task.status:open", []string{"inbox", "launch"}},
+		{"-task.status:open", []string{"habit"}},
+		{"task.title:PLUMB", []string{"inbox"}},
+		{"subtask.tag:writing", []string{"launch"}},
+		{"task.priority:urgent", []string{"launch"}},
+		{"task.priority<=medium", []string{"inbox", "launch"}},
+		{"task.due:none", []string{"inbox"}},
+		{"task.due<today", []string{"launch"}},
+		{"task.assignee:me", []string{"launch"}},
+		{"task.assignee:none", []string{"inbox"}},
+		{"task.assignee:" + other.Hex(), []string{"launch"}},
+		{"task.status:open task.assignee:me", []string{"launch"}},
+		{"tag:work OR type:habit", []string{"habit", "launch"}},
+		{"(status:open OR progress:100) -launch", []string{"habit", "inbox"}},
+		{"NOT (tag:web AND priority:high)", []string{"habit", "inbox"}},
+		{"status:open AND task.status:open due:none", []string{"inbox"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			expr, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			var got []string
+			for _, name := range []string{"habit", "inbox", "launch"} {
+				if expr.Match(goals[name], env) {
+					got = append(got, name)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Match() matched %v, want %v", got, tt.want)
+			}
+
+			// The compiled filter must select the same goals
+			filter := roundTrip(t, expr.Mongo(env))
+			for name, goal := range goals {
+				doc := roundTrip(t, goal)
+				matched, err := evalFilter(filter, doc)
+				if err != nil {
+					t.Fatalf("filter %v is invalid: %v", filter, err)
+				}
+				if want := expr.Match(goal, env); matched != want {
+					t.Errorf("filter %v on %s = %v, Match() = %v", filter, name, matched, want)
+				}
+			}
+		})
+	}
+}
+
+// roundTrip encodes a value as BSON and decodes it as a document, giving
+// it the types the database sees
+func roundTrip(t *testing.T, v interface{}) bson.M {
+	t.Helper()
+	data, err := bson.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// evalFilter evaluates the subset of MongoDB's query language the compiled
+// queries use against a document
+func evalFilter(filter, doc bson.M) (bool, error) {
+	for key, cond := range filter {
+		var ok bool
+		var err error
+		switch key {
+		case "$and", "$or", "$nor":
+			ok, err = evalLogical(key, cond, doc)
+		default:
+			value, exists := doc[key]
+			ok, err = evalField(cond, value, exists)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func evalLogical(op string, cond interface{}, doc bson.M) (bool, error) {
+	filters, ok := cond.(bson.A)
+	if !ok || len(filters) == 0 {
+		return false, fmt.Errorf("%s needs a non-empty array", op)
+	}
+	matched := 0
+	for _, f := range filters {
+		sub, ok := f.(bson.M)
+		if !ok {
+			return false, fmt.Errorf("%s entries must be documents", op)
+		}
+		ok, err := evalFilter(sub, doc)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			matched++
+		}
+	}
+	switch op {
+	case "$and":
+		return matched == len(filters), nil
+	case "$or":
+		return matched > 0, nil
+	default:
+		return matched == 0, nil
+	}
+}
+
+// evalField evaluates the condition on one field, which is either a value
+// to equal or a document of operators
+func evalField(cond, value interface{}, exists bool) (bool, error) {
+	ops, ok := cond.(bson.M)
+	if !ok || !isOperatorDoc(ops) {
+		return equals(value, exists, cond), nil
+	}
+
+	for op, arg := range ops {
+		var ok bool
+		switch op {
+		case "$ne":
+			ok = !equals(value, exists, arg)
+		case "$in":
+			list, isArray := arg.(bson.A)
+			if !isArray {
+				return false, fmt.Errorf("$in needs an array, got %T", arg)
+			}
+			for _, item := range list {
+				if equals(value, exists, item) {
+					ok = true
+				}
+			}
+		case "$lt", "$lte", "$gt", "$gte":
+			ok = anyElement(value, func(v interface{}) bool {
+				c, comparable := compareBSON(v, arg)
+				switch op {
+				case "$lt":
+					return comparable && c < 0
+				case "$lte":
+					return comparable && c <= 0
+				case "$gt":
+					return comparable && c > 0
+				default:
+					return comparable && c >= 0
+				}
+			})
+		case "$regex":
+			pattern := arg.(string)
+			if options, _ := ops["$options"].(string); options == "i" {
+				pattern = "(?i)" + pattern
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return false, err
+			}
+			ok = anyElement(value, func(v interface{}) bool {
+				s, isString := v.(string)
+				return isString && re.MatchString(s)
+			})
+		case "$options":
+			ok = true
+		case "$elemMatch":
+			sub, isDoc := arg.(bson.M)
+			if !isDoc {
+				return false, fmt.Errorf("$elemMatch needs a document")
+			}
+			list, _ := value.(bson.A)
+			for _, item := range list {
+				elem, isDoc := item.(bson.M)
+				if !isDoc {
+					continue
+				}
+				matched, err := evalFilter(sub, elem)
+				if err != nil {
+					return false, err
+				}
+				if matched {
+					ok = true
+				}
+			}
+		default:
+			return false, fmt.Errorf("unsupported operator %s", op)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func isOperatorDoc(doc bson.M) bool {
+	for key := range doc {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return len(doc) > 0
+}
+
+// equals matches a field against a value the way an equality filter does:
+// null matches missing fields and arrays match any of their elements
+func equals(value interface{}, exists bool, want interface{}) bool {
+	if want == nil {
+		return !exists || value == nil
+	}
+	return anyElement(value, func(v interface{}) bool {
+		c, comparable := compareBSON(v, want)
+		return comparable && c == 0
+	})
+}
+
+func anyElement(value interface{}, test func(interface{}) bool) bool {
+	if list, ok := value.(bson.A); ok {
+		for _, item := range list {
+			if test(item) {
+				return true
+			}
+		}
+		return false
+	}
+	return test(value)
+}
+
+// compareBSON orders two values of the same BSON type
+func compareBSON(a, b interface{}) (int, bool) {
+	if x, ok := toNumber(a); ok {
+		y, ok := toNumber(b)
+		switch {
+		case !ok:
+			return 0, false
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		return strings.Compare(x, y), ok
+	case bool:
+		y, ok := b.(bool)
+		return map[bool]int{true: 0, false: 1}[x == y], ok
+	case primitive.DateTime:
+		y, ok := b.(primitive.DateTime)
+		switch {
+		case !ok:
+			return 0, false
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	case primitive.ObjectID:
+		y, ok := b.(primitive.ObjectID)
+		return map[bool]int{true: 0, false: 1}[x == y], ok
+	}
+	return 0, false
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
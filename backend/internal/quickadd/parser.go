@@ -0,0 +1,392 @@
+package quickadd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-management/internal/models"
+)
+
+// defaultHour and defaultMinute are the time of day a due date without a
+// time is set to, so the task is due by the end of that day
+const (
+	defaultHour   = 23
+	defaultMinute = 59
+)
+
+// tonightHour is the time "tonight" stands for
+const tonightHour = 20
+
+// Result is the structure parsed from a quick-add line. Goal is the
+// reference after the @ marker, to be resolved to a goal by the caller.
+type Result struct {
+	Title    string     `json:"title"`
+	Due      *time.Time `json:"due,omitempty"`
+	DueText  string     `json:"dueText,omitempty"`
+	Tags     []string   `json:"tags,omitempty"`
+	Priority string     `json:"priority,omitempty"`
+	Goal     string     `json:"goal,omitempty"`
+}
+
+var (
+	tagPattern      = regexp.MustCompile(`^#([\p{L}\p{N}_/-]+)$`)
+	clockPattern    = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)$`)
+	clock24Pattern  = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+	isoDatePattern  = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	dayPattern      = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`)
+	yearPattern     = regexp.MustCompile(`^\d{4}$`)
+	numberPattern   = regexp.MustCompile(`^\d+$`)
+	trailingPattern = regexp.MustCompile(`[,.;!?]+$`)
+)
+
+// priorities maps the words accepted after ! to priorities
+var priorities = map[string]string{
+	"low":    models.PriorityLow,
+	"medium": models.PriorityMedium,
+	"med":    models.PriorityMedium,
+	"high":   models.PriorityHigh,
+	"urgent": models.PriorityUrgent,
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+// prepositions introduce a due date and are dropped with it
+var prepositions = map[string]bool{"by": true, "due": true, "on": true, "at": true, "before": true}
+
+// word is a token of the input. key is its lowercase form without
+// trailing punctuation, used for matching.
+type word struct {
+	text string
+	key  string
+}
+
+// Parse extracts the due date, #tags, !priority and @goal from a quick-add
+// line; the remaining words form the title. Dates are relative to now and
+// in its location, which should be the user's time zone. Only the first
+// date phrase is used.
+func Parse(input string, now time.Time) Result {
+	var result Result
+	var words []word
+
+	for _, token := range tokenize(input) {
+		switch {
+		case tagPattern.MatchString(token):
+			tag := tagPattern.FindStringSubmatch(token)[1]
+			if !contains(result.Tags, tag) {
+				result.Tags = append(result.Tags, tag)
+			}
+		case strings.HasPrefix(token, "!") && priorities[strings.ToLower(token[1:])] != "":
+			result.Priority = priorities[strings.ToLower(token[1:])]
+		case strings.HasPrefix(token, "@") && len(token) > 1:
+			result.Goal = strings.Trim(token[1:], `"`)
+		default:
+			key := strings.ToLower(trailingPattern.ReplaceAllString(token, ""))
+			words = append(words, word{text: token, key: key})
+		}
+	}
+
+	var title []string
+	for i := 0; i < len(words); i++ {
+		if result.Due == nil {
+			if due, n := matchDue(words[i:], now); n > 0 {
+				result.Due = &due
+				texts := make([]string, n)
+				for j := range texts {
+					texts[j] = words[i+j].text
+				}
+				result.DueText = trailingPattern.ReplaceAllString(strings.Join(texts, " "), "")
+				i += n - 1
+				continue
+			}
+		}
+		title = append(title, words[i].text)
+	}
+	result.Title = strings.Join(title, " ")
+
+	return result
+}
+
+// tokenize splits the input on whitespace, keeping @"quoted goal titles"
+// together
+func tokenize(input string) []string {
+	var tokens []string
+	for len(input) > 0 {
+		input = strings.TrimLeft(input, " \t\r\n")
+		if input == "" {
+			break
+		}
+		if strings.HasPrefix(input, `@"`) {
+			if end := strings.Index(input[2:], `"`); end >= 0 {
+				tokens = append(tokens, input[:end+3])
+				input = input[end+3:]
+				continue
+			}
+		}
+		end := strings.IndexAny(input, " \t\r\n")
+		if end < 0 {
+			end = len(input)
+		}
+		tokens = append(tokens, input[:end])
+		input = input[end:]
+	}
+	return tokens
+}
+
+// matchDue matches a due date phrase at the start of words: an optional
+// preposition, then a date, a time, or both in either order. It returns the
+// due time and the number of words used, or 0 when there's no match.
+func matchDue(words []word, now time.Time) (time.Time, int) {
+	start := 0
+	if len(words) > 1 && prepositions[words[0].key] {
+		start = 1
+	}
+
+	// Relative times such as "in 2 hours" are complete on their own
+	if due, n := matchDuration(words[start:], now); n > 0 {
+		return due, start + n
+	}
+
+	day, n := matchDate(words[start:], now, start > 0)
+	if n > 0 {
+		used := start + n
+		rest := words[used:]
+		if len(rest) > 1 && rest[0].key == "at" {
+			if hour, minute, m := matchClock(rest[1:]); m > 0 {
+				return at(day, hour, minute), used + 1 + m
+			}
+		}
+		if hour, minute, m := matchClock(rest); m > 0 {
+			return at(day, hour, minute), used + m
+		}
+		if words[start].key == "tonight" {
+			return at(day, tonightHour, 0), used
+		}
+		return at(day, defaultHour, defaultMinute), used
+	}
+
+	hour, minute, n := matchClock(words[start:])
+	if n == 0 {
+		return time.Time{}, 0
+	}
+	used := start + n
+	if day, m := matchDate(words[used:], now, true); m > 0 {
+		return at(day, hour, minute), used + m
+	}
+	// A time on its own is the next time the clock shows it
+	due := at(now, hour, minute)
+	if !due.After(now) {
+		due = due.AddDate(0, 0, 1)
+	}
+	return due, used
+}
+
+// matchDate matches a calendar day at the start of words, returning
+// midnight of it in now's location. Abbreviated weekdays such as "sat" are
+// common words, so they only count when introduced by a preposition or a
+// time.
+func matchDate(words []word, now time.Time, introduced bool) (time.Time, int) {
+	if len(words) == 0 {
+		return time.Time{}, 0
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	key := words[0].key
+
+	switch key {
+	case "today", "tonight":
+		return today, 1
+	case "tomorrow", "tmr", "tmrw":
+		return today.AddDate(0, 0, 1), 1
+	}
+
+	if weekday, ok := weekdays[key]; ok && (introduced || len(key) >= len("monday")) {
+		return nextWeekday(today, weekday), 1
+	}
+
+	if len(words) > 1 && (key == "this" || key == "next") {
+		next := words[1].key
+		if weekday, ok := weekdays[next]; ok {
+			day := nextWeekday(today, weekday)
+			if key == "next" {
+				// The weekday in the week after this Monday-to-Sunday week
+				monday := today.AddDate(0, 0, -((int(today.Weekday())+6)%7)+7)
+				day = monday.AddDate(0, 0, (int(weekday)+6)%7)
+			}
+			return day, 2
+		}
+		if key == "next" && next == "week" {
+			return today.AddDate(0, 0, -((int(today.Weekday())+6)%7)+7), 2
+		}
+		if key == "next" && next == "month" {
+			return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()), 2
+		}
+	}
+
+	if isoDatePattern.MatchString(key) {
+		if day, err := time.ParseInLocation("2006-01-02", key, now.Location()); err == nil {
+			return day, 1
+		}
+	}
+
+	// "Dec 5", "December 5th 2026", "5 Dec" and "5th of December"
+	if len(words) > 1 {
+		if month, ok := months[key]; ok {
+			if m := dayPattern.FindStringSubmatch(words[1].key); m != nil {
+				return monthDay(words[2:], today, month, m[1], 2)
+			}
+		}
+		if m := dayPattern.FindStringSubmatch(key); m != nil {
+			rest, used := words[1:], 1
+			if len(rest) > 1 && rest[0].key == "of" {
+				rest, used = rest[1:], 2
+			}
+			if month, ok := months[rest[0].key]; ok {
+				return monthDay(rest[1:], today, month, m[1], used+1)
+			}
+		}
+	}
+
+	return time.Time{}, 0
+}
+
+// monthDay builds the date for a day of a month, using a following year if
+// there is one and otherwise the next time the date comes around
+func monthDay(rest []word, today time.Time, month time.Month, dayText string, used int) (time.Time, int) {
+	dayOfMonth, _ := strconv.Atoi(dayText)
+	if dayOfMonth < 1 || dayOfMonth > 31 {
+		return time.Time{}, 0
+	}
+
+	if len(rest) > 0 && yearPattern.MatchString(rest[0].key) {
+		year, _ := strconv.Atoi(rest[0].key)
+		return validDate(year, month, dayOfMonth, today.Location(), used+1)
+	}
+
+	day, n := validDate(today.Year(), month, dayOfMonth, today.Location(), used)
+	if n > 0 && day.Before(today) {
+		return validDate(today.Year()+1, month, dayOfMonth, today.Location(), used)
+	}
+	return day, n
+}
+
+// validDate builds a date, rejecting days the month doesn't have
+func validDate(year int, month time.Month, day int, loc *time.Location, used int) (time.Time, int) {
+	date := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	if date.Month() != month {
+		return time.Time{}, 0
+	}
+	return date, used
+}
+
+// matchDuration matches "in N minutes/hours/days/weeks/months"
+func matchDuration(words []word, now time.Time) (time.Time, int) {
+	if len(words) < 3 || words[0].key != "in" || !numberPattern.MatchString(words[1].key) {
+		return time.Time{}, 0
+	}
+	n, err := strconv.Atoi(words[1].key)
+	if err != nil {
+		return time.Time{}, 0
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.TrimSuffix(words[2].key, "s") {
+	case "min", "minute":
+		return now.Add(time.Duration(n) * time.Minute), 3
+	case "hour", "hr":
+		return now.Add(time.Duration(n) * time.Hour), 3
+	case "day":
+		return at(today.AddDate(0, 0, n), defaultHour, defaultMinute), 3
+	case "week":
+		return at(today.AddDate(0, 0, 7*n), defaultHour, defaultMinute), 3
+	case "month":
+		return at(today.AddDate(0, n, 0), defaultHour, defaultMinute), 3
+	}
+	return time.Time{}, 0
+}
+
+// matchClock matches a time of day: "5pm", "5:30 pm", "17:00", "noon" or
+// "midnight"
+func matchClock(words []word) (int, int, int) {
+	if len(words) == 0 {
+		return 0, 0, 0
+	}
+	key := words[0].key
+
+	switch key {
+	case "noon":
+		return 12, 0, 1
+	case "midnight":
+		return 0, 0, 1
+	}
+
+	used := 1
+	if len(words) > 1 && (words[1].key == "am" || words[1].key == "pm") {
+		key += words[1].key
+		used = 2
+	}
+	if m := clockPattern.FindStringSubmatch(key); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if hour < 1 || hour > 12 || minute > 59 {
+			return 0, 0, 0
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+		return hour, minute, used
+	}
+
+	if m := clock24Pattern.FindStringSubmatch(words[0].key); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if hour > 23 || minute > 59 {
+			return 0, 0, 0
+		}
+		return hour, minute, 1
+	}
+	return 0, 0, 0
+}
+
+// nextWeekday returns the next day, counting today, that falls on weekday
+func nextWeekday(today time.Time, weekday time.Weekday) time.Time {
+	return today.AddDate(0, 0, (int(weekday)-int(today.Weekday())+7)%7)
+}
+
+// at sets the time of day of a date
+func at(day time.Time, hour, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package quickadd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"task-management/internal/models"
+)
+
+func TestParse(t *testing.T) {
+	// Wednesday morning, in the user's time zone
+	zone := time.FixedZone("UTC-5", -5*60*60)
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, zone)
+
+	tests := []struct {
+		input    string
+		title    string
+		due      string
+		dueText  string
+		tags     []string
+		priority string
+		goal     string
+	}{
+		// Relative days
+		{input: "Buy milk tomorrow", title: "Buy milk", due: "2026-03-05 23:59", dueText: "tomorrow"},
+		{input: "Dinner tonight", title: "Dinner", due: "2026-03-04 20:00", dueText: "tonight"},
+		{input: "Renew in 2 weeks", title: "Renew", due: "2026-03-18 23:59", dueText: "in 2 weeks"},
+		{input: "Check oven in 45 minutes", title: "Check oven", due: "2026-03-04 10:45", dueText: "in 45 minutes"},
+		{input: "Review next month", title: "Review", due: "2026-04-01 23:59", dueText: "next month"},
+
+		// Weekdays
+		{input: "Water plants wednesday", title: "Water plants", due: "2026-03-04 23:59", dueText: "wednesday"},
+		{input: "Demo this friday", title: "Demo", due: "2026-03-06 23:59", dueText: "this friday"},
+		{input: "Pay rent next monday", title: "Pay rent", due: "2026-03-09 23:59", dueText: "next monday"},
+		{input: "Retro next wednesday", title: "Retro", due: "2026-03-11 23:59", dueText: "next wednesday"},
+		{input: "Email Bob, due next week.", title: "Email Bob,", due: "2026-03-09 23:59", dueText: "due next week"},
+		{input: "Gym on sat", title: "Gym", due: "2026-03-07 23:59", dueText: "on sat"},
+		{input: "Gym sat", title: "Gym sat"},
+
+		// Calendar dates
+		{input: "Taxes Dec 5th", title: "Taxes", due: "2026-12-05 23:59", dueText: "Dec 5th"},
+		{input: "Trip 5th of January", title: "Trip", due: "2027-01-05 23:59", dueText: "5th of January"},
+		{input: "Renew passport 1 March 2028", title: "Renew passport", due: "2028-03-01 23:59", dueText: "1 March 2028"},
+		{input: "Launch 2026-04-01 at 14:00", title: "Launch", due: "2026-04-01 14:00", dueText: "2026-04-01 at 14:00"},
+		{input: "Party Feb 30", title: "Party Feb 30"},
+		{input: "May the force", title: "May the force"},
+
+		// Times of day
+		{input: "Call mom at 5pm", title: "Call mom", due: "2026-03-04 17:00", dueText: "at 5pm"},
+		{input: "Standup 9am", title: "Standup", due: "2026-03-05 09:00", dueText: "9am"},
+		{input: "Report by friday 5:30 pm", title: "Report", due: "2026-03-06 17:30", dueText: "by friday 5:30 pm"},
+		{input: "Lunch noon tomorrow", title: "Lunch", due: "2026-03-05 12:00", dueText: "noon tomorrow"},
+		{input: "Deploy 12:60", title: "Deploy 12:60"},
+		{input: "Read 13pm", title: "Read 13pm"},
+
+		// Markers
+		{input: "Ship it #work #work #team/web !HIGH", title: "Ship it", tags: []string{"work", "team/web"}, priority: models.PriorityHigh},
+		{input: "Fix !med bug", title: "Fix bug", priority: models.PriorityMedium},
+		{input: "Wow !bogus", title: "Wow !bogus"},
+		{input: `Draft outline @"Q2 Plan" tomorrow`, title: "Draft outline", goal: "Q2 Plan", due: "2026-03-05 23:59", dueText: "tomorrow"},
+		{input: "Write tests @launch", title: "Write tests", goal: "launch"},
+		{input: "Email @", title: "Email @"},
+
+		// Only the first date phrase is used
+		{input: "Plan tomorrow friday", title: "Plan friday", due: "2026-03-05 23:59", dueText: "tomorrow"},
+		{input: "Next steps", title: "Next steps"},
+		{input: "  spaced   out  ", title: "spaced out"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := Parse(tt.input, now)
+			if got.Title != tt.title {
+				t.Errorf("title = %q, want %q", got.Title, tt.title)
+			}
+			due := ""
+			if got.Due != nil {
+				due = got.Due.Format("2006-01-02 15:04")
+				if got.Due.Location() != zone {
+					t.Errorf("due is in %v, want %v", got.Due.Location(), zone)
+				}
+			}
+			if due != tt.due || got.DueText != tt.dueText {
+				t.Errorf("due = %q (%q), want %q (%q)", due, got.DueText, tt.due, tt.dueText)
+			}
+			if !reflect.DeepEqual(got.Tags, tt.tags) {
+				t.Errorf("tags = %v, want %v", got.Tags, tt.tags)
+			}
+			if got.Priority != tt.priority || got.Goal != tt.goal {
+				t.Errorf("priority, goal = %q, %q, want %q, %q", got.Priority, got.Goal, tt.priority, tt.goal)
+			}
+		})
+	}
+}
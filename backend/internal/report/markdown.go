@@ -0,0 +1,78 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "|", `\|`, "<", "&lt;", ">", "&gt;",
+)
+
+// RenderMarkdown writes the report as a Markdown document
+func RenderMarkdown(w io.Writer, rep *Report) error {
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintf(b, "# %s\n\n", markdownEscaper.Replace(rep.Title))
+	fmt.Fprintf(b, "Period: %s  \n", rangeLabel(rep.Filters))
+	fmt.Fprintf(b, "Status: %s  \n", rep.Filters.Status)
+	if len(rep.Filters.Tags) > 0 {
+		fmt.Fprintf(b, "Tags: %s  \n", markdownEscaper.Replace(strings.Join(rep.Filters.Tags, ", ")))
+	}
+	fmt.Fprintf(b, "Generated: %s\n\n", rep.GeneratedAt.Format("2006-01-02 15:04 MST"))
+
+	s := rep.Summary
+	b.WriteString("## Summary\n\n")
+	b.WriteString("| Metric | Value |\n|---|---|\n")
+	fmt.Fprintf(b, "| Goals | %d |\n", s.TotalGoals)
+	fmt.Fprintf(b, "| Completed | %d |\n", s.CompletedGoals)
+	fmt.Fprintf(b, "| Open | %d |\n", s.OpenGoals)
+	fmt.Fprintf(b, "| Overdue | %d |\n", s.OverdueGoals)
+	fmt.Fprintf(b, "| Completion rate | %.1f%% |\n", s.CompletionRate)
+	fmt.Fprintf(b, "| Average progress | %.1f%% |\n", s.AverageProgress)
+	fmt.Fprintf(b, "| Subtasks completed | %d / %d |\n\n", s.CompletedSubTasks, s.TotalSubTasks)
+
+	b.WriteString("## Goals\n\n")
+	if len(rep.Goals) == 0 {
+		b.WriteString("No goals match the selected filters.\n")
+	}
+
+	for _, goal := range rep.Goals {
+		fmt.Fprintf(b, "### %s\n\n", markdownEscaper.Replace(goal.Title))
+		fmt.Fprintf(b, "- Status: %s\n", statusLabel(goal, rep.GeneratedAt))
+		fmt.Fprintf(b, "- Progress: %.0f%%\n", goal.Progress)
+		fmt.Fprintf(b, "- Period: %s to %s\n", formatDate(&goal.StartDate), formatDate(goal.EndDate))
+		if goal.CompletedAt != nil {
+			fmt.Fprintf(b, "- Completed on: %s\n", formatDate(goal.CompletedAt))
+		}
+		if len(goal.Tags) > 0 {
+			fmt.Fprintf(b, "- Tags: %s\n", markdownEscaper.Replace(strings.Join(goal.Tags, ", ")))
+		}
+		b.WriteString("\n")
+
+		if goal.Description != "" {
+			fmt.Fprintf(b, "%s\n\n", markdownEscaper.Replace(goal.Description))
+		}
+
+		if len(goal.SubTasks) > 0 {
+			for _, task := range goal.SubTasks {
+				check := " "
+				detail := ""
+				if task.Completed {
+					check = "x"
+					if task.CompletedAt != nil {
+						detail = " (completed " + formatDate(task.CompletedAt) + ")"
+					}
+				} else if task.DueDate != nil {
+					detail = " (due " + formatDate(task.DueDate) + ")"
+				}
+				fmt.Fprintf(b, "- [%s] %s%s\n", check, markdownEscaper.Replace(task.Title), detail)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	return b.Flush()
+}
@@ -0,0 +1,239 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Page geometry in PDF points (A4)
+const (
+	pageWidth    = 595.0
+	pageHeight   = 842.0
+	pageMargin   = 50.0
+	lineSpacing  = 1.4
+	avgCharWidth = 0.5 // Helvetica averages about half an em per character
+)
+
+// pdfLine is a single line of laid-out text
+type pdfLine struct {
+	text   string
+	bold   bool
+	size   float64
+	indent float64
+	before float64
+}
+
+// RenderPDF writes the report as a PDF document. The document is generated
+// directly using the standard Type 1 Helvetica fonts, so no external tools
+// or embedded font files are needed.
+func RenderPDF(w io.Writer, rep *Report) error {
+	var lines []pdfLine
+	add := func(text string, size float64, bold bool, indent, before float64) {
+		for _, part := range wrapText(text, size, indent) {
+			lines = append(lines, pdfLine{text: part, bold: bold, size: size, indent: indent, before: before})
+			before = 0
+		}
+	}
+
+	add(rep.Title, 20, true, 0, 0)
+	add("Period: "+rangeLabel(rep.Filters), 10, false, 0, 6)
+	add("Status: "+rep.Filters.Status, 10, false, 0, 0)
+	if len(rep.Filters.Tags) > 0 {
+		add("Tags: "+strings.Join(rep.Filters.Tags, ", "), 10, false, 0, 0)
+	}
+	add("Generated: "+rep.GeneratedAt.Format("2006-01-02 15:04 MST"), 10, false, 0, 0)
+
+	s := rep.Summary
+	add("Summary", 14, true, 0, 14)
+	add(fmt.Sprintf("Goals: %d    Completed: %d    Open: %d    Overdue: %d",
+		s.TotalGoals, s.CompletedGoals, s.OpenGoals, s.OverdueGoals), 10, false, 0, 4)
+	add(fmt.Sprintf("Completion rate: %.1f%%    Average progress: %.1f%%", s.CompletionRate, s.AverageProgress), 10, false, 0, 0)
+	add(fmt.Sprintf("Subtasks completed: %d / %d", s.CompletedSubTasks, s.TotalSubTasks), 10, false, 0, 0)
+
+	add("Goals", 14, true, 0, 14)
+	if len(rep.Goals) == 0 {
+		add("No goals match the selected filters.", 10, false, 0, 4)
+	}
+
+	for _, goal := range rep.Goals {
+		add(goal.Title, 12, true, 0, 10)
+		details := fmt.Sprintf("%s - %.0f%% - %s to %s",
+			statusLabel(goal, rep.GeneratedAt), goal.Progress, formatDate(&goal.StartDate), formatDate(goal.EndDate))
+		if goal.CompletedAt != nil {
+			details += " - completed " + formatDate(goal.CompletedAt)
+		}
+		add(details, 9, false, 0, 2)
+		if len(goal.Tags) > 0 {
+			add("Tags: "+strings.Join(goal.Tags, ", "), 9, false, 0, 0)
+		}
+		if goal.Description != "" {
+			add(goal.Description, 10, false, 0, 4)
+		}
+
+		for _, task := range goal.SubTasks {
+			mark := "[ ]"
+			detail := ""
+			if task.Completed {
+				mark = "[x]"
+				if task.CompletedAt != nil {
+					detail = " (completed " + formatDate(task.CompletedAt) + ")"
+				}
+			} else if task.DueDate != nil {
+				detail = " (due " + formatDate(task.DueDate) + ")"
+			}
+			add(mark+" "+task.Title+detail, 10, false, 14, 2)
+		}
+	}
+
+	return writePDF(w, paginate(lines))
+}
+
+// wrapText splits text into lines that fit the printable width
+func wrapText(text string, size, indent float64) []string {
+	maxChars := int((pageWidth - 2*pageMargin - indent) / (size * avgCharWidth))
+
+	var result []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			continue
+		}
+
+		line := ""
+		for _, word := range words {
+			for runes := []rune(word); len(runes) > maxChars; runes = []rune(word) {
+				if line != "" {
+					result = append(result, line)
+					line = ""
+				}
+				result = append(result, string(runes[:maxChars]))
+				word = string(runes[maxChars:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= maxChars:
+				line += " " + word
+			default:
+				result = append(result, line)
+				line = word
+			}
+		}
+		if line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// paginate lays lines out top to bottom and returns one content stream per page
+func paginate(lines []pdfLine) [][]byte {
+	var pages [][]byte
+	var content bytes.Buffer
+	y := pageHeight - pageMargin
+
+	for _, line := range lines {
+		height := line.before + line.size*lineSpacing
+		if y-height < pageMargin && content.Len() > 0 {
+			pages = append(pages, append([]byte(nil), content.Bytes()...))
+			content.Reset()
+			y = pageHeight - pageMargin
+		}
+		y -= height
+
+		font := "F1"
+		if line.bold {
+			font = "F2"
+		}
+		fmt.Fprintf(&content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n",
+			font, line.size, pageMargin+line.indent, y, pdfString(line.text))
+	}
+
+	if content.Len() > 0 || len(pages) == 0 {
+		pages = append(pages, content.Bytes())
+	}
+
+	// Number the pages once the total is known
+	for i := range pages {
+		footer := fmt.Sprintf("BT /F1 8 Tf %.2f %.2f Td (Page %d of %d) Tj ET\n",
+			pageWidth-pageMargin-50, pageMargin/2, i+1, len(pages))
+		pages[i] = append(pages[i], footer...)
+	}
+
+	return pages
+}
+
+// writePDF serialises the page content streams into a PDF file
+func writePDF(w io.Writer, pages [][]byte) error {
+	var buf bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-4 are fixed; each page then takes a page object and a content stream
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, content := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// winAnsiExtras maps the characters WinAnsiEncoding places in 0x80-0x9F,
+// where Latin-1 has control characters
+var winAnsiExtras = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// pdfString escapes text for a PDF literal string in WinAnsiEncoding, which
+// the standard fonts use. Characters it can't represent become '?'.
+func pdfString(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32 || r == 0x7f:
+			b.WriteByte(' ')
+		case r < 0x80 || (r >= 0xa0 && r < 0x100):
+			b.WriteByte(byte(r))
+		case winAnsiExtras[r] != 0:
+			b.WriteByte(winAnsiExtras[r])
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
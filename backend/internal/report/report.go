@@ -0,0 +1,121 @@
+package report
+
+import (
+	"time"
+
+	"task-management/internal/models"
+)
+
+// Goal statuses that a report can be filtered by
+const (
+	StatusAll       = "all"
+	StatusOpen      = "open"
+	StatusCompleted = "completed"
+	StatusOverdue   = "overdue"
+)
+
+// Filters describes the criteria a report was generated with
+type Filters struct {
+	From   *time.Time `json:"from,omitempty"`
+	To     *time.Time `json:"to,omitempty"`
+	Tags   []string   `json:"tags,omitempty"`
+	Status string     `json:"status"`
+}
+
+// Summary holds the aggregate statistics shown at the top of a report
+type Summary struct {
+	TotalGoals        int     `json:"totalGoals"`
+	CompletedGoals    int     `json:"completedGoals"`
+	OpenGoals         int     `json:"openGoals"`
+	OverdueGoals      int     `json:"overdueGoals"`
+	AverageProgress   float64 `json:"averageProgress"`
+	TotalSubTasks     int     `json:"totalSubTasks"`
+	CompletedSubTasks int     `json:"completedSubTasks"`
+	CompletionRate    float64 `json:"completionRate"`
+}
+
+// Report is a rendered-agnostic view of a user's goals
+type Report struct {
+	Title       string        `json:"title"`
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Filters     Filters       `json:"filters"`
+	Summary     Summary       `json:"summary"`
+	Goals       []models.Goal `json:"goals"`
+}
+
+// Build computes the summary for a set of goals
+func Build(title string, goals []models.Goal, filters Filters, now time.Time) *Report {
+	rep := &Report{
+		Title:       title,
+		GeneratedAt: now,
+		Filters:     filters,
+		Goals:       goals,
+	}
+
+	var progressTotal float64
+	for _, goal := range goals {
+		rep.Summary.TotalGoals++
+		progressTotal += goal.Progress
+
+		switch {
+		case goal.Completed:
+			rep.Summary.CompletedGoals++
+		case IsOverdue(goal, now):
+			rep.Summary.OverdueGoals++
+			rep.Summary.OpenGoals++
+		default:
+			rep.Summary.OpenGoals++
+		}
+
+		for _, task := range goal.SubTasks {
+			rep.Summary.TotalSubTasks++
+			if task.Completed {
+				rep.Summary.CompletedSubTasks++
+			}
+		}
+	}
+
+	if rep.Summary.TotalGoals > 0 {
+		rep.Summary.AverageProgress = progressTotal / float64(rep.Summary.TotalGoals)
+		rep.Summary.CompletionRate = float64(rep.Summary.CompletedGoals) / float64(rep.Summary.TotalGoals) * 100
+	}
+
+	return rep
+}
+
+// IsOverdue reports whether an open goal is past its end date
+func IsOverdue(goal models.Goal, now time.Time) bool {
+	return !goal.Completed && goal.EndDate != nil && goal.EndDate.Before(now)
+}
+
+// statusLabel describes a goal's status for display
+func statusLabel(goal models.Goal, now time.Time) string {
+	switch {
+	case goal.Completed:
+		return "Completed"
+	case IsOverdue(goal, now):
+		return "Overdue"
+	default:
+		return "Open"
+	}
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02")
+}
+
+func rangeLabel(filters Filters) string {
+	switch {
+	case filters.From != nil && filters.To != nil:
+		return formatDate(filters.From) + " to " + formatDate(filters.To)
+	case filters.From != nil:
+		return "since " + formatDate(filters.From)
+	case filters.To != nil:
+		return "until " + formatDate(filters.To)
+	default:
+		return "all time"
+	}
+}
@@ -0,0 +1,224 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"task-management/internal/models"
+)
+
+// testReport builds a report with an overdue, a completed and an open goal
+func testReport() *Report {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	past := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+	future := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	done := time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)
+
+	goals := []models.Goal{
+		{
+			ID:          primitive.NewObjectID(),
+			Title:       "Launch *v2* [beta]",
+			Description: "Ship the <new> site",
+			Tags:        []string{"work", "web"},
+			StartDate:   start,
+			EndDate:     &past,
+			Progress:    50,
+			SubTasks: []models.SubTask{
+				{Title: "Design", Completed: true, CompletedAt: &done},
+				{Title: "Build | test", DueDate: &future},
+			},
+		},
+		{
+			ID:          primitive.NewObjectID(),
+			Title:       "Café “Zürich” – 5€ ≥ 3 😀",
+			StartDate:   start,
+			Completed:   true,
+			CompletedAt: &done,
+			Progress:    100,
+		},
+		{
+			ID:        primitive.NewObjectID(),
+			Title:     "Read (more)",
+			StartDate: start,
+			EndDate:   &future,
+		},
+	}
+
+	from := start
+	return Build("Q1 report", goals, Filters{From: &from, Tags: []string{"work"}, Status: StatusAll}, now)
+}
+
+func TestBuild(t *testing.T) {
+	got := testReport().Summary
+	completed, total := 1.0, 3.0
+	want := Summary{
+		TotalGoals:        3,
+		CompletedGoals:    1,
+		OpenGoals:         2,
+		OverdueGoals:      1,
+		AverageProgress:   50,
+		TotalSubTasks:     2,
+		CompletedSubTasks: 1,
+		CompletionRate:    completed / total * 100,
+	}
+	if got != want {
+		t.Errorf("summary = %+v, want %+v", got, want)
+	}
+
+	if empty := Build("Empty", nil, Filters{}, time.Now()).Summary; empty != (Summary{}) {
+		t.Errorf("empty summary = %+v, want zero", empty)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderMarkdown(&buf, testReport()); err != nil {
+		t.Fatal(err)
+	}
+	text := buf.String()
+
+	for _, want := range []string{
+		"# Q1 report\n\n",
+		"Period: since 2026-03-01  \n",
+		"Status: all  \n",
+		"Tags: work  \n",
+		"Generated: 2026-03-10 12:00 UTC\n",
+		"| Goals | 3 |\n",
+		"| Overdue | 1 |\n",
+		"| Completion rate | 33.3% |\n",
+		"| Average progress | 50.0% |\n",
+		"| Subtasks completed | 1 / 2 |\n",
+		"### Launch \\*v2\\* \\[beta\\]\n\n",
+		"- Status: Overdue\n- Progress: 50%\n- Period: 2026-03-01 to 2026-03-05\n- Tags: work, web\n\n",
+		"Ship the &lt;new&gt; site\n\n",
+		"- [x] Design (completed 2026-03-08)\n",
+		"- [ ] Build \\| test (due 2026-04-01)\n",
+		"- Status: Completed\n- Progress: 100%\n- Period: 2026-03-01 to -\n- Completed on: 2026-03-08\n",
+		"### Read (more)\n\n- Status: Open\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("markdown lacks %q:\n%s", want, text)
+		}
+	}
+
+	buf.Reset()
+	if err := RenderMarkdown(&buf, Build("Empty", nil, Filters{Status: StatusOpen}, time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "No goals match the selected filters.") {
+		t.Errorf("empty report lacks the no goals notice:\n%s", buf.String())
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderPDF(&buf, testReport()); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Errorf("document starts with %q, want %%PDF-", data[:8])
+	}
+	if !bytes.HasSuffix(bytes.TrimRight(data, "\n"), []byte("%%EOF")) {
+		t.Errorf("document doesn't end with %%%%EOF")
+	}
+	checkXref(t, data)
+
+	for _, want := range []string{
+		"/Count 1 ",
+		`(Read \(more\)) Tj`,
+		"(Caf\xe9 \x93Z\xfcrich\x94 \x96 5\x80 ? 3 ?) Tj",
+		"(Page 1 of 1) Tj",
+	} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("PDF lacks %q", want)
+		}
+	}
+}
+
+func TestRenderPDFPages(t *testing.T) {
+	rep := testReport()
+	for i := 0; i < 60; i++ {
+		rep.Goals = append(rep.Goals, models.Goal{Title: fmt.Sprintf("Goal %d %s", i, strings.Repeat("long ", 40))})
+	}
+
+	var buf bytes.Buffer
+	if err := RenderPDF(&buf, rep); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	checkXref(t, data)
+
+	count := regexp.MustCompile(`/Count (\d+) `).FindSubmatch(data)
+	if count == nil {
+		t.Fatal("page count missing")
+	}
+	pages, _ := strconv.Atoi(string(count[1]))
+	if pages < 2 {
+		t.Fatalf("got %d pages, want the report to span several", pages)
+	}
+	if last := fmt.Sprintf("(Page %d of %d) Tj", pages, pages); !bytes.Contains(data, []byte(last)) {
+		t.Errorf("PDF lacks %q", last)
+	}
+
+	for _, m := range regexp.MustCompile(`Td \((.*)\) Tj`).FindAllSubmatch(data, -1) {
+		if len(m[1]) > 100 {
+			t.Errorf("line %q is wider than the page", m[1])
+		}
+	}
+}
+
+func TestPDFString(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "plain", want: "plain"},
+		{text: `a (b) \c`, want: `a \(b\) \\c`},
+		{text: "tab\tnew\nline\x7f", want: "tab new line "},
+		{text: "naïve ©", want: "na\xefve \xa9"},
+		{text: "€ ‘q’ “q” • – — … ™ Œ ž Ÿ", want: "\x80 \x91q\x92 \x93q\x94 \x95 \x96 \x97 \x85 \x99 \x8c \x9e \x9f"},
+		{text: "\u0080\u009f", want: "??"},
+		{text: "日本 😀 ≥", want: "?? ? ?"},
+	}
+	for _, tt := range tests {
+		if got := pdfString(tt.text); got != tt.want {
+			t.Errorf("pdfString(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+// checkXref verifies that startxref points at the cross-reference table
+// and that every entry points at its object
+func checkXref(t *testing.T, data []byte) {
+	t.Helper()
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(data)
+	if m == nil {
+		t.Fatal("startxref missing")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d doesn't point at the xref table", xref)
+	}
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllSubmatch(data[xref:], -1)
+	if len(entries) == 0 {
+		t.Fatal("xref table is empty")
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q, want %q", i+1, data[offset:offset+10], want)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// Package revisions stores full snapshots of goals after every change so
+// they can be compared and restored.
+package revisions
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"task-management/internal/models"
+)
+
+// CollectionName is the collection revisions are stored in
+const CollectionName = "goal_revisions"
+
+// ErrNotFound is returned when a revision doesn't exist
+var ErrNotFound = errors.New("revision not found")
+
+// DeletedGoalRetention is how long the revisions of a deleted goal are kept,
+// so a goal brought back by undo keeps its history
+const DeletedGoalRetention = 24 * time.Hour
+
+// maxRecordAttempts bounds the retries when concurrent changes to a goal
+// race for the same revision number
+const maxRecordAttempts = 5
+
+// Policy limits how many revisions are kept per goal. Zero values disable a
+// limit. The latest revision of a goal is always kept.
+type Policy struct {
+	KeepLast int
+	KeepFor  time.Duration
+}
+
+// NewPolicy creates a policy keeping the last keepLast revisions and those
+// from the last keepDays days
+func NewPolicy(keepLast, keepDays int) Policy {
+	return Policy{
+		KeepLast: keepLast,
+		KeepFor:  time.Duration(keepDays) * 24 * time.Hour,
+	}
+}
+
+// Store reads and writes goal revisions
+type Store struct {
+	collection *mongo.Collection
+	policy     Policy
+}
+
+// NewStore creates a new revision store
+func NewStore(collection *mongo.Collection, policy Policy) *Store {
+	return &Store{
+		collection: collection,
+		policy:     policy,
+	}
+}
+
+// EnsureIndexes creates the unique index that keeps revision numbers from
+// being handed out twice
+func (s *Store) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "goalId", Value: 1}, {Key: "number", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// Record stores a snapshot of goal as its next revision and prunes the
+// revisions the policy no longer keeps. A number taken by a concurrent
+// change is retried with the next one.
+func (s *Store) Record(ctx context.Context, goal *models.Goal, action string, actorID *primitive.ObjectID) error {
+	if s == nil {
+		return nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		latest, err := s.latestNumber(ctx, goal.ID)
+		if err != nil {
+			return err
+		}
+
+		revision := models.Revision{
+			ID:        primitive.NewObjectID(),
+			GoalID:    goal.ID,
+			Number:    latest + 1,
+			Action:    action,
+			ActorID:   actorID,
+			Goal:      goal,
+			CreatedAt: time.Now(),
+		}
+		if _, err := s.collection.InsertOne(ctx, revision); err != nil {
+			if mongo.IsDuplicateKeyError(err) && attempt < maxRecordAttempts {
+				continue
+			}
+			return err
+		}
+
+		return s.prune(ctx, goal.ID, revision.Number, revision.CreatedAt)
+	}
+}
+
+// DeleteGoal marks the revisions of a deleted goal. They are kept for
+// DeletedGoalRetention in case the goal is restored.
+func (s *Store) DeleteGoal(ctx context.Context, goalID primitive.ObjectID, now time.Time) error {
+	if s == nil {
+		return nil
+	}
+	_, err := s.collection.UpdateMany(ctx, bson.M{"goalId": goalID}, bson.M{"$set": bson.M{"goalDeletedAt": now}})
+	return err
+}
+
+// RestoreGoal clears the deletion mark of a goal that was brought back
+func (s *Store) RestoreGoal(ctx context.Context, goalID primitive.ObjectID) error {
+	if s == nil {
+		return nil
+	}
+	_, err := s.collection.UpdateMany(ctx,
+		bson.M{"goalId": goalID, "goalDeletedAt": bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{"goalDeletedAt": ""}},
+	)
+	return err
+}
+
+// List returns a goal's revisions, newest first, without their snapshots
+func (s *Store) List(ctx context.Context, goalID primitive.ObjectID) ([]models.Revision, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{"goalId": goalID},
+		options.Find().SetSort(bson.M{"number": -1}).SetProjection(bson.M{"goal": 0}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	revisions := []models.Revision{}
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// Get returns a revision with its snapshot. A number of zero or less
+// returns the latest revision.
+func (s *Store) Get(ctx context.Context, goalID primitive.ObjectID, number int) (*models.Revision, error) {
+	filter := bson.M{"goalId": goalID}
+	opts := options.FindOne()
+	if number > 0 {
+		filter["number"] = number
+	} else {
+		opts.SetSort(bson.M{"number": -1})
+	}
+
+	var revision models.Revision
+	if err := s.collection.FindOne(ctx, filter, opts).Decode(&revision); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// PruneExpired drops the revisions of goals deleted more than
+// DeletedGoalRetention ago and applies the age limit to every goal, for
+// goals that haven't changed recently enough for Record to prune them
+func (s *Store) PruneExpired(ctx context.Context, now time.Time) error {
+	if _, err := s.collection.DeleteMany(ctx, bson.M{"goalDeletedAt": bson.M{"$lt": now.Add(-DeletedGoalRetention)}}); err != nil {
+		return err
+	}
+
+	if s.policy.KeepFor <= 0 {
+		return nil
+	}
+
+	goalIDs, err := s.collection.Distinct(ctx, "goalId", bson.M{"createdAt": bson.M{"$lt": now.Add(-s.policy.KeepFor)}})
+	if err != nil {
+		return err
+	}
+
+	for _, value := range goalIDs {
+		goalID, ok := value.(primitive.ObjectID)
+		if !ok {
+			continue
+		}
+		latest, err := s.latestNumber(ctx, goalID)
+		if err != nil {
+			return err
+		}
+		if err := s.prune(ctx, goalID, latest, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prune deletes the revisions of a goal that fall outside the policy,
+// never touching the latest one
+func (s *Store) prune(ctx context.Context, goalID primitive.ObjectID, latest int, now time.Time) error {
+	var limits bson.A
+	if s.policy.KeepLast > 0 {
+		limits = append(limits, bson.M{"number": bson.M{"$lte": latest - s.policy.KeepLast}})
+	}
+	if s.policy.KeepFor > 0 {
+		limits = append(limits, bson.M{"createdAt": bson.M{"$lt": now.Add(-s.policy.KeepFor)}})
+	}
+	if len(limits) == 0 {
+		return nil
+	}
+
+	_, err := s.collection.DeleteMany(ctx, bson.M{
+		"goalId": goalID,
+		"number": bson.M{"$lt": latest},
+		"$or":    limits,
+	})
+	return err
+}
+
+// latestNumber returns the number of a goal's latest revision, or zero
+func (s *Store) latestNumber(ctx context.Context, goalID primitive.ObjectID) (int, error) {
+	var revision models.Revision
+	err := s.collection.FindOne(ctx, bson.M{"goalId": goalID},
+		options.FindOne().SetSort(bson.M{"number": -1}).SetProjection(bson.M{"number": 1}),
+	).Decode(&revision)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return revision.Number, nil
+}
@@ -0,0 +1,152 @@
+package schedule
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrCycle is returned when tasks depend on each other in a cycle
+var ErrCycle = errors.New("dependency cycle")
+
+// Task is a unit of work to schedule. Dependencies on tasks that aren't
+// part of the schedule are ignored.
+type Task struct {
+	ID          primitive.ObjectID
+	Duration    time.Duration
+	Completed   bool
+	CompletedAt *time.Time
+	DueDate     *time.Time
+	DependsOn   []primitive.ObjectID
+}
+
+// Slot is the computed time span of a task. Late tasks end after their due
+// date; critical tasks are on the critical path.
+type Slot struct {
+	Start    time.Time
+	End      time.Time
+	Late     bool
+	Critical bool
+}
+
+// Plan is the earliest schedule of a set of tasks
+type Plan struct {
+	Slots        map[primitive.ObjectID]*Slot
+	Finish       *time.Time
+	CriticalPath []primitive.ObjectID
+}
+
+// Build schedules tasks as early as possible from now. An open task starts
+// once all its dependencies have ended and runs for its duration; an
+// unestimated task with a later due date is assumed to take until then.
+// Completed tasks end when they were completed. The critical path is the
+// chain of dependencies that ends with the last open task.
+func Build(tasks []Task, now time.Time) (*Plan, error) {
+	order, err := topoSort(tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Slots: make(map[primitive.ObjectID]*Slot, len(tasks))}
+	var last *Task
+	for _, task := range order {
+		slot := &Slot{}
+		if task.Completed {
+			slot.End = now
+			if task.CompletedAt != nil {
+				slot.End = *task.CompletedAt
+			}
+			slot.Start = slot.End.Add(-task.Duration)
+		} else {
+			slot.Start = now
+			for _, id := range task.DependsOn {
+				if dep, ok := plan.Slots[id]; ok && dep.End.After(slot.Start) {
+					slot.Start = dep.End
+				}
+			}
+			slot.End = slot.Start.Add(task.Duration)
+			if task.Duration == 0 && task.DueDate != nil && task.DueDate.After(slot.Start) {
+				slot.End = *task.DueDate
+			}
+			slot.Late = task.DueDate != nil && slot.End.After(*task.DueDate)
+
+			if last == nil || slot.End.After(plan.Slots[last.ID].End) {
+				last = task
+			}
+		}
+		plan.Slots[task.ID] = slot
+
+		if plan.Finish == nil || slot.End.After(*plan.Finish) {
+			end := slot.End
+			plan.Finish = &end
+		}
+	}
+
+	// Walk back from the last open task through the dependency that held up
+	// each task's start
+	byID := make(map[primitive.ObjectID]*Task, len(tasks))
+	for _, task := range order {
+		byID[task.ID] = task
+	}
+	for task := last; task != nil; {
+		slot := plan.Slots[task.ID]
+		slot.Critical = true
+		plan.CriticalPath = append([]primitive.ObjectID{task.ID}, plan.CriticalPath...)
+
+		var next *Task
+		for _, id := range task.DependsOn {
+			if dep, ok := plan.Slots[id]; ok && !byID[id].Completed && dep.End.Equal(slot.Start) {
+				next = byID[id]
+				break
+			}
+		}
+		task = next
+	}
+
+	return plan, nil
+}
+
+// topoSort orders tasks so that every task comes after its dependencies
+func topoSort(tasks []Task) ([]*Task, error) {
+	byID := make(map[primitive.ObjectID]*Task, len(tasks))
+	for i := range tasks {
+		byID[tasks[i].ID] = &tasks[i]
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[primitive.ObjectID]int, len(tasks))
+	order := make([]*Task, 0, len(tasks))
+
+	var visit func(task *Task) error
+	visit = func(task *Task) error {
+		switch state[task.ID] {
+		case visiting:
+			return ErrCycle
+		case done:
+			return nil
+		}
+
+		state[task.ID] = visiting
+		for _, id := range task.DependsOn {
+			if dep, ok := byID[id]; ok {
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		state[task.ID] = done
+		order = append(order, task)
+		return nil
+	}
+
+	for i := range tasks {
+		if err := visit(&tasks[i]); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,182 @@
+package schedule
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBuildRejectsCycles(t *testing.T) {
+	a, b, c := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	tests := []struct {
+		name  string
+		tasks []Task
+	}{
+		{"self dependency", []Task{{ID: a, DependsOn: []primitive.ObjectID{a}}}},
+		{"two tasks", []Task{
+			{ID: a, DependsOn: []primitive.ObjectID{b}},
+			{ID: b, DependsOn: []primitive.ObjectID{a}},
+		}},
+		{"three tasks", []Task{
+			{ID: a, DependsOn: []primitive.ObjectID{b}},
+			{ID: b, DependsOn: []primitive.ObjectID{c}},
+			{ID: c, DependsOn: []primitive.ObjectID{a}},
+		}},
+		{"cycle through a completed task", []Task{
+			{ID: a, Completed: true, DependsOn: []primitive.ObjectID{b}},
+			{ID: b, DependsOn: []primitive.ObjectID{a}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Build(tt.tasks, time.Now()); err != ErrCycle {
+				t.Errorf("Build() error = %v, want ErrCycle", err)
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	hours := func(n int) time.Duration { return time.Duration(n) * time.Hour }
+	at := func(n int) *time.Time {
+		t := now.Add(hours(n))
+		return &t
+	}
+	a, b, c, d := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	missing := primitive.NewObjectID()
+
+	type slot struct {
+		start, end     int
+		late, critical bool
+	}
+	tests := []struct {
+		name     string
+		tasks    []Task
+		want     map[primitive.ObjectID]slot
+		finish   int
+		critical []primitive.ObjectID
+	}{
+		{
+			name: "chain",
+			tasks: []Task{
+				{ID: c, Duration: hours(1), DependsOn: []primitive.ObjectID{b}},
+				{ID: a, Duration: hours(2)},
+				{ID: b, Duration: hours(3), DependsOn: []primitive.ObjectID{a}},
+			},
+			want: map[primitive.ObjectID]slot{
+				a: {0, 2, false, true},
+				b: {2, 5, false, true},
+				c: {5, 6, false, true},
+			},
+			finish:   6,
+			critical: []primitive.ObjectID{a, b, c},
+		},
+		{
+			name: "longest branch is critical",
+			tasks: []Task{
+				{ID: a, Duration: hours(1)},
+				{ID: b, Duration: hours(4)},
+				{ID: c, Duration: hours(1), DependsOn: []primitive.ObjectID{a, b}},
+			},
+			want: map[primitive.ObjectID]slot{
+				a: {0, 1, false, false},
+				b: {0, 4, false, true},
+				c: {4, 5, false, true},
+			},
+			finish:   5,
+			critical: []primitive.ObjectID{b, c},
+		},
+		{
+			name: "late task",
+			tasks: []Task{
+				{ID: a, Duration: hours(3)},
+				{ID: b, Duration: hours(2), DueDate: at(4), DependsOn: []primitive.ObjectID{a}},
+			},
+			want: map[primitive.ObjectID]slot{
+				a: {0, 3, false, true},
+				b: {3, 5, true, true},
+			},
+			finish:   5,
+			critical: []primitive.ObjectID{a, b},
+		},
+		{
+			name: "unestimated task runs until its due date",
+			tasks: []Task{
+				{ID: a, DueDate: at(8)},
+				{ID: b, Duration: hours(1), DependsOn: []primitive.ObjectID{a}},
+			},
+			want: map[primitive.ObjectID]slot{
+				a: {0, 8, false, true},
+				b: {8, 9, false, true},
+			},
+			finish:   9,
+			critical: []primitive.ObjectID{a, b},
+		},
+		{
+			name: "completed dependency ends when it was completed",
+			tasks: []Task{
+				{ID: a, Duration: hours(2), Completed: true, CompletedAt: at(-1)},
+				{ID: b, Duration: hours(1), DependsOn: []primitive.ObjectID{a}},
+			},
+			want: map[primitive.ObjectID]slot{
+				a: {-3, -1, false, false},
+				b: {0, 1, false, true},
+			},
+			finish:   1,
+			critical: []primitive.ObjectID{b},
+		},
+		{
+			name: "dependencies outside the schedule are ignored",
+			tasks: []Task{
+				{ID: d, Duration: hours(2), DependsOn: []primitive.ObjectID{missing}},
+			},
+			want: map[primitive.ObjectID]slot{
+				d: {0, 2, false, true},
+			},
+			finish:   2,
+			critical: []primitive.ObjectID{d},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := Build(tt.tasks, now)
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			for id, want := range tt.want {
+				got := plan.Slots[id]
+				if got == nil {
+					t.Fatalf("no slot for task %s", id.Hex())
+				}
+				if !got.Start.Equal(now.Add(hours(want.start))) || !got.End.Equal(now.Add(hours(want.end))) {
+					t.Errorf("task %s runs %v-%v, want %dh-%dh", id.Hex(), got.Start, got.End, want.start, want.end)
+				}
+				if got.Late != want.late || got.Critical != want.critical {
+					t.Errorf("task %s late/critical = %v/%v, want %v/%v", id.Hex(), got.Late, got.Critical, want.late, want.critical)
+				}
+			}
+			if plan.Finish == nil || !plan.Finish.Equal(now.Add(hours(tt.finish))) {
+				t.Errorf("finish = %v, want %dh", plan.Finish, tt.finish)
+			}
+			if !reflect.DeepEqual(plan.CriticalPath, tt.critical) {
+				t.Errorf("critical path = %v, want %v", plan.CriticalPath, tt.critical)
+			}
+		})
+	}
+}
+
+func TestBuildEmpty(t *testing.T) {
+	plan, err := Build(nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Slots) != 0 || plan.Finish != nil || plan.CriticalPath != nil {
+		t.Errorf("empty plan = %+v", plan)
+	}
+}
@@ -0,0 +1,67 @@
+package timesheet
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"task-management/internal/models"
+)
+
+// csvHeader lists the columns of a CSV timesheet. Times are local to the
+// timesheet's time zone.
+var csvHeader = []string{
+	"date", "start", "end", "hours", "goal_id", "goal", "subtask_id",
+	"subtask", "tags", "note", "source",
+}
+
+// tagSeparator joins tags within a single CSV cell
+const tagSeparator = ";"
+
+// WriteCSV writes entries as a timesheet, one row per entry
+func WriteCSV(w io.Writer, entries []models.TimeEntry, goals map[primitive.ObjectID]*models.Goal, loc *time.Location) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		goal := goals[entry.GoalID]
+		var goalTitle, subTaskID, subTaskTitle string
+		if goal != nil {
+			goalTitle = goal.Title
+		}
+		if entry.SubTaskID != nil {
+			subTaskID = entry.SubTaskID.Hex()
+			if goal != nil {
+				if task := goal.FindSubTask(*entry.SubTaskID); task != nil {
+					subTaskTitle = task.Title
+				}
+			}
+		}
+
+		start := entry.StartedAt.In(loc)
+		if err := out.Write([]string{
+			start.Format(dayLayout),
+			start.Format("15:04"),
+			entry.EndedAt.In(loc).Format("15:04"),
+			strconv.FormatFloat(float64(entry.Seconds)/3600, 'f', 2, 64),
+			entry.GoalID.Hex(),
+			goalTitle,
+			subTaskID,
+			subTaskTitle,
+			strings.Join(Tags(entry, goal), tagSeparator),
+			entry.Note,
+			entry.Source,
+		}); err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,141 @@
+package timesheet
+
+import (
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"task-management/internal/models"
+)
+
+// dayLayout formats calendar days in reports and timesheets
+const dayLayout = "2006-01-02"
+
+// GoalTime is the time spent on one goal
+type GoalTime struct {
+	GoalID  primitive.ObjectID `json:"goalId"`
+	Title   string             `json:"title"`
+	Seconds int64              `json:"seconds"`
+}
+
+// TagTime is the time spent on goals and subtasks with one tag
+type TagTime struct {
+	Tag     string `json:"tag"`
+	Seconds int64  `json:"seconds"`
+}
+
+// DayTime is the time spent on one calendar day
+type DayTime struct {
+	Date    string `json:"date"`
+	Seconds int64  `json:"seconds"`
+}
+
+// Report sums time entries per goal, tag and day
+type Report struct {
+	Total  int64      `json:"total"`
+	ByGoal []GoalTime `json:"byGoal"`
+	ByTag  []TagTime  `json:"byTag"`
+	ByDay  []DayTime  `json:"byDay"`
+}
+
+// Summarize builds a report from entries. goals holds the entries' goals by
+// ID; entries of deleted goals are counted with an empty title. An entry
+// counts towards each tag of its goal and subtask, and is split across the
+// days it spans in loc.
+func Summarize(entries []models.TimeEntry, goals map[primitive.ObjectID]*models.Goal, loc *time.Location) Report {
+	report := Report{ByGoal: []GoalTime{}, ByTag: []TagTime{}, ByDay: []DayTime{}}
+	byGoal := make(map[primitive.ObjectID]*GoalTime)
+	byTag := make(map[string]int64)
+	byDay := make(map[string]int64)
+
+	for _, entry := range entries {
+		report.Total += entry.Seconds
+
+		line, ok := byGoal[entry.GoalID]
+		if !ok {
+			line = &GoalTime{GoalID: entry.GoalID}
+			if goal := goals[entry.GoalID]; goal != nil {
+				line.Title = goal.Title
+			}
+			byGoal[entry.GoalID] = line
+		}
+		line.Seconds += entry.Seconds
+
+		for _, tag := range Tags(entry, goals[entry.GoalID]) {
+			byTag[tag] += entry.Seconds
+		}
+
+		for day, seconds := range splitByDay(entry, loc) {
+			byDay[day] += seconds
+		}
+	}
+
+	for _, line := range byGoal {
+		report.ByGoal = append(report.ByGoal, *line)
+	}
+	sort.Slice(report.ByGoal, func(i, j int) bool {
+		if report.ByGoal[i].Seconds != report.ByGoal[j].Seconds {
+			return report.ByGoal[i].Seconds > report.ByGoal[j].Seconds
+		}
+		return report.ByGoal[i].GoalID.Hex() < report.ByGoal[j].GoalID.Hex()
+	})
+	for tag, seconds := range byTag {
+		report.ByTag = append(report.ByTag, TagTime{Tag: tag, Seconds: seconds})
+	}
+	sort.Slice(report.ByTag, func(i, j int) bool {
+		if report.ByTag[i].Seconds != report.ByTag[j].Seconds {
+			return report.ByTag[i].Seconds > report.ByTag[j].Seconds
+		}
+		return report.ByTag[i].Tag < report.ByTag[j].Tag
+	})
+	for day, seconds := range byDay {
+		report.ByDay = append(report.ByDay, DayTime{Date: day, Seconds: seconds})
+	}
+	sort.Slice(report.ByDay, func(i, j int) bool {
+		return report.ByDay[i].Date < report.ByDay[j].Date
+	})
+
+	return report
+}
+
+// Tags returns the distinct tags of an entry's goal and subtask
+func Tags(entry models.TimeEntry, goal *models.Goal) []string {
+	if goal == nil {
+		return nil
+	}
+
+	tags := append([]string{}, goal.Tags...)
+	if entry.SubTaskID != nil {
+		if task := goal.FindSubTask(*entry.SubTaskID); task != nil {
+			tags = append(tags, task.Tags...)
+		}
+	}
+
+	seen := make(map[string]bool, len(tags))
+	distinct := tags[:0]
+	for _, tag := range tags {
+		if !seen[tag] {
+			seen[tag] = true
+			distinct = append(distinct, tag)
+		}
+	}
+	return distinct
+}
+
+// splitByDay divides an entry's seconds between the calendar days in loc
+// that it spans
+func splitByDay(entry models.TimeEntry, loc *time.Location) map[string]int64 {
+	days := make(map[string]int64)
+	start := entry.StartedAt.In(loc)
+	end := entry.EndedAt.In(loc)
+	for start.Before(end) {
+		next := time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, loc)
+		if next.After(end) {
+			next = end
+		}
+		days[start.Format(dayLayout)] += int64(next.Sub(start) / time.Second)
+		start = next
+	}
+	return days
+}
@@ -0,0 +1,254 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLineSize bounds a single NDJSON line
+const maxLineSize = 4 * 1024 * 1024
+
+// Decode reads goal records in the given format. Rows that cannot be parsed
+// are reported as RowErrors so the rest of the file can still be imported;
+// the returned error is only set when the file as a whole is unreadable.
+func Decode(format Format, r io.Reader) ([]Record, []RowError, error) {
+	switch format {
+	case FormatNDJSON:
+		return decodeNDJSON(r)
+	case FormatCSV:
+		return decodeCSV(r)
+	default:
+		return decodeJSON(r)
+	}
+}
+
+func decodeJSON(r io.Reader) ([]Record, []RowError, error) {
+	dec := json.NewDecoder(r)
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, nil, errors.New("invalid JSON: expected an array of goals")
+	}
+
+	var records []Record
+	var rowErrs []RowError
+	for row := 1; dec.More(); row++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return records, rowErrs, fmt.Errorf("invalid JSON at item %d: %w", row, err)
+		}
+
+		var goal GoalRecord
+		if err := json.Unmarshal(raw, &goal); err != nil {
+			rowErrs = append(rowErrs, RowError{Row: row, Message: err.Error()})
+			continue
+		}
+		records = append(records, Record{Row: row, Goal: goal})
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return records, rowErrs, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	return records, rowErrs, nil
+}
+
+func decodeNDJSON(r io.Reader) ([]Record, []RowError, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	var records []Record
+	var rowErrs []RowError
+	for row := 1; scanner.Scan(); row++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var goal GoalRecord
+		if err := json.Unmarshal(line, &goal); err != nil {
+			rowErrs = append(rowErrs, RowError{Row: row, Message: err.Error()})
+			continue
+		}
+		records = append(records, Record{Row: row, Goal: goal})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return records, rowErrs, fmt.Errorf("invalid NDJSON: %w", err)
+	}
+
+	return records, rowErrs, nil
+}
+
+func decodeCSV(r io.Reader) ([]Record, []RowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, nil, errors.New("invalid CSV: missing title column")
+	}
+
+	var records []Record
+	var rowErrs []RowError
+	goalIndex := make(map[string]int)
+
+	type pendingTask struct {
+		row    int
+		goalID string
+		task   SubTaskRecord
+	}
+	var tasks []pendingTask
+
+	// Row 1 is the header
+	for row := 2; ; row++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrs = append(rowErrs, RowError{Row: row, Message: parseErr.Err.Error()})
+				continue
+			}
+			return records, rowErrs, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		get := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(fields) {
+				return ""
+			}
+			return strings.TrimSpace(fields[i])
+		}
+
+		var fieldErrs []RowError
+		parseDate := func(name string) *time.Time {
+			t, err := parseTime(get(name))
+			if err != nil {
+				fieldErrs = append(fieldErrs, RowError{Row: row, Field: name, Message: err.Error()})
+			}
+			return t
+		}
+		parseTags := func(name string) []string {
+			var tags []string
+			for _, tag := range strings.Split(get(name), tagSeparator) {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					tags = append(tags, tag)
+				}
+			}
+			return tags
+		}
+		parseBool := func(name string) bool {
+			value := get(name)
+			if value == "" {
+				return false
+			}
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				fieldErrs = append(fieldErrs, RowError{Row: row, Field: name, Message: "expected true or false"})
+			}
+			return b
+		}
+
+		switch strings.ToLower(get("type")) {
+		case "", "goal":
+			goal := GoalRecord{
+				ID:          get("id"),
+				Title:       get("title"),
+				Description: get("description"),
+				SubTasks:    []SubTaskRecord{},
+				Tags:        parseTags("tags"),
+				StartDate:   parseDate("start_date"),
+				EndDate:     parseDate("end_date"),
+				Completed:   parseBool("completed"),
+				CompletedAt: parseDate("completed_at"),
+				CreatedAt:   parseDate("created_at"),
+			}
+			if len(fieldErrs) > 0 {
+				rowErrs = append(rowErrs, fieldErrs...)
+				continue
+			}
+			if goal.ID != "" {
+				if _, exists := goalIndex[goal.ID]; exists {
+					rowErrs = append(rowErrs, RowError{Row: row, Field: "id", Message: "duplicate goal id " + goal.ID})
+					continue
+				}
+				goalIndex[goal.ID] = len(records)
+			}
+			records = append(records, Record{Row: row, Goal: goal})
+
+		case "subtask":
+			task := SubTaskRecord{
+				ID:          get("id"),
+				Title:       get("title"),
+				Description: get("description"),
+				Completed:   parseBool("completed"),
+				CompletedAt: parseDate("completed_at"),
+				DueDate:     parseDate("due_date"),
+				Tags:        parseTags("tags"),
+				CreatedAt:   parseDate("created_at"),
+			}
+			if task.Title == "" {
+				fieldErrs = append(fieldErrs, RowError{Row: row, Field: "title", Message: "title is required"})
+			}
+			if get("goal_id") == "" {
+				fieldErrs = append(fieldErrs, RowError{Row: row, Field: "goal_id", Message: "goal_id is required for subtasks"})
+			}
+			if len(fieldErrs) > 0 {
+				rowErrs = append(rowErrs, fieldErrs...)
+				continue
+			}
+			tasks = append(tasks, pendingTask{row: row, goalID: get("goal_id"), task: task})
+
+		default:
+			rowErrs = append(rowErrs, RowError{Row: row, Field: "type", Message: "type must be goal or subtask"})
+		}
+	}
+
+	// Subtask rows may appear before their goal, so attach them afterwards
+	for _, pending := range tasks {
+		i, ok := goalIndex[pending.goalID]
+		if !ok {
+			rowErrs = append(rowErrs, RowError{Row: pending.row, Field: "goal_id", Message: "unknown goal " + pending.goalID})
+			continue
+		}
+		records[i].Goal.SubTasks = append(records[i].Goal.SubTasks, pending.task)
+	}
+
+	return records, rowErrs, nil
+}
+
+// parseTime accepts RFC 3339 timestamps and plain dates
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid date %q, expected RFC 3339 or YYYY-MM-DD", value)
+}
@@ -0,0 +1,127 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Format represents a supported import/export format
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+	FormatCSV    Format = "csv"
+)
+
+// ErrUnsupportedFormat is returned when a format is not recognised
+var ErrUnsupportedFormat = errors.New("unsupported format, expected json, ndjson or csv")
+
+// ParseFormat converts a query value into a Format
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(value))) {
+	case "", FormatJSON:
+		return FormatJSON, nil
+	case FormatNDJSON, "jsonl":
+		return FormatNDJSON, nil
+	case FormatCSV:
+		return FormatCSV, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// ContentType returns the MIME type used when serving the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// Extension returns the file extension used for downloads
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// SubTaskRecord represents a subtask read from an import file
+type SubTaskRecord struct {
+	ID          string     `json:"id,omitempty"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	DueDate     *time.Time `json:"dueDate,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	CreatedAt   *time.Time `json:"createdAt,omitempty"`
+}
+
+// GoalRecord represents a goal read from an import file. IDs are kept as
+// plain strings because they come from the source system and are remapped
+// on import.
+type GoalRecord struct {
+	ID          string          `json:"id,omitempty"`
+	Title       string          `json:"title"`
+	Description string          `json:"description,omitempty"`
+	SubTasks    []SubTaskRecord `json:"subTasks"`
+	Tags        []string        `json:"tags,omitempty"`
+	StartDate   *time.Time      `json:"startDate,omitempty"`
+	EndDate     *time.Time      `json:"endDate,omitempty"`
+	Completed   bool            `json:"completed"`
+	CompletedAt *time.Time      `json:"completedAt,omitempty"`
+	CreatedAt   *time.Time      `json:"createdAt,omitempty"`
+}
+
+// Record is a decoded goal together with the row it came from
+type Record struct {
+	Row  int
+	Goal GoalRecord
+}
+
+// RowError describes a validation or parse error for a single row
+type RowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e RowError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("row %d: %s: %s", e.Row, e.Field, e.Message)
+	}
+	return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+}
+
+// Validate checks a record for errors that would prevent it from being imported
+func Validate(rec Record) []RowError {
+	var errs []RowError
+
+	if strings.TrimSpace(rec.Goal.Title) == "" {
+		errs = append(errs, RowError{Row: rec.Row, Field: "title", Message: "title is required"})
+	}
+
+	if rec.Goal.StartDate != nil && rec.Goal.EndDate != nil && rec.Goal.EndDate.Before(*rec.Goal.StartDate) {
+		errs = append(errs, RowError{Row: rec.Row, Field: "endDate", Message: "endDate must not be before startDate"})
+	}
+
+	seen := make(map[string]bool)
+	for i, task := range rec.Goal.SubTasks {
+		field := fmt.Sprintf("subTasks[%d]", i)
+		if strings.TrimSpace(task.Title) == "" {
+			errs = append(errs, RowError{Row: rec.Row, Field: field + ".title", Message: "title is required"})
+		}
+		if task.ID != "" {
+			if seen[task.ID] {
+				errs = append(errs, RowError{Row: rec.Row, Field: field + ".id", Message: "duplicate subtask id " + task.ID})
+			}
+			seen[task.ID] = true
+		}
+	}
+
+	return errs
+}
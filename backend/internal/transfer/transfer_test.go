@@ -0,0 +1,283 @@
+package transfer
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"task-management/internal/models"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Format
+		wantErr bool
+	}{
+		{value: "", want: FormatJSON},
+		{value: "JSON", want: FormatJSON},
+		{value: " jsonl ", want: FormatNDJSON},
+		{value: "ndjson", want: FormatNDJSON},
+		{value: "csv", want: FormatCSV},
+		{value: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 4, 30, 18, 0, 0, 0, time.UTC)
+	due := time.Date(2026, 3, 15, 12, 30, 0, 0, time.UTC)
+	done := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	created := time.Date(2026, 2, 20, 8, 0, 0, 0, time.UTC)
+
+	goals := []models.Goal{
+		{
+			ID:          primitive.NewObjectID(),
+			Title:       "Launch, v2",
+			Description: "Ship the \"new\" site\nwith docs",
+			Tags:        []string{"work", "web"},
+			StartDate:   start,
+			EndDate:     &end,
+			SubTasks: []models.SubTask{
+				{ID: primitive.NewObjectID(), Title: "Design", Completed: true, CompletedAt: &done, Tags: []string{"ux"}, CreatedAt: created, UpdatedAt: done},
+				{ID: primitive.NewObjectID(), Title: "Build", Description: "Frontend", DueDate: &due, CreatedAt: created, UpdatedAt: created},
+			},
+			CreatedAt: created,
+			UpdatedAt: done,
+		},
+		{
+			ID:          primitive.NewObjectID(),
+			Title:       "Read más",
+			StartDate:   start,
+			SubTasks:    []models.SubTask{},
+			Completed:   true,
+			CompletedAt: &done,
+			CreatedAt:   created,
+			UpdatedAt:   done,
+		},
+	}
+
+	for _, format := range []Format{FormatJSON, FormatNDJSON, FormatCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewWriter(format, &buf)
+			for i := range goals {
+				if err := w.Write(&goals[i]); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			records, rowErrs, err := Decode(format, &buf)
+			if err != nil || len(rowErrs) > 0 {
+				t.Fatalf("Decode() errors = %v, %v", err, rowErrs)
+			}
+			if len(records) != len(goals) {
+				t.Fatalf("decoded %d records, want %d", len(records), len(goals))
+			}
+			for i, rec := range records {
+				if want := goalRecord(&goals[i]); !reflect.DeepEqual(rec.Goal, want) {
+					t.Errorf("record %d = %+v, want %+v", i, rec.Goal, want)
+				}
+				if errs := Validate(rec); len(errs) > 0 {
+					t.Errorf("record %d is invalid: %v", i, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestEmptyExport(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatNDJSON, FormatCSV} {
+		var buf bytes.Buffer
+		if err := NewWriter(format, &buf).Close(); err != nil {
+			t.Fatalf("%s: Close() error = %v", format, err)
+		}
+		records, rowErrs, err := Decode(format, &buf)
+		if err != nil || len(rowErrs) > 0 || len(records) > 0 {
+			t.Errorf("%s: Decode() = %v, %v, %v, want nothing", format, records, rowErrs, err)
+		}
+	}
+}
+
+func TestDecodeRowErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		input   string
+		titles  []string
+		rowErrs []RowError
+		wantErr bool
+	}{
+		{
+			name:    "json",
+			format:  FormatJSON,
+			input:   `[{"title": "A"}, {"title": 5}, {"title": "C", "completed": "yes"}, {"title": "D"}]`,
+			titles:  []string{"A", "D"},
+			rowErrs: []RowError{{Row: 2}, {Row: 3}},
+		},
+		{
+			name:    "json object",
+			format:  FormatJSON,
+			input:   `{"title": "A"}`,
+			wantErr: true,
+		},
+		{
+			name:    "truncated json",
+			format:  FormatJSON,
+			input:   `[{"title": "A"}, {"title"`,
+			titles:  []string{"A"},
+			wantErr: true,
+		},
+		{
+			name:    "ndjson",
+			format:  FormatNDJSON,
+			input:   "{\"title\": \"A\"}\n\nnot json\n{\"title\": \"D\"}\n",
+			titles:  []string{"A", "D"},
+			rowErrs: []RowError{{Row: 3}},
+		},
+		{
+			name:   "csv",
+			format: FormatCSV,
+			input: "type,id,goal_id,title,end_date,completed\n" +
+				"subtask,s1,g1,Early subtask,,\n" +
+				"goal,g1,,First,2026-05-01,false\n" +
+				"goal,g1,,Duplicate,,\n" +
+				"goal,,,Bad date,May 1st,\n" +
+				"goal,,,Bad flag,,maybe\n" +
+				"subtask,s2,g9,Orphan,,\n" +
+				"subtask,s3,,No goal,,\n" +
+				"milestone,,,Unknown,,\n" +
+				"goal,g2,,Second,,true\n",
+			titles: []string{"First", "Second"},
+			rowErrs: []RowError{
+				{Row: 4, Field: "id"},
+				{Row: 5, Field: "end_date"},
+				{Row: 6, Field: "completed"},
+				{Row: 8, Field: "goal_id"},
+				{Row: 9, Field: "type"},
+				{Row: 7, Field: "goal_id"},
+			},
+		},
+		{
+			name:    "csv without title",
+			format:  FormatCSV,
+			input:   "type,id\ngoal,g1\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, rowErrs, err := Decode(tt.format, strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			var titles []string
+			for _, rec := range records {
+				titles = append(titles, rec.Goal.Title)
+			}
+			if !reflect.DeepEqual(titles, tt.titles) {
+				t.Errorf("titles = %q, want %q", titles, tt.titles)
+			}
+
+			if len(rowErrs) != len(tt.rowErrs) {
+				t.Fatalf("row errors = %v, want %d", rowErrs, len(tt.rowErrs))
+			}
+			for i, want := range tt.rowErrs {
+				if rowErrs[i].Row != want.Row || rowErrs[i].Field != want.Field || rowErrs[i].Message == "" {
+					t.Errorf("row error %d = %+v, want row %d field %q", i, rowErrs[i], want.Row, want.Field)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeCSVAttachesSubTasks(t *testing.T) {
+	input := "type,id,goal_id,title,due_date\n" +
+		"subtask,s1,g1,Before,2026-03-15\n" +
+		"goal,g1,,Goal,\n" +
+		"subtask,s2,g1,After,\n"
+
+	records, rowErrs, err := Decode(FormatCSV, strings.NewReader(input))
+	if err != nil || len(rowErrs) > 0 {
+		t.Fatalf("Decode() errors = %v, %v", err, rowErrs)
+	}
+	if len(records) != 1 || records[0].Row != 3 {
+		t.Fatalf("records = %+v, want one goal from row 3", records)
+	}
+	tasks := records[0].Goal.SubTasks
+	if len(tasks) != 2 || tasks[0].Title != "Before" || tasks[1].Title != "After" {
+		t.Fatalf("subtasks = %+v, want Before and After", tasks)
+	}
+	if tasks[0].DueDate == nil || !tasks[0].DueDate.Equal(time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("due date = %v, want 2026-03-15", tasks[0].DueDate)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	before := start.AddDate(0, 0, -1)
+
+	rec := Record{Row: 7, Goal: GoalRecord{
+		Title:     " ",
+		StartDate: &start,
+		EndDate:   &before,
+		SubTasks:  []SubTaskRecord{{ID: "a", Title: "One"}, {ID: "a"}},
+	}}
+
+	var fields []string
+	for _, err := range Validate(rec) {
+		if err.Row != 7 {
+			t.Errorf("error %v is for row %d, want 7", err, err.Row)
+		}
+		fields = append(fields, err.Field)
+	}
+	want := []string{"title", "endDate", "subTasks[1].title", "subTasks[1].id"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("fields = %q, want %q", fields, want)
+	}
+}
+
+// goalRecord is the record an exported goal is expected to decode to
+func goalRecord(goal *models.Goal) GoalRecord {
+	rec := GoalRecord{
+		ID:          goal.ID.Hex(),
+		Title:       goal.Title,
+		Description: goal.Description,
+		SubTasks:    []SubTaskRecord{},
+		Tags:        goal.Tags,
+		StartDate:   &goal.StartDate,
+		EndDate:     goal.EndDate,
+		Completed:   goal.Completed,
+		CompletedAt: goal.CompletedAt,
+		CreatedAt:   &goal.CreatedAt,
+	}
+	for i := range goal.SubTasks {
+		task := &goal.SubTasks[i]
+		rec.SubTasks = append(rec.SubTasks, SubTaskRecord{
+			ID:          task.ID.Hex(),
+			Title:       task.Title,
+			Description: task.Description,
+			Completed:   task.Completed,
+			CompletedAt: task.CompletedAt,
+			DueDate:     task.DueDate,
+			Tags:        task.Tags,
+			CreatedAt:   &task.CreatedAt,
+		})
+	}
+	return rec
+}
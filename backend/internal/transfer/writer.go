@@ -0,0 +1,172 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-management/internal/models"
+)
+
+// csvHeader lists the columns written and read for CSV files. Goals and
+// subtasks share one sheet; subtask rows point at their goal via goal_id.
+var csvHeader = []string{
+	"type", "id", "goal_id", "title", "description", "tags",
+	"start_date", "end_date", "due_date", "completed", "completed_at",
+	"progress", "created_at", "updated_at",
+}
+
+// tagSeparator joins tags within a single CSV cell
+const tagSeparator = ";"
+
+// Writer streams goals in a particular format
+type Writer interface {
+	Write(goal *models.Goal) error
+	Close() error
+}
+
+// NewWriter creates a writer for the given format
+func NewWriter(format Format, w io.Writer) Writer {
+	switch format {
+	case FormatNDJSON:
+		return &ndjsonWriter{w: bufio.NewWriter(w)}
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}
+	default:
+		return &jsonWriter{w: bufio.NewWriter(w)}
+	}
+}
+
+type jsonWriter struct {
+	w     *bufio.Writer
+	count int
+}
+
+func (j *jsonWriter) Write(goal *models.Goal) error {
+	sep := ",\n"
+	if j.count == 0 {
+		sep = "[\n"
+	}
+	if _, err := j.w.WriteString(sep); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(goal)
+	if err != nil {
+		return err
+	}
+	if _, err := j.w.Write(data); err != nil {
+		return err
+	}
+
+	j.count++
+	return j.w.Flush()
+}
+
+func (j *jsonWriter) Close() error {
+	end := "\n]\n"
+	if j.count == 0 {
+		end = "[]\n"
+	}
+	if _, err := j.w.WriteString(end); err != nil {
+		return err
+	}
+	return j.w.Flush()
+}
+
+type ndjsonWriter struct {
+	w *bufio.Writer
+}
+
+func (n *ndjsonWriter) Write(goal *models.Goal) error {
+	data, err := json.Marshal(goal)
+	if err != nil {
+		return err
+	}
+	if _, err := n.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return n.w.Flush()
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.w.Flush()
+}
+
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (c *csvWriter) Write(goal *models.Goal) error {
+	if !c.headerWritten {
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+
+	if err := c.w.Write([]string{
+		"goal",
+		goal.ID.Hex(),
+		"",
+		goal.Title,
+		goal.Description,
+		strings.Join(goal.Tags, tagSeparator),
+		formatTime(&goal.StartDate),
+		formatTime(goal.EndDate),
+		"",
+		strconv.FormatBool(goal.Completed),
+		formatTime(goal.CompletedAt),
+		strconv.FormatFloat(goal.Progress, 'f', 2, 64),
+		formatTime(&goal.CreatedAt),
+		formatTime(&goal.UpdatedAt),
+	}); err != nil {
+		return err
+	}
+
+	for _, task := range goal.SubTasks {
+		if err := c.w.Write([]string{
+			"subtask",
+			task.ID.Hex(),
+			goal.ID.Hex(),
+			task.Title,
+			task.Description,
+			strings.Join(task.Tags, tagSeparator),
+			"",
+			"",
+			formatTime(task.DueDate),
+			strconv.FormatBool(task.Completed),
+			formatTime(task.CompletedAt),
+			"",
+			formatTime(&task.CreatedAt),
+			formatTime(&task.UpdatedAt),
+		}); err != nil {
+			return err
+		}
+	}
+
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	if !c.headerWritten {
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,185 @@
+// Package undo keeps per-user stacks of recent goal changes in memory so
+// they can be undone and redone for a short while.
+package undo
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"task-management/internal/models"
+)
+
+var (
+	// ErrEmpty is returned when there is nothing to undo or redo
+	ErrEmpty = errors.New("nothing to undo or redo")
+	// ErrConflict is returned by an apply function when the goal no longer
+	// matches the state the change left it in. The change is discarded.
+	ErrConflict = errors.New("goal has changed since")
+)
+
+// Change is a goal going from one state to another. From is nil for a
+// created goal and To is nil for a deleted one.
+type Change struct {
+	Action string             `json:"action"`
+	GoalID primitive.ObjectID `json:"goalId"`
+	From   *models.Goal       `json:"-"`
+	To     *models.Goal       `json:"-"`
+	At     time.Time          `json:"at"`
+}
+
+// ApplyFunc moves a goal from one state to another: it must check that the
+// goal is currently in state from, write state to and return what was
+// stored, or nil when to is nil
+type ApplyFunc func(from, to *models.Goal) (*models.Goal, error)
+
+// Manager holds the undo and redo stacks of every user
+type Manager struct {
+	window time.Duration
+	depth  int
+
+	mu        sync.Mutex
+	stacks    map[string]*stacks
+	lastSweep time.Time
+}
+
+type stacks struct {
+	mu   sync.Mutex
+	undo []Change
+	redo []Change
+
+	// lastUsed is guarded by Manager.mu
+	lastUsed time.Time
+}
+
+// NewManager creates a manager keeping up to depth changes per user for window
+func NewManager(window time.Duration, depth int) *Manager {
+	return &Manager{
+		window: window,
+		depth:  depth,
+		stacks: make(map[string]*stacks),
+	}
+}
+
+// Record pushes a change onto a user's undo stack and clears their redo
+// stack, as a new change invalidates anything that was undone
+func (m *Manager) Record(key string, change Change) {
+	if m == nil {
+		return
+	}
+
+	s := m.stacksFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.undo = append(m.live(s.undo), change)
+	if len(s.undo) > m.depth {
+		s.undo = s.undo[len(s.undo)-m.depth:]
+	}
+	s.redo = nil
+}
+
+// Undo reverts the user's most recent change
+func (m *Manager) Undo(key string, apply ApplyFunc) (Change, *models.Goal, error) {
+	s := m.stacksFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return m.reverse(&s.undo, &s.redo, apply)
+}
+
+// Redo reapplies the user's most recently undone change
+func (m *Manager) Redo(key string, apply ApplyFunc) (Change, *models.Goal, error) {
+	s := m.stacksFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return m.reverse(&s.redo, &s.undo, apply)
+}
+
+// List returns the changes that can currently be undone and redone, most
+// recent first
+func (m *Manager) List(key string) (undo, redo []Change) {
+	s := m.stacksFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.undo = m.live(s.undo)
+	s.redo = m.live(s.redo)
+	return reversed(s.undo), reversed(s.redo)
+}
+
+// reverse pops the top change from one stack, moves the goal back to the
+// state before it and pushes the reversal onto the other stack
+func (m *Manager) reverse(from, to *[]Change, apply ApplyFunc) (Change, *models.Goal, error) {
+	*from = m.live(*from)
+	if len(*from) == 0 {
+		return Change{}, nil, ErrEmpty
+	}
+	top := (*from)[len(*from)-1]
+
+	result, err := apply(top.To, top.From)
+	if err != nil {
+		if errors.Is(err, ErrConflict) {
+			*from = (*from)[:len(*from)-1]
+		}
+		return top, nil, err
+	}
+	*from = (*from)[:len(*from)-1]
+
+	reversal := Change{
+		Action: top.Action,
+		GoalID: top.GoalID,
+		From:   top.To,
+		To:     result,
+		At:     time.Now(),
+	}
+	*to = append(m.live(*to), reversal)
+	return top, result, nil
+}
+
+// live drops changes older than the window
+func (m *Manager) live(changes []Change) []Change {
+	cutoff := time.Now().Add(-m.window)
+	i := 0
+	for i < len(changes) && changes[i].At.Before(cutoff) {
+		i++
+	}
+	return changes[i:]
+}
+
+// stacksFor returns a user's stacks, creating them when needed. Users who
+// haven't been seen for a whole window only have expired changes left, so
+// they are swept out once per window.
+func (m *Manager) stacksFor(key string) *stacks {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(m.lastSweep) > m.window {
+		m.lastSweep = now
+		for k, s := range m.stacks {
+			if now.Sub(s.lastUsed) > m.window {
+				delete(m.stacks, k)
+			}
+		}
+	}
+
+	s, ok := m.stacks[key]
+	if !ok {
+		s = &stacks{}
+		m.stacks[key] = s
+	}
+	s.lastUsed = now
+	return s
+}
+
+func reversed(changes []Change) []Change {
+	out := make([]Change, len(changes))
+	for i, change := range changes {
+		out[len(changes)-1-i] = change
+	}
+	return out
+}